@@ -4,33 +4,103 @@ import (
 	"fmt"
 	"os"
 
+	"github.com/felipepimentel/digitalocean-go/internal/api"
+	"github.com/felipepimentel/digitalocean-go/internal/auth"
+	"github.com/felipepimentel/digitalocean-go/internal/billing"
+	"github.com/felipepimentel/digitalocean-go/internal/cache"
+	"github.com/felipepimentel/digitalocean-go/internal/cachecmd"
 	"github.com/felipepimentel/digitalocean-go/internal/config"
+	"github.com/felipepimentel/digitalocean-go/internal/configcmd"
+	"github.com/felipepimentel/digitalocean-go/internal/database"
+	"github.com/felipepimentel/digitalocean-go/internal/dev"
+	"github.com/felipepimentel/digitalocean-go/internal/domain"
 	"github.com/felipepimentel/digitalocean-go/internal/droplet"
 	"github.com/felipepimentel/digitalocean-go/internal/kubernetes"
+	"github.com/felipepimentel/digitalocean-go/internal/logging"
+	"github.com/felipepimentel/digitalocean-go/internal/output"
 	"github.com/felipepimentel/digitalocean-go/internal/vpc"
 	"github.com/spf13/cobra"
 )
 
 func main() {
-	cfg, err := config.Load()
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error loading configuration: %v\n", err)
-		os.Exit(1)
-	}
+	// Commands hold on to cfg and read it when they run, after the root
+	// command has loaded it for the selected context.
+	cfg := &config.Config{}
 
 	rootCmd := &cobra.Command{
 		Use:   "digitalocean-cli",
 		Short: "A CLI for managing DigitalOcean resources",
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			loaded, err := config.Load(cmd.Flags())
+			if err != nil {
+				return fmt.Errorf("failed to load configuration: %w", err)
+			}
+			*cfg = *loaded
+
+			if err := logging.Setup(logging.Options{Level: cfg.LogLevel, Format: cfg.LogFormat, File: cfg.LogFile}); err != nil {
+				return err
+			}
+
+			if _, err := output.ParseFormat(cfg.Output); err != nil {
+				return err
+			}
+			if needsToken(cmd) {
+				return cfg.RequireToken()
+			}
+			return nil
+		},
 	}
 
+	rootCmd.PersistentFlags().String("token", "", "DigitalOcean API token (overrides DO_TOKEN and the context)")
+	rootCmd.PersistentFlags().String("api-url", "", "DigitalOcean API endpoint")
+	rootCmd.PersistentFlags().Int("max-retries", 3, "Retries for API requests failing with 429, 5xx or network errors")
+	rootCmd.PersistentFlags().Float64("rate-limit", 4, "Maximum API requests per second (0 disables the limit)")
+	rootCmd.PersistentFlags().String("context", "", "Context from the config file to use (defaults to the current context)")
+	rootCmd.PersistentFlags().StringP("output", "o", "", "Output format: json, yaml, table, wide, name, csv, tsv, ndjson, go-template=..., go-template-file=... or jsonpath=... (default table)")
+	rootCmd.PersistentFlags().StringSlice("columns", nil, "Comma-separated list of table columns to show")
+	rootCmd.PersistentFlags().Int("limit", 0, "Maximum number of items list commands return (0 for all)")
+	rootCmd.PersistentFlags().Bool("no-cache", false, "Neither read nor store cached list results")
+	rootCmd.PersistentFlags().Bool("refresh", false, "Refetch lists instead of using cached results")
+	rootCmd.PersistentFlags().String("log-level", "", "Minimum level of diagnostic logs: debug, info, warn or error (default info)")
+	rootCmd.PersistentFlags().String("log-format", "", "Format of diagnostic logs: text or json (default text)")
+	rootCmd.PersistentFlags().String("log-file", "", "Append diagnostic logs to this file instead of stderr")
+	rootCmd.PersistentFlags().Bool("debug-http", false, "Log every API request and response, with the token redacted")
+	rootCmd.PersistentFlags().Bool("debug-http-body", false, "Also log request and response bodies (implies --debug-http)")
+
 	rootCmd.AddCommand(
-		droplet.Cmd(cfg),
-		vpc.Cmd(cfg),
-		kubernetes.Cmd(cfg),
+		auth.Cmd(cfg),
+		configcmd.Cmd(cfg),
+		cachecmd.Cmd(cfg),
+		droplet.Cmd(cfg, api.NewDropletAPI),
+		vpc.Cmd(cfg, api.NewVPCAPI),
+		kubernetes.Cmd(cfg, api.NewKubernetesAPI),
+		database.Cmd(cfg, api.NewDatabaseAPI),
+		domain.Cmd(cfg, api.NewDomainAPI),
+		billing.Cmd(cfg, api.NewBillingAPI),
+		dev.Cmd(),
 	)
 
-	if err := rootCmd.Execute(); err != nil {
+	rootCmd.InitDefaultCompletionCmd()
+	if completion, _, err := rootCmd.Find([]string{"completion"}); err == nil && completion != rootCmd {
+		completion.Annotations = map[string]string{config.NoTokenAnnotation: "true"}
+	}
+
+	err := rootCmd.Execute()
+	cache.Wait()
+	logging.Close()
+	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error executing command: %v\n", err)
 		os.Exit(1)
 	}
 }
+
+// needsToken reports whether cmd talks to the API, i.e. neither it nor any
+// of its parents is marked with config.NoTokenAnnotation.
+func needsToken(cmd *cobra.Command) bool {
+	for c := cmd; c != nil; c = c.Parent() {
+		if c.Annotations[config.NoTokenAnnotation] != "" {
+			return false
+		}
+	}
+	return true
+}
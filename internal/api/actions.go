@@ -0,0 +1,79 @@
+package api
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/digitalocean/godo"
+)
+
+// Droplet action types, as the API names them.
+const (
+	ActionPowerOn       = "power_on"
+	ActionPowerOff      = "power_off"
+	ActionShutdown      = "shutdown"
+	ActionReboot        = "reboot"
+	ActionPowerCycle    = "power_cycle"
+	ActionRebuild       = "rebuild"
+	ActionRename        = "rename"
+	ActionEnableIPv6    = "enable_ipv6"
+	ActionEnableBackups = "enable_backups"
+	ActionPasswordReset = "password_reset"
+	ActionResize        = "resize"
+	ActionSnapshot      = "snapshot"
+	ActionRestore       = "restore"
+)
+
+// DropletActionRequest is an action to take on a droplet. Image is the
+// image a rebuild uses, or the backup a restore does, and Name the new name
+// of a rename or the name of a snapshot. Size is the slug a resize moves
+// to, and Disk makes it grow the disk as well, which cannot be undone.
+type DropletActionRequest struct {
+	Type  string
+	Image godo.DropletCreateImage
+	Name  string
+	Size  string
+	Disk  bool
+}
+
+func (c *Client) RunDropletAction(ctx context.Context, id int, req DropletActionRequest) (*godo.Action, error) {
+	defer c.cache.invalidate(resourceDroplets)
+
+	var action *godo.Action
+	var err error
+	switch req.Type {
+	case ActionPowerOn:
+		action, _, err = c.DropletActions.PowerOn(ctx, id)
+	case ActionPowerOff:
+		action, _, err = c.DropletActions.PowerOff(ctx, id)
+	case ActionShutdown:
+		action, _, err = c.DropletActions.Shutdown(ctx, id)
+	case ActionReboot:
+		action, _, err = c.DropletActions.Reboot(ctx, id)
+	case ActionPowerCycle:
+		action, _, err = c.DropletActions.PowerCycle(ctx, id)
+	case ActionRebuild:
+		if req.Image.ID != 0 {
+			action, _, err = c.DropletActions.RebuildByImageID(ctx, id, req.Image.ID)
+		} else {
+			action, _, err = c.DropletActions.RebuildByImageSlug(ctx, id, req.Image.Slug)
+		}
+	case ActionRename:
+		action, _, err = c.DropletActions.Rename(ctx, id, req.Name)
+	case ActionEnableIPv6:
+		action, _, err = c.DropletActions.EnableIPv6(ctx, id)
+	case ActionEnableBackups:
+		action, _, err = c.DropletActions.EnableBackups(ctx, id)
+	case ActionPasswordReset:
+		action, _, err = c.DropletActions.PasswordReset(ctx, id)
+	case ActionResize:
+		action, _, err = c.DropletActions.Resize(ctx, id, req.Size, req.Disk)
+	case ActionSnapshot:
+		action, _, err = c.DropletActions.Snapshot(ctx, id, req.Name)
+	case ActionRestore:
+		action, _, err = c.DropletActions.Restore(ctx, id, req.Image.ID)
+	default:
+		return nil, fmt.Errorf("unsupported droplet action %q", req.Type)
+	}
+	return action, err
+}
@@ -0,0 +1,112 @@
+package api
+
+import (
+	"net/url"
+	"time"
+
+	"github.com/felipepimentel/digitalocean-go/internal/cache"
+	"github.com/felipepimentel/digitalocean-go/internal/config"
+)
+
+// Cached resources. Domain records are cached per domain below
+// resourceDomainRecords.
+const (
+	resourceDroplets      = "droplets"
+	resourceVPCs          = "vpcs"
+	resourceClusters      = "kubernetes-clusters"
+	resourceDatabases     = "databases"
+	resourceDomains       = "domains"
+	resourceDomainRecords = "domain-records"
+	resourceSizes         = "sizes"
+	resourceRegions       = "regions"
+)
+
+// CachedResources lists the resources whose lists are cached, as accepted
+// by `cache clear`.
+var CachedResources = []string{
+	resourceDroplets,
+	resourceVPCs,
+	resourceClusters,
+	resourceDatabases,
+	resourceDomains,
+	resourceDomainRecords,
+	resourceSizes,
+	resourceRegions,
+}
+
+// catalogTTL is used for sizes and regions, which rarely change.
+const catalogTTL = 24 * time.Hour
+
+// listCache holds the cache settings of a Client. A nil cache disables
+// caching; with disabled set lists bypass the cache but creates and deletes
+// still invalidate it.
+type listCache struct {
+	cache    *cache.Cache
+	profile  string
+	ttl      time.Duration
+	disabled bool
+	refresh  bool
+}
+
+func newListCache(cfg *config.Config) listCache {
+	if cfg.CacheDir == "" {
+		return listCache{}
+	}
+	return listCache{
+		cache:    cache.New(cfg.CacheDir),
+		profile:  CacheProfile(cfg),
+		ttl:      cfg.CacheTTL,
+		disabled: cfg.NoCache,
+		refresh:  cfg.RefreshCache,
+	}
+}
+
+// CacheProfile returns the cache namespace of the selected context. Other
+// API endpoints, such as a fake API, get their own namespace.
+func CacheProfile(cfg *config.Config) string {
+	profile := cfg.Context
+	if profile == "" {
+		profile = "default"
+	}
+	if cfg.APIURL != "" && cfg.APIURL != config.DefaultAPIURL {
+		if u, err := url.Parse(cfg.APIURL); err == nil && u.Host != "" {
+			profile += "@" + u.Host
+		}
+	}
+	return profile
+}
+
+func (lc listCache) key(resource ...string) string {
+	return cache.Key(append([]string{lc.profile}, resource...)...)
+}
+
+// invalidate drops the cached lists of a resource after it was changed.
+func (lc listCache) invalidate(resource ...string) {
+	if lc.cache != nil {
+		lc.cache.Invalidate(lc.key(resource...))
+	}
+}
+
+// cachedList serves list through the cache under resource. A list cut
+// short by a limit is served from a cached full list when there is one but
+// never stored.
+func cachedList[T any](lc listCache, ttl time.Duration, opts PageOptions, list func(PageOptions) ([]T, error), resource ...string) ([]T, error) {
+	if lc.cache == nil || lc.disabled {
+		return list(opts)
+	}
+	key := lc.key(resource...)
+
+	if opts.Limit > 0 {
+		var items []T
+		if !lc.refresh && lc.cache.Get(key, &items) {
+			if len(items) > opts.Limit {
+				items = items[:opts.Limit]
+			}
+			return items, nil
+		}
+		return list(opts)
+	}
+	return cache.Fetch(lc.cache, key, ttl, lc.refresh, func() ([]T, error) {
+		return list(opts)
+	})
+}
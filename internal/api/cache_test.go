@@ -0,0 +1,73 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/digitalocean/godo"
+	"github.com/felipepimentel/digitalocean-go/internal/config"
+)
+
+func TestListsAreCachedAndInvalidated(t *testing.T) {
+	var lists int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			w.WriteHeader(http.StatusAccepted)
+			fmt.Fprint(w, `{"droplet":{"id":2,"name":"web-2"}}`)
+			return
+		}
+		n := atomic.AddInt32(&lists, 1)
+		fmt.Fprintf(w, `{"droplets":[{"id":1,"name":"web-%d"}],"links":{},"meta":{"total":1}}`, n)
+	}))
+	defer server.Close()
+
+	cfg := &config.Config{
+		DOToken:  "test-token",
+		APIURL:   server.URL + "/",
+		Context:  "work",
+		CacheDir: t.TempDir(),
+		CacheTTL: time.Minute,
+	}
+	ctx := context.Background()
+	client := NewClient(cfg)
+
+	list := func(client *Client, opts PageOptions) string {
+		t.Helper()
+		droplets, err := client.ListDroplets(ctx, opts)
+		if err != nil {
+			t.Fatalf("ListDroplets: %v", err)
+		}
+		return droplets[0].Name
+	}
+
+	if name := list(client, PageOptions{}); name != "web-1" {
+		t.Errorf("Expected the first response, got %s", name)
+	}
+	if name := list(client, PageOptions{Limit: 1}); name != "web-1" || lists != 1 {
+		t.Errorf("Expected a cached web-1 after %d requests, got %s", lists, name)
+	}
+
+	cfg.NoCache = true
+	if name := list(NewClient(cfg), PageOptions{}); name != "web-2" {
+		t.Errorf("Expected --no-cache to bypass the cache, got %s", name)
+	}
+	cfg.NoCache, cfg.RefreshCache = false, true
+	if name := list(NewClient(cfg), PageOptions{}); name != "web-3" {
+		t.Errorf("Expected --refresh to refetch, got %s", name)
+	}
+	if name := list(client, PageOptions{}); name != "web-3" {
+		t.Errorf("Expected --refresh to replace the cached list, got %s", name)
+	}
+
+	if _, _, err := client.CreateDroplet(ctx, &godo.DropletCreateRequest{Name: "web-2", Region: "nyc1", Size: "s-1vcpu-1gb", Image: godo.DropletCreateImage{Slug: "ubuntu-22-04-x64"}}); err != nil {
+		t.Fatalf("CreateDroplet: %v", err)
+	}
+	if name := list(client, PageOptions{}); name != "web-4" {
+		t.Errorf("Expected creating a droplet to invalidate the list, got %s", name)
+	}
+}
@@ -0,0 +1,270 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// CassetteMode selects whether a cassette transport records traffic or
+// replays it.
+type CassetteMode string
+
+const (
+	CassetteRecord CassetteMode = "record"
+	CassetteReplay CassetteMode = "replay"
+)
+
+const redacted = "REDACTED"
+
+// Cassette is a recording of API traffic: request/response pairs in the
+// order they happened. Requests are stored without scheme and host so a
+// cassette replays against any base URL.
+type Cassette struct {
+	Interactions []Interaction `json:"interactions"`
+}
+
+type Interaction struct {
+	Request  RecordedRequest  `json:"request"`
+	Response RecordedResponse `json:"response"`
+}
+
+type RecordedRequest struct {
+	Method string      `json:"method"`
+	URL    string      `json:"url"`
+	Header http.Header `json:"header,omitempty"`
+	Body   string      `json:"body,omitempty"`
+}
+
+type RecordedResponse struct {
+	StatusCode int         `json:"status_code"`
+	Header     http.Header `json:"header,omitempty"`
+	Body       string      `json:"body,omitempty"`
+}
+
+func LoadCassette(path string) (*Cassette, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var c Cassette
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("failed to parse cassette %s: %w", path, err)
+	}
+	return &c, nil
+}
+
+func (c *Cassette) Save(path string) error {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(c); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, buf.Bytes(), 0600)
+}
+
+// cassetteTransport records the traffic passing through to next, or
+// replays it from the cassette without touching the network. It sits below
+// the OAuth transport so that it sees, and scrubs, the Authorization
+// header.
+type cassetteTransport struct {
+	path    string
+	mode    CassetteMode
+	next    http.RoundTripper
+	secrets []string
+
+	mu       sync.Mutex
+	cassette *Cassette
+	used     []bool
+	err      error
+}
+
+// newCassetteTransport returns a transport for the cassette at path.
+// Replaying a missing or unreadable cassette fails every request. Recording
+// overwrites any existing cassette. secrets are replaced in everything
+// recorded.
+func newCassetteTransport(path string, mode CassetteMode, next http.RoundTripper, secrets ...string) *cassetteTransport {
+	t := &cassetteTransport{path: path, mode: mode, next: next, cassette: &Cassette{}}
+	for _, secret := range secrets {
+		if secret != "" {
+			t.secrets = append(t.secrets, secret)
+		}
+	}
+
+	switch mode {
+	case CassetteReplay:
+		t.cassette, t.err = LoadCassette(path)
+		if errors.Is(t.err, fs.ErrNotExist) {
+			t.err = fmt.Errorf("cassette %s does not exist; record it with DO_CASSETTE_MODE=record", path)
+		}
+		if t.err == nil {
+			t.used = make([]bool, len(t.cassette.Interactions))
+		}
+	case CassetteRecord:
+	default:
+		t.err = fmt.Errorf("unknown cassette mode %q", mode)
+	}
+	return t
+}
+
+func (t *cassetteTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if t.err != nil {
+		return nil, t.err
+	}
+
+	body, err := readBody(req)
+	if err != nil {
+		return nil, err
+	}
+	recorded := t.recordRequest(req, body)
+
+	if t.mode == CassetteReplay {
+		return t.replay(req, recorded)
+	}
+	return t.record(req, recorded)
+}
+
+// replay returns the first unused interaction matching the request's
+// method, URL and body, so repeated identical requests get the responses
+// recorded for them in turn.
+func (t *cassetteTransport) replay(req *http.Request, recorded RecordedRequest) (*http.Response, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for i, interaction := range t.cassette.Interactions {
+		if t.used[i] || !sameRequest(interaction.Request, recorded) {
+			continue
+		}
+		t.used[i] = true
+
+		resp := interaction.Response
+		header := resp.Header.Clone()
+		if header == nil {
+			header = http.Header{}
+		}
+		return &http.Response{
+			Status:        fmt.Sprintf("%d %s", resp.StatusCode, http.StatusText(resp.StatusCode)),
+			StatusCode:    resp.StatusCode,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        header,
+			Body:          io.NopCloser(strings.NewReader(resp.Body)),
+			ContentLength: int64(len(resp.Body)),
+			Request:       req,
+		}, nil
+	}
+	return nil, fmt.Errorf("cassette %s has no recorded response for %s %s", t.path, recorded.Method, recorded.URL)
+}
+
+func (t *cassetteTransport) record(req *http.Request, recorded RecordedRequest) (*http.Response, error) {
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	header := resp.Header.Clone()
+	header.Del("Set-Cookie")
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.cassette.Interactions = append(t.cassette.Interactions, Interaction{
+		Request: recorded,
+		Response: RecordedResponse{
+			StatusCode: resp.StatusCode,
+			Header:     t.scrubHeader(header),
+			Body:       t.scrub(string(body)),
+		},
+	})
+	// Saving after every interaction keeps the cassette complete even when
+	// the command fails halfway.
+	if err := t.cassette.Save(t.path); err != nil {
+		return nil, fmt.Errorf("failed to save cassette: %w", err)
+	}
+	return resp, nil
+}
+
+func (t *cassetteTransport) recordRequest(req *http.Request, body []byte) RecordedRequest {
+	header := req.Header.Clone()
+	if header.Get("Authorization") != "" {
+		header.Set("Authorization", redacted)
+	}
+	header.Del("Cookie")
+
+	return RecordedRequest{
+		Method: req.Method,
+		URL:    t.scrub(req.URL.RequestURI()),
+		Header: t.scrubHeader(header),
+		Body:   t.scrub(string(body)),
+	}
+}
+
+func (t *cassetteTransport) scrub(s string) string {
+	for _, secret := range t.secrets {
+		s = strings.ReplaceAll(s, secret, redacted)
+	}
+	return s
+}
+
+func (t *cassetteTransport) scrubHeader(header http.Header) http.Header {
+	for name, values := range header {
+		for i, value := range values {
+			values[i] = t.scrub(value)
+		}
+		header[name] = values
+	}
+	return header
+}
+
+func sameRequest(a, b RecordedRequest) bool {
+	return a.Method == b.Method && a.URL == b.URL && sameBody(a.Body, b.Body)
+}
+
+// sameBody compares JSON bodies semantically, so that field order does not
+// matter, and anything else byte for byte.
+func sameBody(a, b string) bool {
+	if a == b {
+		return true
+	}
+	var x, y interface{}
+	if json.Unmarshal([]byte(a), &x) != nil || json.Unmarshal([]byte(b), &y) != nil {
+		return false
+	}
+	xs, _ := json.Marshal(x)
+	ys, _ := json.Marshal(y)
+	return bytes.Equal(xs, ys)
+}
+
+// readBody reads the request body and puts it back for the next transport.
+func readBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, nil
+	}
+	body, err := io.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	return body, nil
+}
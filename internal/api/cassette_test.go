@@ -0,0 +1,88 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/felipepimentel/digitalocean-go/internal/config"
+)
+
+func TestCassetteRecordAndReplay(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if r.Method == http.MethodPost {
+			// Echo the token back to check it is scrubbed from bodies too.
+			fmt.Fprintf(w, `{"domain":{"name":%q,"zone_file":"%s"}}`, strings.TrimSpace(string(body)), r.Header.Get("Authorization"))
+			return
+		}
+		fmt.Fprint(w, `{"domains":[{"name":"example.com","ttl":1800}],"links":{},"meta":{"total":1}}`)
+	}))
+
+	path := filepath.Join(t.TempDir(), "testdata", "domains.json")
+	cfg := &config.Config{
+		DOToken:      "secret-token",
+		APIURL:       server.URL + "/",
+		Cassette:     path,
+		CassetteMode: string(CassetteRecord),
+	}
+	ctx := context.Background()
+
+	recorder := NewClient(cfg)
+	if _, err := recorder.ListDomains(ctx, PageOptions{}); err != nil {
+		t.Fatalf("ListDomains while recording: %v", err)
+	}
+	if _, err := recorder.CreateDomain(ctx, "example.org"); err != nil {
+		t.Fatalf("CreateDomain while recording: %v", err)
+	}
+	server.Close()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "secret-token") {
+		t.Errorf("Cassette contains the token:\n%s", data)
+	}
+	if !strings.Contains(string(data), `"REDACTED"`) {
+		t.Errorf("Cassette does not show the redacted Authorization header:\n%s", data)
+	}
+
+	cfg.CassetteMode = string(CassetteReplay)
+	cfg.APIURL = "http://replay.invalid/"
+	player := NewClient(cfg)
+
+	domains, err := player.ListDomains(ctx, PageOptions{})
+	if err != nil {
+		t.Fatalf("ListDomains while replaying: %v", err)
+	}
+	if len(domains) != 1 || domains[0].Name != "example.com" {
+		t.Errorf("Unexpected replayed domains %+v", domains)
+	}
+	if _, err := player.CreateDomain(ctx, "example.org"); err != nil {
+		t.Errorf("CreateDomain while replaying: %v", err)
+	}
+
+	// Each recorded interaction is only replayed once.
+	if _, err := player.CreateDomain(ctx, "example.org"); err == nil || !strings.Contains(err.Error(), "no recorded response") {
+		t.Errorf("Expected a missing interaction error, got %v", err)
+	}
+}
+
+func TestCassetteReplayMissingFile(t *testing.T) {
+	client := NewClient(&config.Config{
+		DOToken:  "test-token",
+		Cassette: filepath.Join(t.TempDir(), "missing.json"),
+	})
+
+	_, err := client.ListDroplets(context.Background(), PageOptions{})
+	if err == nil || !strings.Contains(err.Error(), "does not exist") {
+		t.Errorf("Expected a missing cassette error, got %v", err)
+	}
+}
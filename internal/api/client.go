@@ -2,79 +2,150 @@ package api
 
 import (
 	"context"
+	"net/http"
+	"net/url"
+	"strings"
 
 	"github.com/digitalocean/godo"
 	"github.com/felipepimentel/digitalocean-go/internal/config"
+	"golang.org/x/oauth2"
 )
 
 type Client struct {
 	*godo.Client
+
+	cache listCache
 }
 
 func NewClient(cfg *config.Config) *Client {
-	return &Client{
-		Client: godo.NewFromToken(cfg.DOToken),
+	token := strings.Trim(strings.TrimSpace(cfg.DOToken), "'")
+
+	var transport http.RoundTripper = http.DefaultTransport
+	if cfg.Cassette != "" {
+		mode := CassetteMode(cfg.CassetteMode)
+		if mode == "" {
+			mode = CassetteReplay
+		}
+		transport = newCassetteTransport(cfg.Cassette, mode, transport, token)
+	}
+	if cfg.DebugHTTP {
+		transport = newDebugTransport(transport, cfg.DebugHTTPBodies, token)
+	}
+	httpClient := &http.Client{
+		Transport: &oauth2.Transport{
+			Source: oauth2.StaticTokenSource(&oauth2.Token{AccessToken: token}),
+			Base:   transport,
+		},
 	}
-}
 
-func (c *Client) ListDroplets(ctx context.Context) ([]godo.Droplet, error) {
-	list := []godo.Droplet{}
-	opt := &godo.ListOptions{Page: 1, PerPage: 100}
+	policy := DefaultRetryPolicy
+	policy.MaxRetries = cfg.MaxRetries
+	httpClient.Transport = newRetryTransport(httpClient.Transport, policy, cfg.RateLimit)
 
-	for {
-		droplets, resp, err := c.Droplets.List(ctx, opt)
-		if err != nil {
-			return nil, err
-		}
-		list = append(list, droplets...)
-		if resp.Links == nil || resp.Links.IsLastPage() {
-			break
+	client := godo.NewClient(httpClient)
+	if cfg.APIURL != "" {
+		// config.Load has already validated the URL.
+		if baseURL, err := url.Parse(cfg.APIURL); err == nil {
+			client.BaseURL = baseURL
 		}
-		opt.Page++
 	}
 
-	return list, nil
+	return &Client{
+		Client: client,
+		cache:  newListCache(cfg),
+	}
+}
+
+func (c *Client) IterateDroplets(ctx context.Context, opts PageOptions) *Iterator[godo.Droplet] {
+	return NewIterator(ctx, c.Droplets.List, opts)
 }
 
-func (c *Client) CreateDroplet(ctx context.Context, name, region, size, image string) (*godo.Droplet, error) {
-	createRequest := &godo.DropletCreateRequest{
-		Name:   name,
-		Region: region,
-		Size:   size,
-		Image:  godo.DropletCreateImage{Slug: image},
-	}
+func (c *Client) ListDroplets(ctx context.Context, opts PageOptions) ([]godo.Droplet, error) {
+	return cachedList(c.cache, c.cache.ttl, opts, func(opts PageOptions) ([]godo.Droplet, error) {
+		return Collect(c.IterateDroplets(ctx, opts))
+	}, resourceDroplets)
+}
 
-	droplet, _, err := c.Droplets.Create(ctx, createRequest)
-	return droplet, err
+// CreateDroplet creates a droplet and returns it with the link to its
+// create action.
+func (c *Client) CreateDroplet(ctx context.Context, req *godo.DropletCreateRequest) (*godo.Droplet, []godo.LinkAction, error) {
+	defer c.cache.invalidate(resourceDroplets)
+
+	droplet, resp, err := c.Droplets.Create(ctx, req)
+	return droplet, actionLinks(resp), err
+}
+
+// CreateDroplets creates several droplets in one request and returns them
+// with the links to their create actions, in the same order. The API
+// accepts at most MaxMultiCreate names.
+func (c *Client) CreateDroplets(ctx context.Context, req *godo.DropletMultiCreateRequest) ([]godo.Droplet, []godo.LinkAction, error) {
+	defer c.cache.invalidate(resourceDroplets)
+
+	droplets, resp, err := c.Droplets.CreateMultiple(ctx, req)
+	return droplets, actionLinks(resp), err
+}
+
+func actionLinks(resp *godo.Response) []godo.LinkAction {
+	if resp == nil || resp.Links == nil {
+		return nil
+	}
+	return resp.Links.Actions
 }
 
 func (c *Client) DeleteDroplet(ctx context.Context, id int) error {
+	defer c.cache.invalidate(resourceDroplets)
+
 	_, err := c.Droplets.Delete(ctx, id)
 	return err
 }
 
-func (c *Client) ListVPCs(ctx context.Context) ([]godo.VPC, error) {
-	list := []godo.VPC{}
-	opt := &godo.ListOptions{Page: 1, PerPage: 100}
+func (c *Client) GetDroplet(ctx context.Context, id int) (*godo.Droplet, error) {
+	droplet, _, err := c.Droplets.Get(ctx, id)
+	return droplet, err
+}
+
+// ListDropletSnapshots returns the snapshots of every droplet in the
+// account. Their ResourceID is the ID of the droplet they were taken of.
+func (c *Client) ListDropletSnapshots(ctx context.Context, opts PageOptions) ([]godo.Snapshot, error) {
+	return Collect(NewIterator(ctx, c.Snapshots.ListDroplet, opts))
+}
+
+func (c *Client) DeleteSnapshot(ctx context.Context, id string) error {
+	_, err := c.Snapshots.Delete(ctx, id)
+	return err
+}
+
+func (c *Client) ListDropletBackups(ctx context.Context, id int) ([]godo.Image, error) {
+	return Collect(NewIterator(ctx, func(ctx context.Context, opt *godo.ListOptions) ([]godo.Image, *godo.Response, error) {
+		return c.Droplets.Backups(ctx, id, opt)
+	}, PageOptions{}))
+}
+
+func (c *Client) GetAction(ctx context.Context, id int) (*godo.Action, error) {
+	action, _, err := c.Actions.Get(ctx, id)
+	return action, err
+}
 
-	for {
+func (c *Client) IterateVPCs(ctx context.Context, opts PageOptions) *Iterator[godo.VPC] {
+	return NewIterator(ctx, func(ctx context.Context, opt *godo.ListOptions) ([]godo.VPC, *godo.Response, error) {
 		vpcs, resp, err := c.VPCs.List(ctx, opt)
-		if err != nil {
-			return nil, err
-		}
+		list := make([]godo.VPC, 0, len(vpcs))
 		for _, vpc := range vpcs {
 			list = append(list, *vpc)
 		}
-		if resp.Links == nil || resp.Links.IsLastPage() {
-			break
-		}
-		opt.Page++
-	}
+		return list, resp, err
+	}, opts)
+}
 
-	return list, nil
+func (c *Client) ListVPCs(ctx context.Context, opts PageOptions) ([]godo.VPC, error) {
+	return cachedList(c.cache, c.cache.ttl, opts, func(opts PageOptions) ([]godo.VPC, error) {
+		return Collect(c.IterateVPCs(ctx, opts))
+	}, resourceVPCs)
 }
 
 func (c *Client) CreateVPC(ctx context.Context, name, region, ipRange string) (*godo.VPC, error) {
+	defer c.cache.invalidate(resourceVPCs)
+
 	createRequest := &godo.VPCCreateRequest{
 		Name:        name,
 		RegionSlug:  region,
@@ -87,30 +158,25 @@ func (c *Client) CreateVPC(ctx context.Context, name, region, ipRange string) (*
 }
 
 func (c *Client) DeleteVPC(ctx context.Context, id string) error {
+	defer c.cache.invalidate(resourceVPCs)
+
 	_, err := c.VPCs.Delete(ctx, id)
 	return err
 }
 
-func (c *Client) ListKubernetesClusters(ctx context.Context) ([]*godo.KubernetesCluster, error) {
-	list := []*godo.KubernetesCluster{}
-	opt := &godo.ListOptions{Page: 1, PerPage: 100}
-
-	for {
-		clusters, resp, err := c.Kubernetes.List(ctx, opt)
-		if err != nil {
-			return nil, err
-		}
-		list = append(list, clusters...)
-		if resp.Links == nil || resp.Links.IsLastPage() {
-			break
-		}
-		opt.Page++
-	}
+func (c *Client) IterateKubernetesClusters(ctx context.Context, opts PageOptions) *Iterator[*godo.KubernetesCluster] {
+	return NewIterator(ctx, c.Kubernetes.List, opts)
+}
 
-	return list, nil
+func (c *Client) ListKubernetesClusters(ctx context.Context, opts PageOptions) ([]*godo.KubernetesCluster, error) {
+	return cachedList(c.cache, c.cache.ttl, opts, func(opts PageOptions) ([]*godo.KubernetesCluster, error) {
+		return Collect(c.IterateKubernetesClusters(ctx, opts))
+	}, resourceClusters)
 }
 
 func (c *Client) CreateKubernetesCluster(ctx context.Context, name, region, version string, numNodes int) (*godo.KubernetesCluster, error) {
+	defer c.cache.invalidate(resourceClusters)
+
 	createRequest := &godo.KubernetesClusterCreateRequest{
 		Name:        name,
 		RegionSlug:  region,
@@ -129,32 +195,30 @@ func (c *Client) CreateKubernetesCluster(ctx context.Context, name, region, vers
 }
 
 func (c *Client) DeleteKubernetesCluster(ctx context.Context, id string) error {
+	defer c.cache.invalidate(resourceClusters)
+
 	_, err := c.Kubernetes.Delete(ctx, id)
 	return err
 }
 
-// Change the return type from []*godo.Database to []godo.Database
-func (c *Client) ListDatabases(ctx context.Context) ([]godo.Database, error) {
-	list := []godo.Database{}
-	opt := &godo.ListOptions{Page: 1, PerPage: 100}
+func (c *Client) GetKubernetesCluster(ctx context.Context, id string) (*godo.KubernetesCluster, error) {
+	cluster, _, err := c.Kubernetes.Get(ctx, id)
+	return cluster, err
+}
 
-	for {
-		databases, resp, err := c.Databases.List(ctx, opt)
-		if err != nil {
-			return nil, err
-		}
-		// Append without converting to pointer
-		list = append(list, databases...)
-		if resp.Links == nil || resp.Links.IsLastPage() {
-			break
-		}
-		opt.Page++
-	}
+func (c *Client) IterateDatabases(ctx context.Context, opts PageOptions) *Iterator[godo.Database] {
+	return NewIterator(ctx, c.Databases.List, opts)
+}
 
-	return list, nil
+func (c *Client) ListDatabases(ctx context.Context, opts PageOptions) ([]godo.Database, error) {
+	return cachedList(c.cache, c.cache.ttl, opts, func(opts PageOptions) ([]godo.Database, error) {
+		return Collect(c.IterateDatabases(ctx, opts))
+	}, resourceDatabases)
 }
 
 func (c *Client) CreateDatabase(ctx context.Context, name, engine, version, size, region string) (*godo.Database, error) {
+	defer c.cache.invalidate(resourceDatabases)
+
 	createRequest := &godo.DatabaseCreateRequest{
 		Name:       name,
 		EngineSlug: engine,
@@ -168,36 +232,35 @@ func (c *Client) CreateDatabase(ctx context.Context, name, engine, version, size
 }
 
 func (c *Client) DeleteDatabase(ctx context.Context, id string) error {
+	defer c.cache.invalidate(resourceDatabases)
+
 	_, err := c.Databases.Delete(ctx, id)
 	return err
 }
 
-// Change the GetBillingInfo function to use the correct method
+func (c *Client) GetDatabase(ctx context.Context, id string) (*godo.Database, error) {
+	database, _, err := c.Databases.Get(ctx, id)
+	return database, err
+}
+
 func (c *Client) GetBillingInfo(ctx context.Context) (*godo.Balance, error) {
 	balance, _, err := c.Balance.Get(ctx)
 	return balance, err
 }
 
-func (c *Client) ListDomains(ctx context.Context) ([]godo.Domain, error) {
-	list := []godo.Domain{}
-	opt := &godo.ListOptions{Page: 1, PerPage: 100}
-
-	for {
-		domains, resp, err := c.Domains.List(ctx, opt)
-		if err != nil {
-			return nil, err
-		}
-		list = append(list, domains...)
-		if resp.Links == nil || resp.Links.IsLastPage() {
-			break
-		}
-		opt.Page++
-	}
+func (c *Client) IterateDomains(ctx context.Context, opts PageOptions) *Iterator[godo.Domain] {
+	return NewIterator(ctx, c.Domains.List, opts)
+}
 
-	return list, nil
+func (c *Client) ListDomains(ctx context.Context, opts PageOptions) ([]godo.Domain, error) {
+	return cachedList(c.cache, c.cache.ttl, opts, func(opts PageOptions) ([]godo.Domain, error) {
+		return Collect(c.IterateDomains(ctx, opts))
+	}, resourceDomains)
 }
 
 func (c *Client) CreateDomain(ctx context.Context, name string) (*godo.Domain, error) {
+	defer c.cache.invalidate(resourceDomains)
+
 	createRequest := &godo.DomainCreateRequest{
 		Name: name,
 	}
@@ -207,42 +270,69 @@ func (c *Client) CreateDomain(ctx context.Context, name string) (*godo.Domain, e
 }
 
 func (c *Client) DeleteDomain(ctx context.Context, name string) error {
+	defer c.cache.invalidate(resourceDomains)
+	defer c.cache.invalidate(resourceDomainRecords, name)
+
 	_, err := c.Domains.Delete(ctx, name)
 	return err
 }
 
-func (c *Client) ListDomainRecords(ctx context.Context, domain string) ([]godo.DomainRecord, error) {
-	list := []godo.DomainRecord{}
-	opt := &godo.ListOptions{Page: 1, PerPage: 100}
+func (c *Client) IterateDomainRecords(ctx context.Context, domain string, opts PageOptions) *Iterator[godo.DomainRecord] {
+	return NewIterator(ctx, func(ctx context.Context, opt *godo.ListOptions) ([]godo.DomainRecord, *godo.Response, error) {
+		return c.Domains.Records(ctx, domain, opt)
+	}, opts)
+}
 
-	for {
-		records, resp, err := c.Domains.Records(ctx, domain, opt)
-		if err != nil {
-			return nil, err
-		}
-		list = append(list, records...)
-		if resp.Links == nil || resp.Links.IsLastPage() {
-			break
-		}
-		opt.Page++
-	}
+func (c *Client) ListDomainRecords(ctx context.Context, domain string, opts PageOptions) ([]godo.DomainRecord, error) {
+	return cachedList(c.cache, c.cache.ttl, opts, func(opts PageOptions) ([]godo.DomainRecord, error) {
+		return Collect(c.IterateDomainRecords(ctx, domain, opts))
+	}, resourceDomainRecords, domain)
+}
 
-	return list, nil
+func (c *Client) GetDomainRecord(ctx context.Context, domain string, recordID int) (*godo.DomainRecord, error) {
+	record, _, err := c.Domains.Record(ctx, domain, recordID)
+	return record, err
 }
 
-func (c *Client) CreateDomainRecord(ctx context.Context, domain, recordType, name, data string, priority int) (*godo.DomainRecord, error) {
-	createRequest := &godo.DomainRecordEditRequest{
-		Type:     recordType,
-		Name:     name,
-		Data:     data,
-		Priority: priority,
-	}
+func (c *Client) CreateDomainRecord(ctx context.Context, domain string, req *godo.DomainRecordEditRequest) (*godo.DomainRecord, error) {
+	defer c.cache.invalidate(resourceDomainRecords, domain)
+
+	record, _, err := c.Domains.CreateRecord(ctx, domain, req)
+	return record, err
+}
+
+// UpdateDomainRecord replaces the record with req. Priority, port, weight
+// and flags are always sent, so req should start from the current record.
+func (c *Client) UpdateDomainRecord(ctx context.Context, domain string, recordID int, req *godo.DomainRecordEditRequest) (*godo.DomainRecord, error) {
+	defer c.cache.invalidate(resourceDomainRecords, domain)
 
-	record, _, err := c.Domains.CreateRecord(ctx, domain, createRequest)
+	record, _, err := c.Domains.EditRecord(ctx, domain, recordID, req)
 	return record, err
 }
 
 func (c *Client) DeleteDomainRecord(ctx context.Context, domain string, recordID int) error {
+	defer c.cache.invalidate(resourceDomainRecords, domain)
+
 	_, err := c.Domains.DeleteRecord(ctx, domain, recordID)
 	return err
-}
\ No newline at end of file
+}
+
+func (c *Client) IterateSizes(ctx context.Context, opts PageOptions) *Iterator[godo.Size] {
+	return NewIterator(ctx, c.Sizes.List, opts)
+}
+
+func (c *Client) ListSizes(ctx context.Context, opts PageOptions) ([]godo.Size, error) {
+	return cachedList(c.cache, catalogTTL, opts, func(opts PageOptions) ([]godo.Size, error) {
+		return Collect(c.IterateSizes(ctx, opts))
+	}, resourceSizes)
+}
+
+func (c *Client) IterateRegions(ctx context.Context, opts PageOptions) *Iterator[godo.Region] {
+	return NewIterator(ctx, c.Regions.List, opts)
+}
+
+func (c *Client) ListRegions(ctx context.Context, opts PageOptions) ([]godo.Region, error) {
+	return cachedList(c.cache, catalogTTL, opts, func(opts PageOptions) ([]godo.Region, error) {
+		return Collect(c.IterateRegions(ctx, opts))
+	}, resourceRegions)
+}
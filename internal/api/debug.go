@@ -0,0 +1,105 @@
+package api
+
+import (
+	"bytes"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/felipepimentel/digitalocean-go/internal/logging"
+)
+
+const (
+	headerRequestID = "X-Request-Id"
+	headerRateLimit = "RateLimit-Limit"
+)
+
+// maxDumpBytes caps each body logged by debugTransport.
+const maxDumpBytes = 64 << 10
+
+// debugTransport logs every request passing through to next, along with
+// the response status, latency, request ID and rate limit headers. It sits
+// below the OAuth transport, like cassetteTransport, so that it sees what
+// is sent and can redact the Authorization header and the token.
+type debugTransport struct {
+	next   http.RoundTripper
+	bodies bool
+	secret string
+
+	// now is replaced in tests.
+	now func() time.Time
+}
+
+func newDebugTransport(next http.RoundTripper, bodies bool, secret string) *debugTransport {
+	return &debugTransport{next: next, bodies: bodies, secret: secret, now: time.Now}
+}
+
+func (t *debugTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	attrs := []any{
+		slog.String("method", req.Method),
+		slog.String("url", t.scrub(req.URL.String())),
+	}
+	if t.bodies {
+		body, err := readBody(req)
+		if err != nil {
+			return nil, err
+		}
+		attrs = append(attrs, slog.Any("request_header", t.header(req.Header)), slog.String("request_body", t.dump(body)))
+	}
+
+	start := t.now()
+	resp, err := t.next.RoundTrip(req)
+	attrs = append(attrs, slog.Duration("latency", t.now().Sub(start)))
+	if err != nil {
+		logging.Info("API request failed", append(attrs, slog.String("err", t.scrub(err.Error())))...)
+		return nil, err
+	}
+
+	attrs = append(attrs,
+		slog.Int("status", resp.StatusCode),
+		slog.String("request_id", resp.Header.Get(headerRequestID)),
+		slog.String("ratelimit_limit", resp.Header.Get(headerRateLimit)),
+		slog.String("ratelimit_remaining", resp.Header.Get(headerRateRemaining)),
+		slog.String("ratelimit_reset", resp.Header.Get(headerRateReset)),
+	)
+	if t.bodies {
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		resp.Body = io.NopCloser(bytes.NewReader(body))
+		attrs = append(attrs, slog.String("response_body", t.dump(body)))
+	}
+
+	logging.Info("API request", attrs...)
+	return resp, nil
+}
+
+// header returns a copy of h with the Authorization header redacted.
+func (t *debugTransport) header(h http.Header) map[string]string {
+	header := make(map[string]string, len(h))
+	for name, values := range h {
+		header[name] = t.scrub(strings.Join(values, ", "))
+	}
+	if _, ok := header["Authorization"]; ok {
+		header["Authorization"] = "Bearer " + redacted
+	}
+	return header
+}
+
+func (t *debugTransport) dump(body []byte) string {
+	if len(body) > maxDumpBytes {
+		return t.scrub(string(body[:maxDumpBytes])) + "...(truncated)"
+	}
+	return t.scrub(string(body))
+}
+
+func (t *debugTransport) scrub(s string) string {
+	if t.secret == "" {
+		return s
+	}
+	return strings.ReplaceAll(s, t.secret, redacted)
+}
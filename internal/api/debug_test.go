@@ -0,0 +1,72 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/felipepimentel/digitalocean-go/internal/config"
+	"github.com/felipepimentel/digitalocean-go/internal/logging"
+)
+
+func TestDebugHTTP(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Request-Id", "req-1")
+		w.Header().Set("RateLimit-Limit", "5000")
+		w.Header().Set("RateLimit-Remaining", "4999")
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		fmt.Fprint(w, `{"id":"unprocessable_entity","message":"Name is invalid"}`)
+	}))
+	defer server.Close()
+
+	var buf bytes.Buffer
+	previous := logging.Logger()
+	logging.SetLogger(slog.New(slog.NewJSONHandler(&buf, nil)))
+	defer logging.SetLogger(previous)
+
+	client := NewClient(&config.Config{
+		DOToken:         "secret-token",
+		APIURL:          server.URL + "/",
+		DebugHTTP:       true,
+		DebugHTTPBodies: true,
+	})
+	if _, err := client.CreateDomain(context.Background(), "bad_name"); err == nil {
+		t.Fatal("Expected the API error")
+	}
+
+	if strings.Contains(buf.String(), "secret-token") {
+		t.Errorf("Log contains the token:\n%s", buf.String())
+	}
+	var record struct {
+		Msg           string            `json:"msg"`
+		Method        string            `json:"method"`
+		URL           string            `json:"url"`
+		Status        int               `json:"status"`
+		RequestID     string            `json:"request_id"`
+		Remaining     string            `json:"ratelimit_remaining"`
+		RequestHeader map[string]string `json:"request_header"`
+		RequestBody   string            `json:"request_body"`
+		ResponseBody  string            `json:"response_body"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+		t.Fatalf("Expected a single JSON record, got %s", buf.String())
+	}
+	if record.Method != http.MethodPost || !strings.HasSuffix(record.URL, "/v2/domains") || record.Status != http.StatusUnprocessableEntity {
+		t.Errorf("Unexpected request summary %+v", record)
+	}
+	if record.RequestID != "req-1" || record.Remaining != "4999" {
+		t.Errorf("Expected the request ID and rate limit headers, got %+v", record)
+	}
+	if record.RequestHeader["Authorization"] != "Bearer REDACTED" {
+		t.Errorf("Expected a redacted Authorization header, got %q", record.RequestHeader["Authorization"])
+	}
+	if !strings.Contains(record.RequestBody, "bad_name") || !strings.Contains(record.ResponseBody, "Name is invalid") {
+		t.Errorf("Expected both bodies, got %q and %q", record.RequestBody, record.ResponseBody)
+	}
+}
@@ -0,0 +1,751 @@
+// Package fake provides an in-memory implementation of the api service
+// interfaces for testing commands without a DigitalOcean account.
+package fake
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/digitalocean/godo"
+	"github.com/felipepimentel/digitalocean-go/internal/api"
+	"github.com/felipepimentel/digitalocean-go/internal/config"
+)
+
+// ErrNotFound is returned for resources the fake does not hold.
+var ErrNotFound = api.ErrNotFound
+
+// Client keeps every resource in memory. The zero value is not usable; call
+// New. Resources may be seeded through the exported fields before the
+// client is used. Errors maps a method name, such as "CreateDroplet", to
+// the error that method should fail with, and Now stamps created resources.
+// A positive DropletLimit caps the number of droplets, as an account's
+// droplet limit does.
+type Client struct {
+	mu sync.Mutex
+
+	Droplets  []godo.Droplet
+	Actions   []godo.Action
+	Sizes     []godo.Size
+	Snapshots []godo.Snapshot
+	// Backups are the backup images of each droplet, by droplet ID.
+	Backups   map[int][]godo.Image
+	VPCs      []godo.VPC
+	Clusters  []*godo.KubernetesCluster
+	Databases []godo.Database
+	Domains   []godo.Domain
+	Records   map[string][]godo.DomainRecord
+	Balance   godo.Balance
+	Errors    map[string]error
+	Now       func() time.Time
+
+	DropletLimit int
+	// CreateStatus is the status of the create action recorded for each new
+	// droplet, completed when empty.
+	CreateStatus string
+
+	lastID int
+}
+
+var (
+	_ api.DropletAPI    = (*Client)(nil)
+	_ api.VPCAPI        = (*Client)(nil)
+	_ api.KubernetesAPI = (*Client)(nil)
+	_ api.DatabaseAPI   = (*Client)(nil)
+	_ api.DomainAPI     = (*Client)(nil)
+	_ api.BillingAPI    = (*Client)(nil)
+)
+
+func New() *Client {
+	return &Client{
+		Records: map[string][]godo.DomainRecord{},
+		Errors:  map[string]error{},
+		Now:     time.Now,
+	}
+}
+
+// Factory returns a constructor handing out c, in the shape the command
+// packages expect, e.g. droplet.Cmd(cfg, fake.Factory[api.DropletAPI](c)).
+func Factory[T any](c *Client) func(*config.Config) T {
+	return func(*config.Config) T {
+		return any(c).(T)
+	}
+}
+
+func (c *Client) fail(method string) error {
+	return c.Errors[method]
+}
+
+// nextID hands out increasing IDs, starting past any seeded ones.
+func (c *Client) nextID() int {
+	for _, d := range c.Droplets {
+		if d.ID > c.lastID {
+			c.lastID = d.ID
+		}
+	}
+	for _, a := range c.Actions {
+		if a.ID > c.lastID {
+			c.lastID = a.ID
+		}
+	}
+	for _, records := range c.Records {
+		for _, r := range records {
+			if r.ID > c.lastID {
+				c.lastID = r.ID
+			}
+		}
+	}
+	for _, s := range c.Snapshots {
+		if id, err := strconv.Atoi(s.ID); err == nil && id > c.lastID {
+			c.lastID = id
+		}
+	}
+	c.lastID++
+	return c.lastID
+}
+
+func (c *Client) nextUUID() string {
+	return fmt.Sprintf("00000000-0000-4000-8000-%012d", c.nextID())
+}
+
+func (c *Client) now() string {
+	return c.Now().UTC().Format(time.RFC3339)
+}
+
+// limit applies the Limit of opts, the only paging option that affects the
+// result of a list call.
+func limit[T any](items []T, opts api.PageOptions) []T {
+	list := append([]T{}, items...)
+	if opts.Limit > 0 && len(list) > opts.Limit {
+		list = list[:opts.Limit]
+	}
+	return list
+}
+
+func (c *Client) ListDroplets(ctx context.Context, opts api.PageOptions) ([]godo.Droplet, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.fail("ListDroplets"); err != nil {
+		return nil, err
+	}
+	return limit(c.Droplets, opts), nil
+}
+
+// CreateDroplet adds the droplet and records its create action with
+// CreateStatus.
+func (c *Client) CreateDroplet(ctx context.Context, req *godo.DropletCreateRequest) (*godo.Droplet, []godo.LinkAction, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.fail("CreateDroplet"); err != nil {
+		return nil, nil, err
+	}
+	if err := c.checkDropletLimit(1); err != nil {
+		return nil, nil, err
+	}
+
+	droplet := c.newDroplet(req)
+	c.Droplets = append(c.Droplets, droplet)
+	return &droplet, []godo.LinkAction{c.recordCreate(droplet)}, nil
+}
+
+func (c *Client) CreateDroplets(ctx context.Context, req *godo.DropletMultiCreateRequest) ([]godo.Droplet, []godo.LinkAction, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.fail("CreateDroplets"); err != nil {
+		return nil, nil, err
+	}
+	if len(req.Names) > api.MaxMultiCreate {
+		return nil, nil, fmt.Errorf("at most %d droplets can be created at once", api.MaxMultiCreate)
+	}
+	if err := c.checkDropletLimit(len(req.Names)); err != nil {
+		return nil, nil, err
+	}
+
+	var droplets []godo.Droplet
+	var links []godo.LinkAction
+	for _, name := range req.Names {
+		droplets = append(droplets, c.newDroplet(&godo.DropletCreateRequest{
+			Name:       name,
+			Region:     req.Region,
+			Size:       req.Size,
+			Image:      req.Image,
+			SSHKeys:    req.SSHKeys,
+			Backups:    req.Backups,
+			IPv6:       req.IPv6,
+			Monitoring: req.Monitoring,
+			UserData:   req.UserData,
+			Tags:       req.Tags,
+			VPCUUID:    req.VPCUUID,
+		}))
+	}
+	c.Droplets = append(c.Droplets, droplets...)
+	for _, d := range droplets {
+		links = append(links, c.recordCreate(d))
+	}
+	return droplets, links, nil
+}
+
+// checkDropletLimit fails, like the API does, when creating n more droplets
+// would exceed DropletLimit.
+func (c *Client) checkDropletLimit(n int) error {
+	if c.DropletLimit > 0 && len(c.Droplets)+n > c.DropletLimit {
+		return fmt.Errorf("creating %d droplets would exceed your droplet limit of %d", n, c.DropletLimit)
+	}
+	return nil
+}
+
+// recordCreate records the create action of a new droplet and returns the
+// link to it.
+func (c *Client) recordCreate(d godo.Droplet) godo.LinkAction {
+	action := c.recordAction(d, "create")
+	if c.CreateStatus != "" {
+		action.Status = c.CreateStatus
+		c.Actions[len(c.Actions)-1] = action
+	}
+	return godo.LinkAction{ID: action.ID, Rel: "create"}
+}
+
+// recordAction records a completed action on the droplet.
+func (c *Client) recordAction(d godo.Droplet, typ string) godo.Action {
+	now := &godo.Timestamp{Time: c.Now().UTC()}
+	action := godo.Action{
+		ID:           c.nextID(),
+		Type:         typ,
+		Status:       godo.ActionCompleted,
+		ResourceID:   d.ID,
+		ResourceType: "droplet",
+		StartedAt:    now,
+		CompletedAt:  now,
+	}
+	if d.Region != nil {
+		action.RegionSlug = d.Region.Slug
+	}
+	c.Actions = append(c.Actions, action)
+	return action
+}
+
+func (c *Client) newDroplet(req *godo.DropletCreateRequest) godo.Droplet {
+	droplet := godo.Droplet{
+		ID:       c.nextID(),
+		Name:     req.Name,
+		Status:   "active",
+		Region:   &godo.Region{Slug: req.Region},
+		Size:     &godo.Size{Slug: req.Size},
+		SizeSlug: req.Size,
+		Image:    &godo.Image{ID: req.Image.ID, Slug: req.Image.Slug},
+		Tags:     append([]string{}, req.Tags...),
+		VPCUUID:  req.VPCUUID,
+		Created:  c.now(),
+	}
+	for _, v := range req.Volumes {
+		droplet.VolumeIDs = append(droplet.VolumeIDs, v.ID)
+	}
+	for feature, on := range map[string]bool{"backups": req.Backups, "ipv6": req.IPv6, "monitoring": req.Monitoring} {
+		if on {
+			droplet.Features = append(droplet.Features, feature)
+		}
+	}
+	sort.Strings(droplet.Features)
+	return droplet
+}
+
+func (c *Client) DeleteDroplet(ctx context.Context, id int) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.fail("DeleteDroplet"); err != nil {
+		return err
+	}
+
+	for i, d := range c.Droplets {
+		if d.ID == id {
+			c.Droplets = append(c.Droplets[:i], c.Droplets[i+1:]...)
+			return nil
+		}
+	}
+	return fmt.Errorf("droplet %d: %w", id, ErrNotFound)
+}
+
+func (c *Client) GetDroplet(ctx context.Context, id int) (*godo.Droplet, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.fail("GetDroplet"); err != nil {
+		return nil, err
+	}
+
+	for _, d := range c.Droplets {
+		if d.ID == id {
+			return &d, nil
+		}
+	}
+	return nil, fmt.Errorf("droplet %d: %w", id, ErrNotFound)
+}
+
+func (c *Client) GetAction(ctx context.Context, id int) (*godo.Action, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.fail("GetAction"); err != nil {
+		return nil, err
+	}
+
+	for _, a := range c.Actions {
+		if a.ID == id {
+			return &a, nil
+		}
+	}
+	return nil, fmt.Errorf("action %d: %w", id, ErrNotFound)
+}
+
+// RunDropletAction applies the action to the droplet at once and records
+// it as completed.
+func (c *Client) RunDropletAction(ctx context.Context, id int, req api.DropletActionRequest) (*godo.Action, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.fail("RunDropletAction"); err != nil {
+		return nil, err
+	}
+
+	var d *godo.Droplet
+	for i := range c.Droplets {
+		if c.Droplets[i].ID == id {
+			d = &c.Droplets[i]
+		}
+	}
+	if d == nil {
+		return nil, fmt.Errorf("droplet %d: %w", id, ErrNotFound)
+	}
+
+	switch req.Type {
+	case api.ActionPowerOn, api.ActionReboot, api.ActionPowerCycle:
+		d.Status = "active"
+	case api.ActionPowerOff, api.ActionShutdown:
+		d.Status = "off"
+	case api.ActionRebuild:
+		d.Image = &godo.Image{ID: req.Image.ID, Slug: req.Image.Slug}
+		d.Status = "active"
+	case api.ActionRename:
+		d.Name = req.Name
+	case api.ActionEnableIPv6:
+		d.Features = append(d.Features, "ipv6")
+	case api.ActionEnableBackups:
+		d.Features = append(d.Features, "backups")
+	case api.ActionPasswordReset:
+	case api.ActionResize:
+		if d.Status != "off" {
+			return nil, fmt.Errorf("droplet %d is currently on: power it off to resize it", id)
+		}
+		size := godo.Size{Slug: req.Size}
+		for _, s := range c.Sizes {
+			if s.Slug == req.Size {
+				size = s
+			}
+		}
+		d.Size = &size
+		d.SizeSlug = size.Slug
+		d.Memory, d.Vcpus = size.Memory, size.Vcpus
+		if req.Disk {
+			d.Disk = size.Disk
+		}
+	case api.ActionSnapshot:
+		c.Snapshots = append(c.Snapshots, godo.Snapshot{
+			ID:            strconv.Itoa(c.nextID()),
+			Name:          req.Name,
+			ResourceID:    strconv.Itoa(id),
+			ResourceType:  "droplet",
+			Regions:       []string{},
+			MinDiskSize:   d.Disk,
+			SizeGigaBytes: float64(d.Disk) / 10,
+			Created:       c.now(),
+		})
+	case api.ActionRestore:
+		found := false
+		for _, b := range c.Backups[id] {
+			found = found || b.ID == req.Image.ID
+		}
+		if !found {
+			return nil, fmt.Errorf("image %d is not a backup of droplet %d", req.Image.ID, id)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported droplet action %q", req.Type)
+	}
+
+	action := c.recordAction(*d, req.Type)
+	return &action, nil
+}
+
+func (c *Client) ListSizes(ctx context.Context, opts api.PageOptions) ([]godo.Size, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.fail("ListSizes"); err != nil {
+		return nil, err
+	}
+	return limit(c.Sizes, opts), nil
+}
+
+func (c *Client) ListDropletSnapshots(ctx context.Context, opts api.PageOptions) ([]godo.Snapshot, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.fail("ListDropletSnapshots"); err != nil {
+		return nil, err
+	}
+	return limit(c.Snapshots, opts), nil
+}
+
+func (c *Client) DeleteSnapshot(ctx context.Context, id string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.fail("DeleteSnapshot"); err != nil {
+		return err
+	}
+
+	for i, s := range c.Snapshots {
+		if s.ID == id {
+			c.Snapshots = append(c.Snapshots[:i], c.Snapshots[i+1:]...)
+			return nil
+		}
+	}
+	return fmt.Errorf("snapshot %s: %w", id, ErrNotFound)
+}
+
+func (c *Client) ListDropletBackups(ctx context.Context, id int) ([]godo.Image, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.fail("ListDropletBackups"); err != nil {
+		return nil, err
+	}
+	for _, d := range c.Droplets {
+		if d.ID == id {
+			return append([]godo.Image{}, c.Backups[id]...), nil
+		}
+	}
+	return nil, fmt.Errorf("droplet %d: %w", id, ErrNotFound)
+}
+
+func (c *Client) ListVPCs(ctx context.Context, opts api.PageOptions) ([]godo.VPC, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.fail("ListVPCs"); err != nil {
+		return nil, err
+	}
+	return limit(c.VPCs, opts), nil
+}
+
+func (c *Client) CreateVPC(ctx context.Context, name, region, ipRange string) (*godo.VPC, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.fail("CreateVPC"); err != nil {
+		return nil, err
+	}
+
+	vpc := godo.VPC{
+		ID:          c.nextUUID(),
+		Name:        name,
+		RegionSlug:  region,
+		IPRange:     ipRange,
+		Description: "Created via DigitalOcean CLI",
+		CreatedAt:   c.Now().UTC(),
+	}
+	c.VPCs = append(c.VPCs, vpc)
+	return &vpc, nil
+}
+
+func (c *Client) DeleteVPC(ctx context.Context, id string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.fail("DeleteVPC"); err != nil {
+		return err
+	}
+
+	for i, v := range c.VPCs {
+		if v.ID == id {
+			c.VPCs = append(c.VPCs[:i], c.VPCs[i+1:]...)
+			return nil
+		}
+	}
+	return fmt.Errorf("vpc %s: %w", id, ErrNotFound)
+}
+
+func (c *Client) ListKubernetesClusters(ctx context.Context, opts api.PageOptions) ([]*godo.KubernetesCluster, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.fail("ListKubernetesClusters"); err != nil {
+		return nil, err
+	}
+	return limit(c.Clusters, opts), nil
+}
+
+func (c *Client) CreateKubernetesCluster(ctx context.Context, name, region, version string, numNodes int) (*godo.KubernetesCluster, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.fail("CreateKubernetesCluster"); err != nil {
+		return nil, err
+	}
+
+	cluster := &godo.KubernetesCluster{
+		ID:          c.nextUUID(),
+		Name:        name,
+		RegionSlug:  region,
+		VersionSlug: version,
+		NodePools: []*godo.KubernetesNodePool{
+			{Name: "worker-pool", Size: "s-2vcpu-2gb", Count: numNodes},
+		},
+		Status:    &godo.KubernetesClusterStatus{State: godo.KubernetesClusterStatusRunning},
+		CreatedAt: c.Now().UTC(),
+	}
+	c.Clusters = append(c.Clusters, cluster)
+	return cluster, nil
+}
+
+func (c *Client) DeleteKubernetesCluster(ctx context.Context, id string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.fail("DeleteKubernetesCluster"); err != nil {
+		return err
+	}
+
+	for i, k := range c.Clusters {
+		if k.ID == id {
+			c.Clusters = append(c.Clusters[:i], c.Clusters[i+1:]...)
+			return nil
+		}
+	}
+	return fmt.Errorf("kubernetes cluster %s: %w", id, ErrNotFound)
+}
+
+func (c *Client) GetKubernetesCluster(ctx context.Context, id string) (*godo.KubernetesCluster, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.fail("GetKubernetesCluster"); err != nil {
+		return nil, err
+	}
+
+	for _, k := range c.Clusters {
+		if k.ID == id {
+			return k, nil
+		}
+	}
+	return nil, fmt.Errorf("kubernetes cluster %s: %w", id, ErrNotFound)
+}
+
+func (c *Client) ListDatabases(ctx context.Context, opts api.PageOptions) ([]godo.Database, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.fail("ListDatabases"); err != nil {
+		return nil, err
+	}
+	return limit(c.Databases, opts), nil
+}
+
+func (c *Client) CreateDatabase(ctx context.Context, name, engine, version, size, region string) (*godo.Database, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.fail("CreateDatabase"); err != nil {
+		return nil, err
+	}
+
+	database := godo.Database{
+		ID:          c.nextUUID(),
+		Name:        name,
+		EngineSlug:  engine,
+		VersionSlug: version,
+		SizeSlug:    size,
+		RegionSlug:  region,
+		NumNodes:    1,
+		Status:      "online",
+		CreatedAt:   c.Now().UTC(),
+	}
+	c.Databases = append(c.Databases, database)
+	return &database, nil
+}
+
+func (c *Client) DeleteDatabase(ctx context.Context, id string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.fail("DeleteDatabase"); err != nil {
+		return err
+	}
+
+	for i, d := range c.Databases {
+		if d.ID == id {
+			c.Databases = append(c.Databases[:i], c.Databases[i+1:]...)
+			return nil
+		}
+	}
+	return fmt.Errorf("database %s: %w", id, ErrNotFound)
+}
+
+func (c *Client) GetDatabase(ctx context.Context, id string) (*godo.Database, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.fail("GetDatabase"); err != nil {
+		return nil, err
+	}
+
+	for _, d := range c.Databases {
+		if d.ID == id {
+			return &d, nil
+		}
+	}
+	return nil, fmt.Errorf("database %s: %w", id, ErrNotFound)
+}
+
+func (c *Client) ListDomains(ctx context.Context, opts api.PageOptions) ([]godo.Domain, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.fail("ListDomains"); err != nil {
+		return nil, err
+	}
+	return limit(c.Domains, opts), nil
+}
+
+func (c *Client) CreateDomain(ctx context.Context, name string) (*godo.Domain, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.fail("CreateDomain"); err != nil {
+		return nil, err
+	}
+
+	for _, d := range c.Domains {
+		if strings.EqualFold(d.Name, name) {
+			return nil, fmt.Errorf("domain %s already exists", name)
+		}
+	}
+	domain := godo.Domain{Name: name, TTL: 1800}
+	c.Domains = append(c.Domains, domain)
+	sort.Slice(c.Domains, func(i, j int) bool { return c.Domains[i].Name < c.Domains[j].Name })
+	return &domain, nil
+}
+
+func (c *Client) DeleteDomain(ctx context.Context, name string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.fail("DeleteDomain"); err != nil {
+		return err
+	}
+
+	for i, d := range c.Domains {
+		if d.Name == name {
+			c.Domains = append(c.Domains[:i], c.Domains[i+1:]...)
+			delete(c.Records, name)
+			return nil
+		}
+	}
+	return fmt.Errorf("domain %s: %w", name, ErrNotFound)
+}
+
+func (c *Client) hasDomain(name string) bool {
+	for _, d := range c.Domains {
+		if d.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *Client) ListDomainRecords(ctx context.Context, domain string, opts api.PageOptions) ([]godo.DomainRecord, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.fail("ListDomainRecords"); err != nil {
+		return nil, err
+	}
+	if !c.hasDomain(domain) {
+		return nil, fmt.Errorf("domain %s: %w", domain, ErrNotFound)
+	}
+	return limit(c.Records[domain], opts), nil
+}
+
+func (c *Client) GetDomainRecord(ctx context.Context, domain string, recordID int) (*godo.DomainRecord, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.fail("GetDomainRecord"); err != nil {
+		return nil, err
+	}
+
+	for _, r := range c.Records[domain] {
+		if r.ID == recordID {
+			return &r, nil
+		}
+	}
+	return nil, fmt.Errorf("record %d of domain %s: %w", recordID, domain, ErrNotFound)
+}
+
+func (c *Client) CreateDomainRecord(ctx context.Context, domain string, req *godo.DomainRecordEditRequest) (*godo.DomainRecord, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.fail("CreateDomainRecord"); err != nil {
+		return nil, err
+	}
+	if !c.hasDomain(domain) {
+		return nil, fmt.Errorf("domain %s: %w", domain, ErrNotFound)
+	}
+
+	record := recordFromRequest(c.nextID(), req)
+	c.Records[domain] = append(c.Records[domain], record)
+	return &record, nil
+}
+
+func (c *Client) UpdateDomainRecord(ctx context.Context, domain string, recordID int, req *godo.DomainRecordEditRequest) (*godo.DomainRecord, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.fail("UpdateDomainRecord"); err != nil {
+		return nil, err
+	}
+
+	records := c.Records[domain]
+	for i, r := range records {
+		if r.ID == recordID {
+			records[i] = recordFromRequest(recordID, req)
+			return &records[i], nil
+		}
+	}
+	return nil, fmt.Errorf("record %d of domain %s: %w", recordID, domain, ErrNotFound)
+}
+
+func recordFromRequest(id int, req *godo.DomainRecordEditRequest) godo.DomainRecord {
+	record := godo.DomainRecord{
+		ID:       id,
+		Type:     req.Type,
+		Name:     req.Name,
+		Data:     req.Data,
+		Priority: req.Priority,
+		Port:     req.Port,
+		TTL:      req.TTL,
+		Weight:   req.Weight,
+		Flags:    req.Flags,
+		Tag:      req.Tag,
+	}
+	if record.TTL == 0 {
+		record.TTL = 1800
+	}
+	return record
+}
+
+func (c *Client) DeleteDomainRecord(ctx context.Context, domain string, recordID int) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.fail("DeleteDomainRecord"); err != nil {
+		return err
+	}
+
+	records := c.Records[domain]
+	for i, r := range records {
+		if r.ID == recordID {
+			c.Records[domain] = append(records[:i], records[i+1:]...)
+			return nil
+		}
+	}
+	return fmt.Errorf("record %d of domain %s: %w", recordID, domain, ErrNotFound)
+}
+
+func (c *Client) GetBillingInfo(ctx context.Context) (*godo.Balance, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.fail("GetBillingInfo"); err != nil {
+		return nil, err
+	}
+	balance := c.Balance
+	return &balance, nil
+}
@@ -0,0 +1,223 @@
+package api
+
+import (
+	"context"
+	"sync"
+
+	"github.com/digitalocean/godo"
+	"github.com/felipepimentel/digitalocean-go/internal/config"
+)
+
+const defaultPerPage = 100
+
+// PageFunc fetches a single page of a paginated godo list call.
+type PageFunc[T any] func(ctx context.Context, opt *godo.ListOptions) ([]T, *godo.Response, error)
+
+// PageOptions controls how a list is paged through. Limit caps the number
+// of items returned (0 means all of them) and Prefetch is the number of
+// pages fetched concurrently ahead of the consumer (0 fetches one page at a
+// time, on demand).
+type PageOptions struct {
+	PerPage  int
+	Limit    int
+	Prefetch int
+}
+
+// Iterator streams the items of a paginated list page by page:
+//
+//	it := api.NewIterator(ctx, fetch, opts)
+//	defer it.Close()
+//	for it.Next() {
+//		item := it.Item()
+//	}
+//	if err := it.Err(); err != nil { ... }
+//
+// Stopping early, reaching the limit or cancelling ctx stops any pages
+// still being fetched.
+type Iterator[T any] struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	fetch  PageFunc[T]
+	opts   PageOptions
+
+	buffer []T
+	item   T
+	count  int
+	page   int
+	done   bool
+	err    error
+
+	// pages delivers prefetched pages in order when opts.Prefetch > 0.
+	pages chan pageResult[T]
+	wg    sync.WaitGroup
+}
+
+type pageResult[T any] struct {
+	items []T
+	last  bool
+	err   error
+}
+
+// PageOptionsFromConfig returns the paging selected by the global --limit
+// flag.
+func PageOptionsFromConfig(cfg *config.Config) PageOptions {
+	return PageOptions{Limit: cfg.Limit}
+}
+
+func NewIterator[T any](ctx context.Context, fetch PageFunc[T], opts PageOptions) *Iterator[T] {
+	if opts.PerPage <= 0 {
+		opts.PerPage = defaultPerPage
+	}
+	if opts.Limit > 0 && opts.Limit < opts.PerPage {
+		opts.PerPage = opts.Limit
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	it := &Iterator[T]{
+		ctx:    ctx,
+		cancel: cancel,
+		fetch:  fetch,
+		opts:   opts,
+	}
+	if opts.Prefetch > 0 {
+		it.pages = make(chan pageResult[T], opts.Prefetch)
+		it.wg.Add(1)
+		go it.prefetch()
+	}
+	return it
+}
+
+// Next advances to the next item, fetching pages as needed. It returns
+// false when the list is exhausted, the limit is reached or an error
+// occurred.
+func (it *Iterator[T]) Next() bool {
+	if it.opts.Limit > 0 && it.count >= it.opts.Limit {
+		it.Close()
+		return false
+	}
+
+	for len(it.buffer) == 0 {
+		if it.done || it.err != nil {
+			return false
+		}
+		if err := it.ctx.Err(); err != nil {
+			it.err = err
+			return false
+		}
+
+		page := it.nextPage()
+		if page.err != nil {
+			it.err = page.err
+			it.Close()
+			return false
+		}
+		it.buffer = page.items
+		it.done = page.last
+	}
+
+	it.item, it.buffer = it.buffer[0], it.buffer[1:]
+	it.count++
+	return true
+}
+
+// Item returns the current item.
+func (it *Iterator[T]) Item() T {
+	return it.item
+}
+
+// Err returns the error that stopped the iteration, if any.
+func (it *Iterator[T]) Err() error {
+	return it.err
+}
+
+// Close stops any in-flight page fetches. It is safe to call more than once.
+func (it *Iterator[T]) Close() {
+	it.cancel()
+	if it.pages != nil {
+		// Unblock the producer and wait for it to finish.
+		go func() {
+			for range it.pages {
+			}
+		}()
+		it.wg.Wait()
+	}
+}
+
+func (it *Iterator[T]) nextPage() pageResult[T] {
+	if it.pages == nil {
+		it.page++
+		return it.fetchPage(it.ctx, it.page)
+	}
+
+	page, ok := <-it.pages
+	if !ok {
+		if err := it.ctx.Err(); err != nil {
+			return pageResult[T]{err: err}
+		}
+		return pageResult[T]{last: true}
+	}
+	return page
+}
+
+func (it *Iterator[T]) fetchPage(ctx context.Context, page int) pageResult[T] {
+	items, resp, err := it.fetch(ctx, &godo.ListOptions{Page: page, PerPage: it.opts.PerPage})
+	if err != nil {
+		return pageResult[T]{err: err}
+	}
+	last := len(items) == 0 || resp == nil || resp.Links == nil || resp.Links.IsLastPage()
+	return pageResult[T]{items: items, last: last}
+}
+
+// prefetch keeps up to opts.Prefetch pages in flight beyond the one being
+// consumed and delivers them in page order. Pages requested past the end of
+// the list come back empty and are discarded.
+func (it *Iterator[T]) prefetch() {
+	defer it.wg.Done()
+	defer close(it.pages)
+
+	// Speculative requests are cancelled once the last page is known,
+	// without cancelling the iterator's own context.
+	ctx, stop := context.WithCancel(it.ctx)
+	defer stop()
+
+	var inflight []chan pageResult[T]
+	next := 1
+	for {
+		for len(inflight) <= it.opts.Prefetch {
+			result := make(chan pageResult[T], 1)
+			go func(page int) {
+				result <- it.fetchPage(ctx, page)
+			}(next)
+			inflight = append(inflight, result)
+			next++
+		}
+
+		var page pageResult[T]
+		select {
+		case page = <-inflight[0]:
+		case <-it.ctx.Done():
+			return
+		}
+		inflight = inflight[1:]
+
+		select {
+		case it.pages <- page:
+		case <-it.ctx.Done():
+			return
+		}
+		if page.err != nil || page.last {
+			return
+		}
+	}
+}
+
+// Collect drains it into a slice and closes it.
+func Collect[T any](it *Iterator[T]) ([]T, error) {
+	defer it.Close()
+
+	list := []T{}
+	for it.Next() {
+		list = append(list, it.Item())
+	}
+	return list, it.Err()
+}
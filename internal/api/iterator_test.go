@@ -0,0 +1,126 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/digitalocean/godo"
+)
+
+// fakePages serves total integers in pages, recording which pages were
+// requested.
+type fakePages struct {
+	total int
+
+	mu        sync.Mutex
+	requested []int
+	failPage  int
+}
+
+func (f *fakePages) fetch(ctx context.Context, opt *godo.ListOptions) ([]int, *godo.Response, error) {
+	f.mu.Lock()
+	f.requested = append(f.requested, opt.Page)
+	f.mu.Unlock()
+
+	if err := ctx.Err(); err != nil {
+		return nil, nil, err
+	}
+	if opt.Page == f.failPage {
+		return nil, nil, errors.New("boom")
+	}
+
+	var items []int
+	for i := (opt.Page - 1) * opt.PerPage; i < opt.Page*opt.PerPage && i < f.total; i++ {
+		items = append(items, i)
+	}
+
+	links := &godo.Links{Pages: &godo.Pages{}}
+	if opt.Page*opt.PerPage < f.total {
+		links.Pages.Next = "next"
+		links.Pages.Last = "last"
+	}
+	return items, &godo.Response{Links: links}, nil
+}
+
+func TestIteratorCollectsAllPages(t *testing.T) {
+	for _, prefetch := range []int{0, 1, 3} {
+		pages := &fakePages{total: 25}
+		items, err := Collect(NewIterator(context.Background(), pages.fetch, PageOptions{PerPage: 10, Prefetch: prefetch}))
+		if err != nil {
+			t.Fatalf("prefetch %d: Collect returned error: %v", prefetch, err)
+		}
+		if len(items) != 25 {
+			t.Fatalf("prefetch %d: expected 25 items, got %d", prefetch, len(items))
+		}
+		for i, item := range items {
+			if item != i {
+				t.Fatalf("prefetch %d: items out of order: %v", prefetch, items)
+			}
+		}
+	}
+}
+
+func TestIteratorLimitStopsEarly(t *testing.T) {
+	pages := &fakePages{total: 1000}
+	items, err := Collect(NewIterator(context.Background(), pages.fetch, PageOptions{PerPage: 10, Limit: 15}))
+	if err != nil {
+		t.Fatalf("Collect returned error: %v", err)
+	}
+	if len(items) != 15 {
+		t.Errorf("Expected 15 items, got %d", len(items))
+	}
+	if len(pages.requested) != 2 {
+		t.Errorf("Expected 2 pages to be requested, got %v", pages.requested)
+	}
+
+	// A limit below the page size shrinks the pages requested.
+	small := &fakePages{total: 1000}
+	items, _ = Collect(NewIterator(context.Background(), small.fetch, PageOptions{Limit: 5}))
+	if len(items) != 5 || len(small.requested) != 1 {
+		t.Errorf("Expected 5 items from one page, got %d from %v", len(items), small.requested)
+	}
+}
+
+func TestIteratorPropagatesErrors(t *testing.T) {
+	for _, prefetch := range []int{0, 2} {
+		pages := &fakePages{total: 100, failPage: 3}
+		items, err := Collect(NewIterator(context.Background(), pages.fetch, PageOptions{PerPage: 10, Prefetch: prefetch}))
+		if err == nil || err.Error() != "boom" {
+			t.Errorf("prefetch %d: expected the page error, got %v", prefetch, err)
+		}
+		if len(items) != 20 {
+			t.Errorf("prefetch %d: expected the 20 items before the failure, got %d", prefetch, len(items))
+		}
+	}
+}
+
+func TestIteratorCancellation(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var fetched int32
+	fetch := func(ctx context.Context, opt *godo.ListOptions) ([]int, *godo.Response, error) {
+		atomic.AddInt32(&fetched, 1)
+		links := &godo.Links{Pages: &godo.Pages{Next: "next", Last: "last"}}
+		return make([]int, opt.PerPage), &godo.Response{Links: links}, ctx.Err()
+	}
+
+	it := NewIterator(ctx, fetch, PageOptions{PerPage: 10, Prefetch: 2})
+	defer it.Close()
+
+	count := 0
+	for it.Next() {
+		count++
+		if count == 5 {
+			cancel()
+		}
+		if count > 100 {
+			t.Fatal("Iteration did not stop after cancellation")
+		}
+	}
+	if !errors.Is(it.Err(), context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", it.Err())
+	}
+}
@@ -0,0 +1,240 @@
+package api
+
+import (
+	"context"
+	"io"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// RetryPolicy controls how requests failing with 429, a 5xx status or a
+// network error are retried. Delays grow exponentially from BaseDelay up to
+// MaxDelay with random jitter, unless the API says how long to wait.
+type RetryPolicy struct {
+	MaxRetries int
+	BaseDelay  time.Duration
+	MaxDelay   time.Duration
+}
+
+var DefaultRetryPolicy = RetryPolicy{
+	MaxRetries: 3,
+	BaseDelay:  500 * time.Millisecond,
+	MaxDelay:   30 * time.Second,
+}
+
+const (
+	headerRetryAfter    = "Retry-After"
+	headerRateRemaining = "RateLimit-Remaining"
+	headerRateReset     = "RateLimit-Reset"
+)
+
+// retryTransport retries failed requests according to its policy, spaces
+// requests out with a token bucket and holds off every request once the
+// API reports the account's quota as exhausted until it resets.
+type retryTransport struct {
+	next    http.RoundTripper
+	policy  RetryPolicy
+	limiter *rate.Limiter
+
+	mu          sync.Mutex
+	pausedUntil time.Time
+
+	// sleep is replaced in tests.
+	sleep func(ctx context.Context, d time.Duration) error
+}
+
+// newRetryTransport wraps next. A requestsPerSecond of zero or less
+// disables the client-side rate limit.
+func newRetryTransport(next http.RoundTripper, policy RetryPolicy, requestsPerSecond float64) *retryTransport {
+	t := &retryTransport{
+		next:   next,
+		policy: policy,
+		sleep:  sleepContext,
+	}
+	if requestsPerSecond > 0 {
+		burst := int(requestsPerSecond)
+		if burst < 1 {
+			burst = 1
+		}
+		t.limiter = rate.NewLimiter(rate.Limit(requestsPerSecond), burst)
+	}
+	return t
+}
+
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	canReplay := req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+
+	for attempt := 0; ; attempt++ {
+		if err := t.wait(ctx); err != nil {
+			return nil, err
+		}
+
+		attemptReq := req
+		if attempt > 0 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			attemptReq = req.Clone(ctx)
+			attemptReq.Body = body
+		}
+
+		resp, err := t.next.RoundTrip(attemptReq)
+		if resp != nil {
+			t.observe(resp)
+		}
+
+		if attempt >= t.policy.MaxRetries || !canReplay || !t.shouldRetry(req, resp, err) {
+			return resp, err
+		}
+
+		delay := t.delay(attempt, resp)
+		if resp != nil {
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+		if err := t.sleep(ctx, delay); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// wait blocks until the token bucket and any quota pause allow a request.
+func (t *retryTransport) wait(ctx context.Context) error {
+	t.mu.Lock()
+	pause := t.capDelay(time.Until(t.pausedUntil))
+	t.mu.Unlock()
+
+	if pause > 0 {
+		if err := t.sleep(ctx, pause); err != nil {
+			return err
+		}
+	}
+	if t.limiter != nil {
+		return t.limiter.Wait(ctx)
+	}
+	return nil
+}
+
+// observe records a pause when the response reports no remaining quota.
+func (t *retryTransport) observe(resp *http.Response) {
+	remaining := resp.Header.Get(headerRateRemaining)
+	if remaining != "0" {
+		return
+	}
+	reset, ok := rateReset(resp)
+	if !ok {
+		return
+	}
+
+	t.mu.Lock()
+	if reset.After(t.pausedUntil) {
+		t.pausedUntil = reset
+	}
+	t.mu.Unlock()
+}
+
+// shouldRetry retries rate-limited requests, which the API has not acted
+// on, always. Server errors and network failures are only retried for
+// idempotent methods so that creates are never issued twice.
+func (t *retryTransport) shouldRetry(req *http.Request, resp *http.Response, err error) bool {
+	if err != nil {
+		return req.Context().Err() == nil && idempotent(req.Method)
+	}
+
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests:
+		return true
+	case http.StatusInternalServerError, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return idempotent(req.Method)
+	}
+	return false
+}
+
+func idempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// delay prefers the server's Retry-After, then the rate limit reset for 429
+// responses, and falls back to jittered exponential backoff.
+func (t *retryTransport) delay(attempt int, resp *http.Response) time.Duration {
+	if resp != nil {
+		if d, ok := retryAfter(resp); ok {
+			return t.capDelay(d)
+		}
+		if resp.StatusCode == http.StatusTooManyRequests {
+			if reset, ok := rateReset(resp); ok {
+				return t.capDelay(time.Until(reset))
+			}
+		}
+	}
+
+	backoff := t.policy.BaseDelay << uint(attempt)
+	if backoff <= 0 || backoff > t.policy.MaxDelay {
+		backoff = t.policy.MaxDelay
+	}
+	// Equal jitter: half fixed, half random.
+	half := backoff / 2
+	return half + time.Duration(rand.Int63n(int64(half)+1))
+}
+
+func (t *retryTransport) capDelay(d time.Duration) time.Duration {
+	if d < 0 {
+		return 0
+	}
+	if t.policy.MaxDelay > 0 && d > t.policy.MaxDelay {
+		return t.policy.MaxDelay
+	}
+	return d
+}
+
+func retryAfter(resp *http.Response) (time.Duration, bool) {
+	value := resp.Header.Get(headerRetryAfter)
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		return time.Until(date), true
+	}
+	return 0, false
+}
+
+func rateReset(resp *http.Response) (time.Time, bool) {
+	value := resp.Header.Get(headerRateReset)
+	if value == "" {
+		return time.Time{}, false
+	}
+	seconds, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || seconds <= 0 {
+		return time.Time{}, false
+	}
+	return time.Unix(seconds, 0), true
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
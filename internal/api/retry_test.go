@@ -0,0 +1,134 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/digitalocean/godo"
+	"github.com/felipepimentel/digitalocean-go/internal/config"
+)
+
+func newTestClient(t *testing.T, handler http.Handler) *Client {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	return NewClient(&config.Config{
+		DOToken:    "test-token",
+		APIURL:     server.URL + "/",
+		MaxRetries: 3,
+	})
+}
+
+func TestListDropletsRetriesRateLimitedPages(t *testing.T) {
+	var calls int32
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) <= 2 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			fmt.Fprint(w, `{"id":"too_many_requests","message":"slow down"}`)
+			return
+		}
+		fmt.Fprint(w, `{"droplets":[{"id":1,"name":"web-1"}],"links":{},"meta":{"total":1}}`)
+	}))
+
+	droplets, err := client.ListDroplets(context.Background(), PageOptions{})
+	if err != nil {
+		t.Fatalf("ListDroplets returned error: %v", err)
+	}
+	if len(droplets) != 1 || droplets[0].Name != "web-1" {
+		t.Errorf("Unexpected droplets: %+v", droplets)
+	}
+	if calls != 3 {
+		t.Errorf("Expected 3 requests, got %d", calls)
+	}
+}
+
+func TestCreateIsNotRetriedOnServerError(t *testing.T) {
+	var calls int32
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusBadGateway)
+		fmt.Fprint(w, `{"id":"bad_gateway","message":"upstream failed"}`)
+	}))
+
+	_, _, err := client.CreateDroplet(context.Background(), &godo.DropletCreateRequest{Name: "web-1", Region: "nyc1", Size: "s-1vcpu-1gb", Image: godo.DropletCreateImage{Slug: "ubuntu-20-04-x64"}})
+	if err == nil {
+		t.Fatal("Expected an error")
+	}
+	if calls != 1 {
+		t.Errorf("Expected a single request for a create, got %d", calls)
+	}
+}
+
+func TestRetryTransportBackoffAndQuota(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&calls, 1)
+		w.Header().Set("RateLimit-Remaining", "0")
+		w.Header().Set("RateLimit-Reset", strconv.FormatInt(time.Now().Add(10*time.Second).Unix(), 10))
+		if n < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	var sleeps []time.Duration
+	transport := newRetryTransport(http.DefaultTransport, RetryPolicy{MaxRetries: 5, BaseDelay: time.Second, MaxDelay: 4 * time.Second}, 0)
+	transport.sleep = func(ctx context.Context, d time.Duration) error {
+		sleeps = append(sleeps, d)
+		return nil
+	}
+
+	resp, err := (&http.Client{Transport: transport}).Get(server.URL)
+	if err != nil {
+		t.Fatalf("Get returned error: %v", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK || calls != 3 {
+		t.Fatalf("Expected success after 3 requests, got %d after %d", resp.StatusCode, calls)
+	}
+
+	// Each attempt after the first waits for its backoff and for the quota
+	// pause, which is capped at MaxDelay.
+	if len(sleeps) != 4 {
+		t.Fatalf("Expected 4 sleeps, got %v", sleeps)
+	}
+	if sleeps[0] < 500*time.Millisecond || sleeps[0] > time.Second {
+		t.Errorf("First backoff %v outside [0.5s, 1s]", sleeps[0])
+	}
+	if sleeps[2] < time.Second || sleeps[2] > 2*time.Second {
+		t.Errorf("Second backoff %v outside [1s, 2s]", sleeps[2])
+	}
+	if sleeps[1] != 4*time.Second {
+		t.Errorf("Expected the quota pause to be capped at 4s, got %v", sleeps[1])
+	}
+}
+
+func TestRetryTransportTokenBucket(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	client := &http.Client{Transport: newRetryTransport(http.DefaultTransport, DefaultRetryPolicy, 20)}
+	start := time.Now()
+	for i := 0; i < 30; i++ {
+		resp, err := client.Get(server.URL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+	}
+
+	// A burst of 20 passes immediately and the remaining 10 take ~0.5s.
+	if elapsed := time.Since(start); elapsed < 400*time.Millisecond {
+		t.Errorf("Expected the rate limit to slow requests down, took %v", elapsed)
+	}
+}
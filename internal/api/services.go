@@ -0,0 +1,100 @@
+package api
+
+import (
+	"context"
+
+	"github.com/digitalocean/godo"
+	"github.com/felipepimentel/digitalocean-go/internal/config"
+)
+
+// MaxMultiCreate is the most droplets a single CreateDroplets call may create.
+const MaxMultiCreate = 10
+
+// The interfaces below are the narrow slices of Client each command package
+// depends on, so commands can be run against the fakes in api/fake.
+
+type DropletAPI interface {
+	ListDroplets(ctx context.Context, opts PageOptions) ([]godo.Droplet, error)
+	CreateDroplet(ctx context.Context, req *godo.DropletCreateRequest) (*godo.Droplet, []godo.LinkAction, error)
+	CreateDroplets(ctx context.Context, req *godo.DropletMultiCreateRequest) ([]godo.Droplet, []godo.LinkAction, error)
+	DeleteDroplet(ctx context.Context, id int) error
+	GetDroplet(ctx context.Context, id int) (*godo.Droplet, error)
+	GetAction(ctx context.Context, id int) (*godo.Action, error)
+	RunDropletAction(ctx context.Context, id int, req DropletActionRequest) (*godo.Action, error)
+	ListSizes(ctx context.Context, opts PageOptions) ([]godo.Size, error)
+	ListDropletSnapshots(ctx context.Context, opts PageOptions) ([]godo.Snapshot, error)
+	DeleteSnapshot(ctx context.Context, id string) error
+	ListDropletBackups(ctx context.Context, id int) ([]godo.Image, error)
+}
+
+type VPCAPI interface {
+	ListVPCs(ctx context.Context, opts PageOptions) ([]godo.VPC, error)
+	CreateVPC(ctx context.Context, name, region, ipRange string) (*godo.VPC, error)
+	DeleteVPC(ctx context.Context, id string) error
+}
+
+type KubernetesAPI interface {
+	ListKubernetesClusters(ctx context.Context, opts PageOptions) ([]*godo.KubernetesCluster, error)
+	CreateKubernetesCluster(ctx context.Context, name, region, version string, numNodes int) (*godo.KubernetesCluster, error)
+	DeleteKubernetesCluster(ctx context.Context, id string) error
+	GetKubernetesCluster(ctx context.Context, id string) (*godo.KubernetesCluster, error)
+}
+
+type DatabaseAPI interface {
+	ListDatabases(ctx context.Context, opts PageOptions) ([]godo.Database, error)
+	CreateDatabase(ctx context.Context, name, engine, version, size, region string) (*godo.Database, error)
+	DeleteDatabase(ctx context.Context, id string) error
+	GetDatabase(ctx context.Context, id string) (*godo.Database, error)
+}
+
+type DomainAPI interface {
+	ListDomains(ctx context.Context, opts PageOptions) ([]godo.Domain, error)
+	CreateDomain(ctx context.Context, name string) (*godo.Domain, error)
+	DeleteDomain(ctx context.Context, name string) error
+	ListDomainRecords(ctx context.Context, domain string, opts PageOptions) ([]godo.DomainRecord, error)
+	GetDomainRecord(ctx context.Context, domain string, recordID int) (*godo.DomainRecord, error)
+	CreateDomainRecord(ctx context.Context, domain string, req *godo.DomainRecordEditRequest) (*godo.DomainRecord, error)
+	UpdateDomainRecord(ctx context.Context, domain string, recordID int, req *godo.DomainRecordEditRequest) (*godo.DomainRecord, error)
+	DeleteDomainRecord(ctx context.Context, domain string, recordID int) error
+}
+
+type BillingAPI interface {
+	GetBillingInfo(ctx context.Context) (*godo.Balance, error)
+}
+
+var (
+	_ DropletAPI    = (*Client)(nil)
+	_ VPCAPI        = (*Client)(nil)
+	_ KubernetesAPI = (*Client)(nil)
+	_ DatabaseAPI   = (*Client)(nil)
+	_ DomainAPI     = (*Client)(nil)
+	_ BillingAPI    = (*Client)(nil)
+)
+
+// The New*API functions build the real client behind each interface. They
+// are handed to the command constructors, which call them once the
+// configuration has been loaded.
+
+func NewDropletAPI(cfg *config.Config) DropletAPI {
+	return NewClient(cfg)
+}
+
+func NewVPCAPI(cfg *config.Config) VPCAPI {
+	return NewClient(cfg)
+}
+
+func NewKubernetesAPI(cfg *config.Config) KubernetesAPI {
+	return NewClient(cfg)
+}
+
+func NewDatabaseAPI(cfg *config.Config) DatabaseAPI {
+	return NewClient(cfg)
+}
+
+func NewDomainAPI(cfg *config.Config) DomainAPI {
+	return NewClient(cfg)
+}
+
+func NewBillingAPI(cfg *config.Config) BillingAPI {
+	return NewClient(cfg)
+}
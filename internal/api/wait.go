@@ -0,0 +1,239 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/digitalocean/godo"
+	"github.com/felipepimentel/digitalocean-go/internal/logging"
+)
+
+// ErrNotFound is returned by implementations of the service interfaces for
+// resources that do not exist. The API itself answers with a 404; use
+// IsNotFound to check for either.
+var ErrNotFound = errors.New("not found")
+
+// IsNotFound reports whether err means the resource does not exist.
+func IsNotFound(err error) bool {
+	var apiErr *godo.ErrorResponse
+	if errors.As(err, &apiErr) && apiErr.Response != nil {
+		return apiErr.Response.StatusCode == http.StatusNotFound
+	}
+	return errors.Is(err, ErrNotFound)
+}
+
+// WaitOptions control how Wait polls. The delay between polls starts at
+// Interval and grows by half after every poll, up to MaxInterval.
+type WaitOptions struct {
+	// Timeout bounds the whole wait. Zero leaves it to the context.
+	Timeout     time.Duration
+	Interval    time.Duration
+	MaxInterval time.Duration
+	// Progress, if set, is called with the status after every poll.
+	Progress func(status string)
+}
+
+var DefaultWaitOptions = WaitOptions{
+	Timeout:     10 * time.Minute,
+	Interval:    2 * time.Second,
+	MaxInterval: 15 * time.Second,
+}
+
+// Check polls a resource once. It reports whether the wait is over and the
+// status to show while it is not. An error ends the wait.
+type Check func(ctx context.Context) (done bool, status string, err error)
+
+// Wait polls check until it is done, fails or the timeout passes.
+func Wait(ctx context.Context, opts WaitOptions, check Check) error {
+	if opts.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.Timeout)
+		defer cancel()
+	}
+
+	delay := opts.Interval
+	status := ""
+	for {
+		done, s, err := check(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return waitError(ctx, opts, status)
+			}
+			return err
+		}
+		if done {
+			return nil
+		}
+		if s != status {
+			logging.Debug("still waiting", "status", s)
+			status = s
+		}
+		if opts.Progress != nil {
+			opts.Progress(status)
+		}
+
+		if err := sleepContext(ctx, delay); err != nil {
+			return waitError(ctx, opts, status)
+		}
+		delay += delay / 2
+		if opts.MaxInterval > 0 && delay > opts.MaxInterval {
+			delay = opts.MaxInterval
+		}
+	}
+}
+
+func waitError(ctx context.Context, opts WaitOptions, status string) error {
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) && opts.Timeout > 0 {
+		return fmt.Errorf("timed out after %s, last status %q: %w", opts.Timeout, status, ctx.Err())
+	}
+	return ctx.Err()
+}
+
+// All combines checks into one that is done when all of them are. Checks
+// that are done are not polled again.
+func All(checks ...Check) Check {
+	done := make([]bool, len(checks))
+	return func(ctx context.Context) (bool, string, error) {
+		ready, status := 0, ""
+		for i, check := range checks {
+			if !done[i] {
+				var err error
+				if done[i], status, err = check(ctx); err != nil {
+					return false, "", err
+				}
+			}
+			if done[i] {
+				ready++
+			}
+		}
+		if len(checks) == 1 {
+			return done[0], status, nil
+		}
+		return ready == len(checks), fmt.Sprintf("%d of %d ready", ready, len(checks)), nil
+	}
+}
+
+// DropletReady waits for a droplet's create action, the one linked from the
+// create response, to complete and for the droplet to be active. Without an
+// action ID only the droplet's status is checked.
+func DropletReady(c DropletAPI, id, actionID int) Check {
+	created := actionID == 0
+	action := ActionDone(c, actionID)
+	return func(ctx context.Context) (bool, string, error) {
+		if !created {
+			done, status, err := action(ctx)
+			if err != nil {
+				return false, "", fmt.Errorf("droplet %d: %w", id, err)
+			}
+			if !done {
+				return false, status, nil
+			}
+			created = true
+		}
+
+		droplet, err := c.GetDroplet(ctx, id)
+		if err != nil {
+			return false, "", err
+		}
+		return droplet.Status == "active", droplet.Status, nil
+	}
+}
+
+// ActionDone waits for a single action to complete.
+func ActionDone(c DropletAPI, id int) Check {
+	return func(ctx context.Context) (bool, string, error) {
+		action, err := c.GetAction(ctx, id)
+		if err != nil {
+			return false, "", err
+		}
+		switch action.Status {
+		case godo.ActionCompleted:
+			return true, action.Status, nil
+		case "errored":
+			return false, "", fmt.Errorf("%s action %d errored", action.Type, id)
+		}
+		return false, action.Type + " " + action.Status, nil
+	}
+}
+
+func DropletDeleted(c DropletAPI, id int) Check {
+	return deleted(func(ctx context.Context) (string, error) {
+		droplet, err := c.GetDroplet(ctx, id)
+		if err != nil {
+			return "", err
+		}
+		return droplet.Status, nil
+	})
+}
+
+// ClusterRunning waits for a Kubernetes cluster to be running. A cluster
+// in the error or invalid state ends the wait with an error.
+func ClusterRunning(c KubernetesAPI, id string) Check {
+	return func(ctx context.Context) (bool, string, error) {
+		cluster, err := c.GetKubernetesCluster(ctx, id)
+		if err != nil {
+			return false, "", err
+		}
+		if cluster.Status == nil {
+			return false, "unknown", nil
+		}
+		switch cluster.Status.State {
+		case godo.KubernetesClusterStatusRunning:
+			return true, string(cluster.Status.State), nil
+		case godo.KubernetesClusterStatusError, godo.KubernetesClusterStatusInvalid:
+			return false, "", fmt.Errorf("kubernetes cluster %s is in state %s: %s", id, cluster.Status.State, cluster.Status.Message)
+		}
+		return false, string(cluster.Status.State), nil
+	}
+}
+
+func ClusterDeleted(c KubernetesAPI, id string) Check {
+	return deleted(func(ctx context.Context) (string, error) {
+		cluster, err := c.GetKubernetesCluster(ctx, id)
+		if err != nil || cluster.Status == nil {
+			return "", err
+		}
+		return string(cluster.Status.State), nil
+	})
+}
+
+func DatabaseOnline(c DatabaseAPI, id string) Check {
+	return func(ctx context.Context) (bool, string, error) {
+		database, err := c.GetDatabase(ctx, id)
+		if err != nil {
+			return false, "", err
+		}
+		return database.Status == "online", database.Status, nil
+	}
+}
+
+func DatabaseDeleted(c DatabaseAPI, id string) Check {
+	return deleted(func(ctx context.Context) (string, error) {
+		database, err := c.GetDatabase(ctx, id)
+		if err != nil {
+			return "", err
+		}
+		return database.Status, nil
+	})
+}
+
+// deleted turns a status lookup into a Check that is done once the
+// resource is gone.
+func deleted(status func(ctx context.Context) (string, error)) Check {
+	return func(ctx context.Context) (bool, string, error) {
+		s, err := status(ctx)
+		if IsNotFound(err) {
+			return true, "deleted", nil
+		}
+		if err != nil {
+			return false, "", err
+		}
+		if s == "" {
+			s = "deleting"
+		}
+		return false, s, nil
+	}
+}
@@ -0,0 +1,84 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/digitalocean/godo"
+)
+
+var fastWait = WaitOptions{Timeout: time.Second, Interval: time.Millisecond, MaxInterval: 2 * time.Millisecond}
+
+func TestWait(t *testing.T) {
+	polls := 0
+	var statuses []string
+	opts := fastWait
+	opts.Progress = func(status string) { statuses = append(statuses, status) }
+
+	err := Wait(context.Background(), opts, func(ctx context.Context) (bool, string, error) {
+		polls++
+		return polls == 3, fmt.Sprintf("poll %d", polls), nil
+	})
+	if err != nil {
+		t.Fatalf("Wait: %v", err)
+	}
+	if !reflect.DeepEqual(statuses, []string{"poll 1", "poll 2"}) {
+		t.Errorf("Unexpected progress %v", statuses)
+	}
+}
+
+func TestWaitFailures(t *testing.T) {
+	boom := errors.New("boom")
+	err := Wait(context.Background(), fastWait, func(ctx context.Context) (bool, string, error) {
+		return false, "", boom
+	})
+	if !errors.Is(err, boom) {
+		t.Errorf("Expected the check error, got %v", err)
+	}
+
+	opts := fastWait
+	opts.Timeout = 20 * time.Millisecond
+	err = Wait(context.Background(), opts, func(ctx context.Context) (bool, string, error) {
+		return false, "new", nil
+	})
+	if !errors.Is(err, context.DeadlineExceeded) || !strings.Contains(err.Error(), `last status "new"`) {
+		t.Errorf("Expected a timeout with the last status, got %v", err)
+	}
+}
+
+func TestAll(t *testing.T) {
+	polls := map[string]int{}
+	check := func(name string, readyAfter int) Check {
+		return func(ctx context.Context) (bool, string, error) {
+			polls[name]++
+			return polls[name] >= readyAfter, "", nil
+		}
+	}
+
+	all := All(check("a", 1), check("b", 3))
+	for i, want := range []string{"1 of 2 ready", "1 of 2 ready", "2 of 2 ready"} {
+		done, status, err := all(context.Background())
+		if err != nil || status != want || done != (i == 2) {
+			t.Errorf("Poll %d: got %v, %q, %v", i+1, done, status, err)
+		}
+	}
+	if polls["a"] != 1 {
+		t.Errorf("Expected a finished check not to be polled again, polled %d times", polls["a"])
+	}
+}
+
+func TestIsNotFound(t *testing.T) {
+	notFound := &godo.ErrorResponse{Response: &http.Response{StatusCode: http.StatusNotFound}}
+	if !IsNotFound(notFound) || !IsNotFound(fmt.Errorf("droplet 1: %w", ErrNotFound)) {
+		t.Error("Expected 404 responses and ErrNotFound to be not found")
+	}
+	if IsNotFound(&godo.ErrorResponse{Response: &http.Response{StatusCode: http.StatusForbidden}}) || IsNotFound(errors.New("boom")) {
+		t.Error("Expected other errors not to be not found")
+	}
+}
@@ -0,0 +1,33 @@
+// Package atomicfile writes files readable by their owner only, replacing
+// them atomically so that readers never see a partial file.
+package atomicfile
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// TempPrefix starts the names of the temporary files Write creates next to
+// the file it writes. Ones left behind by a crash can be recognised by it.
+const TempPrefix = ".tmp-"
+
+// Write replaces the file at path with data, with 0600 permissions. The data
+// goes to a temporary file in the same directory first, which is renamed
+// over path, so path holds either its old or its new contents.
+func Write(path string, data []byte) error {
+	// CreateTemp creates the file with 0600 permissions.
+	tmp, err := os.CreateTemp(filepath.Dir(path), TempPrefix+"*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,173 @@
+package auth
+
+import (
+	"bufio"
+	"fmt"
+	"strings"
+
+	"github.com/felipepimentel/digitalocean-go/internal/config"
+	"github.com/felipepimentel/digitalocean-go/internal/output"
+	"github.com/spf13/cobra"
+)
+
+func Cmd(cfg *config.Config) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "auth",
+		Short: "Manage named contexts for DigitalOcean accounts",
+		// Contexts are managed before any token is configured.
+		Annotations: map[string]string{config.NoTokenAnnotation: "true"},
+	}
+
+	cmd.AddCommand(
+		initCmd(),
+		listCmd(cfg),
+		switchCmd(),
+	)
+
+	return cmd
+}
+
+func initCmd() *cobra.Command {
+	var ctx config.Context
+	var use bool
+
+	cmd := &cobra.Command{
+		Use:   "init [context_name]",
+		Short: "Create or update a context",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if ctx.Token == "" {
+				fmt.Fprint(cmd.ErrOrStderr(), "DigitalOcean API token: ")
+				token, err := bufio.NewReader(cmd.InOrStdin()).ReadString('\n')
+				if err != nil && token == "" {
+					return fmt.Errorf("failed to read token: %w", err)
+				}
+				ctx.Token = strings.TrimSpace(token)
+			}
+			if ctx.Token == "" {
+				return fmt.Errorf("a token is required")
+			}
+			if ctx.Output != "" {
+				if _, err := output.ParseFormat(ctx.Output); err != nil {
+					return err
+				}
+			}
+
+			path, err := config.FilePath()
+			if err != nil {
+				return err
+			}
+			file, err := config.ReadFile(path)
+			if err != nil {
+				return err
+			}
+
+			if ctx.TokenStore != "" {
+				store, err := config.NewSecretStore(ctx.TokenStore, config.TerminalPassphrase)
+				if err != nil {
+					return err
+				}
+				if err := store.Set(args[0], ctx.Token); err != nil {
+					return fmt.Errorf("failed to save token in the %s token store: %w", ctx.TokenStore, err)
+				}
+				ctx.Token = ""
+			}
+
+			file.Contexts[args[0]] = ctx
+			if use || file.CurrentContext == "" {
+				file.CurrentContext = args[0]
+			}
+			if err := file.Write(path); err != nil {
+				return fmt.Errorf("failed to write %s: %w", path, err)
+			}
+
+			fmt.Fprintf(cmd.OutOrStdout(), "Context %s saved to %s\n", args[0], path)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&ctx.Token, "token", "", "API token (prompted for when omitted)")
+	cmd.Flags().StringVar(&ctx.TokenStore, "token-store", "", "Keep the token in keyring, file (encrypted) or helper:NAME instead of the config file")
+	cmd.Flags().StringVar(&ctx.Region, "region", "", "Default region")
+	cmd.Flags().StringVar(&ctx.Size, "size", "", "Default droplet size")
+	cmd.Flags().StringVar(&ctx.Output, "default-output", "", "Default output format")
+	cmd.Flags().StringVar(&ctx.APIURL, "api-url", "", "API endpoint (defaults to the public DigitalOcean API)")
+	cmd.Flags().BoolVar(&use, "use", false, "Make this the current context")
+
+	return cmd
+}
+
+// contextInfo is the listing shown by auth list. Tokens are never printed.
+type contextInfo struct {
+	Current bool   `json:"current"`
+	Name    string `json:"name"`
+	Region  string `json:"region,omitempty"`
+	Size    string `json:"size,omitempty"`
+	Output  string `json:"output,omitempty"`
+	APIURL  string `json:"api_url,omitempty"`
+
+	TokenStore string `json:"token_store,omitempty"`
+}
+
+func listCmd(cfg *config.Config) *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List all contexts",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			path, err := config.FilePath()
+			if err != nil {
+				return err
+			}
+			file, err := config.ReadFile(path)
+			if err != nil {
+				return err
+			}
+
+			contexts := make([]contextInfo, 0, len(file.Contexts))
+			for _, name := range file.ContextNames() {
+				ctx := file.Contexts[name]
+				contexts = append(contexts, contextInfo{
+					Current: name == file.CurrentContext,
+					Name:    name,
+					Region:  ctx.Region,
+					Size:    ctx.Size,
+					Output:  ctx.Output,
+					APIURL:  ctx.APIURL,
+
+					TokenStore: ctx.TokenStore,
+				})
+			}
+
+			return output.Fprint(cmd.OutOrStdout(), contexts, output.FromConfig(cfg))
+		},
+	}
+}
+
+func switchCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "switch [context_name]",
+		Short: "Change the current context",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			path, err := config.FilePath()
+			if err != nil {
+				return err
+			}
+			file, err := config.ReadFile(path)
+			if err != nil {
+				return err
+			}
+
+			if _, err := file.Context(args[0]); err != nil {
+				return err
+			}
+			file.CurrentContext = args[0]
+			if err := file.Write(path); err != nil {
+				return fmt.Errorf("failed to write %s: %w", path, err)
+			}
+
+			fmt.Fprintf(cmd.OutOrStdout(), "Switched to context %s\n", args[0])
+			return nil
+		},
+	}
+}
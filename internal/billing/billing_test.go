@@ -0,0 +1,25 @@
+package billing
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/felipepimentel/digitalocean-go/internal/api"
+	"github.com/felipepimentel/digitalocean-go/internal/api/apitest"
+	"github.com/felipepimentel/digitalocean-go/internal/config"
+)
+
+// These tests replay the cassettes in testdata. Re-record them with
+// DO_RECORD=1 go test ./internal/billing.
+
+func TestBillingReplay(t *testing.T) {
+	out, err := apitest.Run(Cmd(&config.Config{Output: "table"}, apitest.Replay[api.BillingAPI](t, "balance")))
+	if err != nil {
+		t.Fatalf("billing: %v", err)
+	}
+	for _, want := range []string{"23.44", "12.23", "11.21"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected %q in the output:\n%s", want, out)
+		}
+	}
+}
@@ -1,64 +1,458 @@
 package cache
 
 import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
+	"io"
+	"io/fs"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/felipepimentel/digitalocean-go/internal/atomicfile"
 	"github.com/felipepimentel/digitalocean-go/internal/logging"
 )
 
+const (
+	// DefaultMaxBytes is the default size budget of a cache.
+	DefaultMaxBytes = 32 << 20
+	// DefaultSweepInterval is how often expired entries are swept by default.
+	DefaultSweepInterval = time.Hour
+)
+
+// Files below Cache.Path. Entries are stored in entriesDir under the SHA-256
+// of their key, so that any key maps to a single safe file name.
+const (
+	entriesDir = "entries"
+	lockFile   = "lock"
+	sweptFile  = "swept"
+	tempPrefix = atomicfile.TempPrefix
+)
+
+// Cache stores JSON values on disk. It is safe for concurrent use by
+// goroutines and by processes sharing Path: readers take a shared lock,
+// writers an exclusive one, and entries are replaced by renaming a complete
+// file into place.
 type Cache struct {
 	Path string
+	// MaxBytes bounds the total size of the entries. Past it the least
+	// recently used entries are evicted. Zero disables the limit.
+	MaxBytes int64
+	// SweepInterval is how often Set removes expired entries in the
+	// background. Zero disables sweeping.
+	SweepInterval time.Duration
+
+	now      func() time.Time
+	sweeping atomic.Bool
+}
+
+// sweeps tracks the background sweeps of every Cache, so that Wait can
+// hold the process until they finish.
+var sweeps sync.WaitGroup
+
+// header is the first line of an entry file, followed by the data. It lets
+// Invalidate and Entries find keys without reading whole entries.
+type header struct {
+	Key       string
+	ExpiresAt time.Time
 }
 
-type CacheEntry struct {
-	Data      interface{}
+// EntryInfo describes a stored entry, as returned by Entries.
+type EntryInfo struct {
+	Key       string
+	Size      int64
 	ExpiresAt time.Time
+	LastUsed  time.Time
 }
 
 func New(path string) *Cache {
-	return &Cache{Path: path}
+	return &Cache{
+		Path:          path,
+		MaxBytes:      DefaultMaxBytes,
+		SweepInterval: DefaultSweepInterval,
+	}
+}
+
+// Key joins the parts of a key, e.g. Key("work", "domain-records",
+// "example.com"). Keys are hierarchical so that Invalidate can drop a
+// resource together with everything below it.
+func Key(parts ...string) string {
+	return strings.Join(parts, "/")
 }
 
 func (c *Cache) Set(key string, data interface{}, expiration time.Duration) error {
-	entry := CacheEntry{
-		Data:      data,
-		ExpiresAt: time.Now().Add(expiration),
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	head, err := json.Marshal(header{Key: key, ExpiresAt: c.clock().Add(expiration)})
+	if err != nil {
+		return err
 	}
 
-	bytes, err := json.Marshal(entry)
+	unlock, err := c.lock(true)
 	if err != nil {
 		return err
 	}
+	defer unlock()
 
-	filename := filepath.Join(c.Path, key)
-	return os.WriteFile(filename, bytes, 0644)
+	if err := c.write(key, append(append(head, '\n'), raw...)); err != nil {
+		return err
+	}
+	if err := c.evict(); err != nil {
+		return err
+	}
+	c.startSweep()
+	return nil
 }
 
 func (c *Cache) Get(key string, result interface{}) bool {
-	filename := filepath.Join(c.Path, key)
-	bytes, err := os.ReadFile(filename)
+	unlock, err := c.lock(false)
 	if err != nil {
+		logging.Warn("failed to lock cache", "err", err)
 		return false
 	}
+	defer unlock()
 
-	var entry CacheEntry
-	if err := json.Unmarshal(bytes, &entry); err != nil {
-		logging.ErrorLogger.Printf("Failed to unmarshal cache entry: %v", err)
+	filename := c.filename(key)
+	f, err := os.Open(filename)
+	if err != nil {
 		return false
 	}
+	defer f.Close()
 
-	if time.Now().After(entry.ExpiresAt) {
-		os.Remove(filename)
+	r := bufio.NewReader(f)
+	head, err := readHeader(r)
+	if err != nil {
+		logging.Warn("failed to read cache entry", "key", key, "err", err)
+		return false
+	}
+	// Expired entries are left to the sweeper, which holds the exclusive
+	// lock needed to remove them.
+	if head.Key != key || c.clock().After(head.ExpiresAt) {
 		return false
 	}
 
-	if err := json.Unmarshal(bytes, result); err != nil {
-		logging.ErrorLogger.Printf("Failed to unmarshal cached data: %v", err)
+	if err := json.NewDecoder(r).Decode(result); err != nil {
+		logging.Warn("failed to unmarshal cached data", "key", key, "err", err)
 		return false
 	}
 
+	// The modification time records the last use for LRU eviction.
+	now := c.clock()
+	os.Chtimes(filename, now, now)
 	return true
-}
\ No newline at end of file
+}
+
+// Invalidate removes the entry stored under key and every entry below it.
+func (c *Cache) Invalidate(key string) error {
+	unlock, err := c.lock(true)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	return c.scan(func(path string, head header, _ fs.FileInfo) error {
+		if head.Key == key || strings.HasPrefix(head.Key, key+"/") {
+			return remove(path)
+		}
+		return nil
+	})
+}
+
+// Clear removes every entry.
+func (c *Cache) Clear() error {
+	unlock, err := c.lock(true)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	if err := os.RemoveAll(filepath.Join(c.Path, entriesDir)); err != nil {
+		return err
+	}
+	return remove(filepath.Join(c.Path, sweptFile))
+}
+
+// Entries lists the stored entries, expired ones included.
+func (c *Cache) Entries() ([]EntryInfo, error) {
+	if _, err := os.Stat(c.Path); errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	unlock, err := c.lock(false)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	var entries []EntryInfo
+	err = c.scan(func(_ string, head header, info fs.FileInfo) error {
+		entries = append(entries, EntryInfo{
+			Key:       head.Key,
+			Size:      info.Size(),
+			ExpiresAt: head.ExpiresAt,
+			LastUsed:  info.ModTime(),
+		})
+		return nil
+	})
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Key < entries[j].Key })
+	return entries, err
+}
+
+// Sweep removes expired entries, and temporary files left behind by
+// writers that did not finish.
+func (c *Cache) Sweep() error {
+	unlock, err := c.lock(true)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	now := c.clock()
+	dir := filepath.Join(c.Path, entriesDir)
+	files, err := os.ReadDir(dir)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	for _, file := range files {
+		if !strings.HasPrefix(file.Name(), tempPrefix) {
+			continue
+		}
+		if info, err := file.Info(); err == nil && now.Sub(info.ModTime()) > time.Hour {
+			remove(filepath.Join(dir, file.Name()))
+		}
+	}
+
+	err = c.scan(func(path string, head header, _ fs.FileInfo) error {
+		if now.After(head.ExpiresAt) {
+			return remove(path)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return touch(filepath.Join(c.Path, sweptFile), now)
+}
+
+// Wait blocks until the background sweeps started by Set have finished.
+// Call it before the process exits, which would otherwise cut them short.
+func Wait() {
+	sweeps.Wait()
+}
+
+// startSweep starts a background sweep when the last one is older than
+// SweepInterval. A new cache only records the time, as it has nothing to
+// sweep yet. It is called with the exclusive lock held, which the sweep
+// waits for.
+func (c *Cache) startSweep() {
+	if c.SweepInterval <= 0 {
+		return
+	}
+	swept := filepath.Join(c.Path, sweptFile)
+	info, err := os.Stat(swept)
+	if errors.Is(err, fs.ErrNotExist) {
+		touch(swept, c.clock())
+		return
+	}
+	if err != nil || c.clock().Sub(info.ModTime()) < c.SweepInterval {
+		return
+	}
+	if !c.sweeping.CompareAndSwap(false, true) {
+		return
+	}
+
+	sweeps.Add(1)
+	go func() {
+		defer sweeps.Done()
+		defer c.sweeping.Store(false)
+		if err := c.Sweep(); err != nil {
+			logging.Warn("failed to sweep cache", "err", err)
+		}
+	}()
+}
+
+// write replaces the entry stored under key with contents.
+func (c *Cache) write(key string, contents []byte) error {
+	dir := filepath.Join(c.Path, entriesDir)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+	filename := c.filename(key)
+	if err := atomicfile.Write(filename, contents); err != nil {
+		return err
+	}
+	return touch(filename, c.clock())
+}
+
+// evict removes the least recently used entries until the cache fits in
+// MaxBytes. It is called with the exclusive lock held.
+func (c *Cache) evict() error {
+	if c.MaxBytes <= 0 {
+		return nil
+	}
+	dir := filepath.Join(c.Path, entriesDir)
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+
+	var infos []fs.FileInfo
+	var total int64
+	for _, file := range files {
+		if strings.HasPrefix(file.Name(), tempPrefix) {
+			continue
+		}
+		info, err := file.Info()
+		if err != nil {
+			continue
+		}
+		infos = append(infos, info)
+		total += info.Size()
+	}
+	if total <= c.MaxBytes {
+		return nil
+	}
+
+	sort.Slice(infos, func(i, j int) bool { return infos[i].ModTime().Before(infos[j].ModTime()) })
+	for _, info := range infos {
+		if total <= c.MaxBytes {
+			break
+		}
+		if err := remove(filepath.Join(dir, info.Name())); err != nil {
+			return err
+		}
+		total -= info.Size()
+	}
+	return nil
+}
+
+// scan calls fn with the header of every entry. Unreadable entries are
+// skipped.
+func (c *Cache) scan(fn func(path string, head header, info fs.FileInfo) error) error {
+	dir := filepath.Join(c.Path, entriesDir)
+	files, err := os.ReadDir(dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	for _, file := range files {
+		if strings.HasPrefix(file.Name(), tempPrefix) {
+			continue
+		}
+		path := filepath.Join(dir, file.Name())
+		head, info, err := statEntry(path)
+		if err != nil {
+			continue
+		}
+		if err := fn(path, head, info); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// lock takes the cache's file lock, creating the cache directory if needed.
+func (c *Cache) lock(exclusive bool) (func(), error) {
+	if err := os.MkdirAll(c.Path, 0700); err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(filepath.Join(c.Path, lockFile), os.O_CREATE|os.O_RDWR, 0600)
+	if err != nil {
+		return nil, err
+	}
+	if err := lockFileHandle(f, exclusive); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return func() {
+		unlockFileHandle(f)
+		f.Close()
+	}, nil
+}
+
+func (c *Cache) clock() time.Time {
+	if c.now != nil {
+		return c.now()
+	}
+	return time.Now()
+}
+
+func (c *Cache) filename(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(c.Path, entriesDir, hex.EncodeToString(sum[:]))
+}
+
+func statEntry(path string) (header, fs.FileInfo, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return header{}, nil, err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return header{}, nil, err
+	}
+	head, err := readHeader(bufio.NewReader(f))
+	return head, info, err
+}
+
+func readHeader(r *bufio.Reader) (header, error) {
+	line, err := r.ReadBytes('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return header{}, err
+	}
+	var head header
+	err = json.Unmarshal(line, &head)
+	return head, err
+}
+
+func touch(path string, t time.Time) error {
+	if err := os.Chtimes(path, t, t); !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	f.Close()
+	return os.Chtimes(path, t, t)
+}
+
+// remove removes a file, ignoring files that are already gone.
+func remove(path string) error {
+	if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// Fetch returns the value cached under key, or calls load and caches what
+// it returns for ttl. With refresh the cached value is ignored and
+// replaced. Failing to write the cache does not fail Fetch.
+func Fetch[T any](c *Cache, key string, ttl time.Duration, refresh bool, load func() (T, error)) (T, error) {
+	var value T
+	if !refresh && c.Get(key, &value) {
+		return value, nil
+	}
+
+	value, err := load()
+	if err != nil {
+		return value, err
+	}
+	if err := c.Set(key, value, ttl); err != nil {
+		logging.Warn("failed to write cache entry", "key", key, "err", err)
+	}
+	return value, nil
+}
@@ -0,0 +1,225 @@
+package cache
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type item struct {
+	ID   int
+	Name string
+}
+
+func TestSetGet(t *testing.T) {
+	c := New(t.TempDir())
+
+	if err := c.Set(Key("work", "droplets"), []item{{1, "web-1"}}, time.Minute); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+
+	var items []item
+	if !c.Get(Key("work", "droplets"), &items) {
+		t.Fatal("Expected a cache hit")
+	}
+	if len(items) != 1 || items[0].Name != "web-1" {
+		t.Errorf("Unexpected cached items %+v", items)
+	}
+
+	if err := c.Set("expired", []item{}, -time.Second); err != nil {
+		t.Fatal(err)
+	}
+	if c.Get("expired", &items) {
+		t.Error("Expected expired entries to miss")
+	}
+}
+
+func TestInvalidate(t *testing.T) {
+	c := New(t.TempDir())
+	c.Set(Key("work", "domain-records", "example.com"), []item{}, time.Minute)
+	c.Set(Key("work", "domain-records", "example.org"), []item{}, time.Minute)
+	c.Set(Key("work", "droplets"), []item{}, time.Minute)
+
+	if err := c.Invalidate(Key("work", "domain-records")); err != nil {
+		t.Fatalf("Invalidate: %v", err)
+	}
+
+	entries, err := c.Entries()
+	if err != nil {
+		t.Fatalf("Entries: %v", err)
+	}
+	if len(entries) != 1 || entries[0].Key != "work/droplets" {
+		t.Errorf("Expected only work/droplets to be left, got %+v", entries)
+	}
+}
+
+func TestClear(t *testing.T) {
+	dir := t.TempDir()
+	c := New(dir)
+	c.Set(Key("work", "droplets"), []item{}, time.Minute)
+	// The cache directory may be shared with files that are not the cache's.
+	other := filepath.Join(dir, "other")
+	if err := os.WriteFile(other, nil, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := c.Clear(); err != nil {
+		t.Fatalf("Clear: %v", err)
+	}
+
+	if entries, _ := c.Entries(); len(entries) != 0 {
+		t.Errorf("Expected no entries after Clear, got %+v", entries)
+	}
+	if _, err := os.Stat(other); err != nil {
+		t.Errorf("Expected Clear to leave other files alone: %v", err)
+	}
+}
+
+func TestFetch(t *testing.T) {
+	c := New(t.TempDir())
+	calls := 0
+	load := func() ([]item, error) {
+		calls++
+		return []item{{calls, "web"}}, nil
+	}
+
+	for i := 0; i < 2; i++ {
+		items, err := Fetch(c, "droplets", time.Minute, false, load)
+		if err != nil || items[0].ID != 1 {
+			t.Fatalf("Fetch returned %+v, %v", items, err)
+		}
+	}
+	if calls != 1 {
+		t.Errorf("Expected one load, got %d", calls)
+	}
+
+	items, _ := Fetch(c, "droplets", time.Minute, true, load)
+	if calls != 2 || items[0].ID != 2 {
+		t.Errorf("Expected refresh to reload, got %+v after %d loads", items, calls)
+	}
+
+	if _, err := Fetch(c, "failing", time.Minute, false, func() ([]item, error) { return nil, errors.New("boom") }); err == nil {
+		t.Error("Expected the load error")
+	}
+	if c.Get("failing", &items) {
+		t.Error("Expected failed loads not to be cached")
+	}
+}
+
+func TestKeysAndPermissions(t *testing.T) {
+	dir := t.TempDir()
+	c := New(dir)
+	key := Key("work", "domain-records", "../../example.com")
+
+	if err := c.Set(key, []item{{1, "www"}}, time.Minute); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+
+	files, err := os.ReadDir(filepath.Join(dir, entriesDir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 1 {
+		t.Fatalf("Expected a single entry file, got %d", len(files))
+	}
+	info, err := files[0].Info()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if runtime.GOOS != "windows" && info.Mode().Perm() != 0600 {
+		t.Errorf("Expected 0600 permissions, got %v", info.Mode().Perm())
+	}
+
+	entries, _ := c.Entries()
+	if len(entries) != 1 || entries[0].Key != key {
+		t.Errorf("Expected the original key back, got %+v", entries)
+	}
+}
+
+func TestEviction(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	c := New(t.TempDir())
+	c.now = func() time.Time { return now }
+
+	for _, key := range []string{"a", "b", "c"} {
+		if err := c.Set(key, strings.Repeat("x", 100), time.Hour); err != nil {
+			t.Fatal(err)
+		}
+		now = now.Add(time.Second)
+	}
+	var value string
+	c.Get("a", &value)
+	now = now.Add(time.Second)
+
+	entries, _ := c.Entries()
+	c.MaxBytes = entries[0].Size * 3
+	if err := c.Set("d", strings.Repeat("x", 100), time.Hour); err != nil {
+		t.Fatal(err)
+	}
+
+	for key, want := range map[string]bool{"a": true, "b": false, "c": true, "d": true} {
+		if got := c.Get(key, &value); got != want {
+			t.Errorf("Expected Get(%s) to be %v after eviction, got %v", key, want, got)
+		}
+	}
+}
+
+func TestSweep(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	c := New(t.TempDir())
+	c.now = func() time.Time { return now }
+	c.SweepInterval = time.Hour
+
+	c.Set("short", 1, time.Minute)
+	c.Set("long", 1, 2*time.Hour)
+
+	now = now.Add(90 * time.Minute)
+	// The next write finds the last sweep an interval old and sweeps.
+	c.Set("new", 1, time.Hour)
+	Wait()
+
+	entries, err := c.Entries()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var keys []string
+	for _, entry := range entries {
+		keys = append(keys, entry.Key)
+	}
+	if strings.Join(keys, ",") != "long,new" {
+		t.Errorf("Expected the expired entry to be swept, got %v", keys)
+	}
+}
+
+func TestConcurrentWriters(t *testing.T) {
+	dir := t.TempDir()
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			// Separate Cache values stand in for separate processes.
+			c := New(dir)
+			for j := 0; j < 20; j++ {
+				if err := c.Set("droplets", []item{{i, strings.Repeat("x", j*100)}}, time.Minute); err != nil {
+					t.Errorf("Set: %v", err)
+				}
+				var items []item
+				if !c.Get("droplets", &items) || len(items) != 1 {
+					t.Errorf("Expected a complete entry, got %+v", items)
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	files, _ := os.ReadDir(filepath.Join(dir, entriesDir))
+	if len(files) != 1 {
+		t.Errorf("Expected one entry and no temporary files, got %d files", len(files))
+	}
+}
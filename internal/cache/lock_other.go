@@ -0,0 +1,15 @@
+//go:build !unix && !windows
+
+package cache
+
+import "os"
+
+// Platforms without file locking rely on atomic renames alone.
+
+func lockFileHandle(f *os.File, exclusive bool) error {
+	return nil
+}
+
+func unlockFileHandle(f *os.File) error {
+	return nil
+}
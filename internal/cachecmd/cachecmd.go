@@ -0,0 +1,148 @@
+package cachecmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/felipepimentel/digitalocean-go/internal/api"
+	"github.com/felipepimentel/digitalocean-go/internal/cache"
+	"github.com/felipepimentel/digitalocean-go/internal/config"
+	"github.com/felipepimentel/digitalocean-go/internal/output"
+	"github.com/spf13/cobra"
+)
+
+func Cmd(cfg *config.Config) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:         "cache",
+		Short:       "Inspect and clear cached list results",
+		Annotations: map[string]string{config.NoTokenAnnotation: "true"},
+	}
+
+	cmd.AddCommand(
+		clearCmd(cfg),
+		statsCmd(cfg),
+	)
+
+	return cmd
+}
+
+func open(cfg *config.Config) (*cache.Cache, error) {
+	if cfg.CacheDir == "" {
+		return nil, fmt.Errorf("caching is disabled: no cache directory is configured (set DO_CACHE_DIR)")
+	}
+	return cache.New(cfg.CacheDir), nil
+}
+
+func clearCmd(cfg *config.Config) *cobra.Command {
+	var all bool
+
+	cmd := &cobra.Command{
+		Use:       "clear [resource]",
+		Short:     "Clear cached lists of the current context, or of one resource",
+		Args:      cobra.MaximumNArgs(1),
+		ValidArgs: api.CachedResources,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, err := open(cfg)
+			if err != nil {
+				return err
+			}
+
+			if all {
+				if len(args) > 0 {
+					return fmt.Errorf("--all clears every resource; drop the %s argument", args[0])
+				}
+				if err := c.Clear(); err != nil {
+					return err
+				}
+				fmt.Fprintln(cmd.OutOrStdout(), "Cache cleared")
+				return nil
+			}
+
+			profile := api.CacheProfile(cfg)
+			key := cache.Key(profile)
+			if len(args) > 0 {
+				if !valid(args[0]) {
+					return fmt.Errorf("unknown resource %q: expected one of %s", args[0], strings.Join(api.CachedResources, ", "))
+				}
+				key = cache.Key(profile, args[0])
+			}
+			if err := c.Invalidate(key); err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Cache cleared for %s\n", key)
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&all, "all", false, "Clear the cache of every context")
+
+	return cmd
+}
+
+func valid(resource string) bool {
+	for _, r := range api.CachedResources {
+		if r == resource {
+			return true
+		}
+	}
+	return false
+}
+
+// stat summarises the entries of one resource in one context.
+type stat struct {
+	Context  string `json:"context"`
+	Resource string `json:"resource"`
+	Entries  int    `json:"entries"`
+	Expired  int    `json:"expired"`
+	Bytes    int64  `json:"bytes"`
+}
+
+func statsCmd(cfg *config.Config) *cobra.Command {
+	return &cobra.Command{
+		Use:   "stats",
+		Short: "Show the number and size of cached entries",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, err := open(cfg)
+			if err != nil {
+				return err
+			}
+			entries, err := c.Entries()
+			if err != nil {
+				return err
+			}
+
+			now := time.Now()
+			byResource := map[string]*stat{}
+			for _, entry := range entries {
+				parts := strings.SplitN(entry.Key, "/", 3)
+				s := stat{Context: parts[0]}
+				if len(parts) > 1 {
+					s.Resource = parts[1]
+				}
+				id := s.Context + "/" + s.Resource
+				if byResource[id] == nil {
+					byResource[id] = &s
+				}
+				byResource[id].Entries++
+				byResource[id].Bytes += entry.Size
+				if now.After(entry.ExpiresAt) {
+					byResource[id].Expired++
+				}
+			}
+
+			ids := make([]string, 0, len(byResource))
+			for id := range byResource {
+				ids = append(ids, id)
+			}
+			sort.Strings(ids)
+			stats := make([]stat, 0, len(ids))
+			for _, id := range ids {
+				stats = append(stats, *byResource[id])
+			}
+
+			return output.Fprint(cmd.OutOrStdout(), stats, output.FromConfig(cfg))
+		},
+	}
+}
@@ -1,21 +1,379 @@
 package config
 
 import (
-	"github.com/joho/godotenv"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"net/url"
 	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/felipepimentel/digitalocean-go/internal/logging"
+	"github.com/joho/godotenv"
+	"github.com/spf13/pflag"
 )
 
+const DefaultAPIURL = "https://api.digitalocean.com/"
+
 type Config struct {
+	// Context is the name of the selected context from the config file.
+	Context string
+
 	DOToken string
+	Region  string
+	Size    string
+	APIURL  string
+
+	// MaxRetries bounds how often a failed API request is retried and
+	// RateLimit caps requests per second (0 disables the limit).
+	MaxRetries int
+	RateLimit  float64
+
+	// Output and Columns come from the global --output and --columns flags
+	// and are read by commands when printing results. Limit, from --limit,
+	// caps the number of items list commands fetch.
+	Output  string
+	Columns []string
+	Limit   int
+
+	// Cassette, when set, is a file API traffic is recorded to or replayed
+	// from, as selected by CassetteMode ("record" or "replay").
+	Cassette     string
+	CassetteMode string
+
+	// CacheDir holds cached list results for CacheTTL; empty disables the
+	// cache. NoCache (--no-cache) bypasses it and RefreshCache (--refresh)
+	// refetches and replaces what it holds.
+	CacheDir     string
+	CacheTTL     time.Duration
+	NoCache      bool
+	RefreshCache bool
+
+	// LogLevel, LogFormat and LogFile configure diagnostics logging; an
+	// empty LogFile logs to stderr.
+	LogLevel  string
+	LogFormat string
+	LogFile   string
+
+	// DebugHTTP logs every API request and response, with their bodies
+	// when DebugHTTPBodies is set.
+	DebugHTTP       bool
+	DebugHTTPBodies bool
+
+	sources map[string]Source
+
+	// tokenStore holds the token of the selected context until it is
+	// fetched by RequireToken, so that commands not calling the API never
+	// touch the store.
+	tokenStore     SecretStore
+	tokenStoreName string
+}
+
+// Source names the layer a setting was resolved from.
+type Source string
+
+const (
+	SourceFlag    Source = "flag"
+	SourceEnv     Source = "env"
+	SourceDotEnv  Source = ".env"
+	SourceFile    Source = "config file"
+	SourceDefault Source = "default"
+)
+
+// Setting is a resolved configuration value, as shown by config view.
+type Setting struct {
+	Name   string `json:"name"`
+	Value  string `json:"value"`
+	Source Source `json:"source"`
+}
+
+// setting describes where a configuration value may come from. Settings
+// without a flag can only be set through the environment or a context.
+type setting struct {
+	name string
+	flag string
+	env  string
+}
+
+var (
+	contextSetting = setting{name: "context", flag: "context", env: "DO_CONTEXT"}
+	tokenSetting   = setting{name: "token", flag: "token", env: "DO_TOKEN"}
+	regionSetting  = setting{name: "region", env: "DO_REGION"}
+	sizeSetting    = setting{name: "size", env: "DO_SIZE"}
+	outputSetting  = setting{name: "output", flag: "output", env: "DO_OUTPUT"}
+	apiURLSetting  = setting{name: "api-url", flag: "api-url", env: "DO_API_URL"}
+	retriesSetting = setting{name: "max-retries", flag: "max-retries", env: "DO_MAX_RETRIES"}
+	rateSetting    = setting{name: "rate-limit", flag: "rate-limit", env: "DO_RATE_LIMIT"}
+
+	cacheDirSetting = setting{name: "cache-dir", env: "DO_CACHE_DIR"}
+	cacheTTLSetting = setting{name: "cache-ttl", env: "DO_CACHE_TTL"}
+
+	logLevelSetting  = setting{name: "log-level", flag: "log-level", env: "DO_LOG_LEVEL"}
+	logFormatSetting = setting{name: "log-format", flag: "log-format", env: "DO_LOG_FORMAT"}
+	logFileSetting   = setting{name: "log-file", flag: "log-file", env: "DO_LOG_FILE"}
+
+	debugHTTPSetting     = setting{name: "debug-http", flag: "debug-http", env: "DO_DEBUG"}
+	debugHTTPBodySetting = setting{name: "debug-http-body", flag: "debug-http-body", env: "DO_DEBUG_BODY"}
+)
+
+// Cassettes are a development aid, set through the environment only.
+var (
+	cassetteSetting     = setting{name: "cassette", env: "DO_CASSETTE"}
+	cassetteModeSetting = setting{name: "cassette-mode", env: "DO_CASSETTE_MODE"}
+)
+
+var settingOrder = []setting{
+	contextSetting,
+	tokenSetting,
+	regionSetting,
+	sizeSetting,
+	outputSetting,
+	apiURLSetting,
+	retriesSetting,
+	rateSetting,
+	cacheDirSetting,
+	cacheTTLSetting,
+	logLevelSetting,
+	logFormatSetting,
+	logFileSetting,
+	debugHTTPSetting,
+	debugHTTPBodySetting,
+	cassetteSetting,
+	cassetteModeSetting,
 }
 
-func Load() (*Config, error) {
-	err := godotenv.Load()
+// NoTokenAnnotation marks cobra commands, and their subcommands, that work
+// without an API token, such as those managing the configuration itself.
+const NoTokenAnnotation = "digitalocean-cli/no-token"
+
+// ErrNoToken is returned by RequireToken when no layer provided a token.
+var ErrNoToken = errors.New("no DigitalOcean API token found: pass --token, set DO_TOKEN in the environment or .env, or run `digitalocean-cli auth init`")
+
+// Load resolves every setting from, in order of precedence, command-line
+// flags, environment variables, the .env file in the working directory,
+// the selected context of the config file and built-in defaults. Neither
+// the .env file nor the config file is required. flags may be nil.
+func Load(flags *pflag.FlagSet) (*Config, error) {
+	dotenv, err := godotenv.Read()
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("failed to read .env: %w", err)
+	}
+
+	path, err := FilePath()
+	if err != nil {
+		return nil, err
+	}
+	file, err := ReadFile(path)
 	if err != nil {
 		return nil, err
 	}
 
-	return &Config{
-		DOToken: os.Getenv("DO_TOKEN"),
-	}, nil
-}
\ No newline at end of file
+	r := &resolver{flags: flags, dotenv: dotenv}
+	cfg := &Config{sources: map[string]Source{}}
+
+	cfg.Context = r.resolve(cfg, contextSetting, file.CurrentContext, "")
+	ctx, err := file.Context(cfg.Context)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg.DOToken = r.resolve(cfg, tokenSetting, ctx.Token, "")
+	if cfg.DOToken == "" && ctx.TokenStore != "" {
+		store, err := NewSecretStore(ctx.TokenStore, TerminalPassphrase)
+		if err != nil {
+			return nil, fmt.Errorf("context %q: %w", cfg.Context, err)
+		}
+		cfg.tokenStore, cfg.tokenStoreName = store, ctx.TokenStore
+		cfg.sources[tokenSetting.name] = SourceFile
+	}
+	cfg.Region = r.resolve(cfg, regionSetting, ctx.Region, "")
+	cfg.Size = r.resolve(cfg, sizeSetting, ctx.Size, "")
+	cfg.Output = r.resolve(cfg, outputSetting, ctx.Output, "table")
+	cfg.APIURL = r.resolve(cfg, apiURLSetting, ctx.APIURL, DefaultAPIURL)
+
+	retries := r.resolve(cfg, retriesSetting, "", "3")
+	if cfg.MaxRetries, err = strconv.Atoi(retries); err != nil || cfg.MaxRetries < 0 {
+		return nil, fmt.Errorf("invalid max-retries %q from %s", retries, cfg.sources[retriesSetting.name])
+	}
+	// The default stays under the API's limit of 250 requests per minute.
+	rateLimit := r.resolve(cfg, rateSetting, "", "4")
+	if cfg.RateLimit, err = strconv.ParseFloat(rateLimit, 64); err != nil || cfg.RateLimit < 0 {
+		return nil, fmt.Errorf("invalid rate-limit %q from %s", rateLimit, cfg.sources[rateSetting.name])
+	}
+
+	cfg.CacheDir = r.resolve(cfg, cacheDirSetting, "", DefaultCacheDir())
+	cacheTTL := r.resolve(cfg, cacheTTLSetting, "", "1m")
+	if cfg.CacheTTL, err = time.ParseDuration(cacheTTL); err != nil || cfg.CacheTTL < 0 {
+		return nil, fmt.Errorf("invalid cache-ttl %q from %s", cacheTTL, cfg.sources[cacheTTLSetting.name])
+	}
+
+	cfg.LogLevel = r.resolve(cfg, logLevelSetting, "", "info")
+	if _, err := logging.ParseLevel(cfg.LogLevel); err != nil {
+		return nil, fmt.Errorf("invalid log-level from %s: %w", cfg.sources[logLevelSetting.name], err)
+	}
+	cfg.LogFormat = r.resolve(cfg, logFormatSetting, "", logging.FormatText)
+	if cfg.LogFormat != logging.FormatText && cfg.LogFormat != logging.FormatJSON {
+		return nil, fmt.Errorf("invalid log-format %q from %s: expected text or json", cfg.LogFormat, cfg.sources[logFormatSetting.name])
+	}
+	cfg.LogFile = r.resolve(cfg, logFileSetting, "", "")
+
+	debugHTTP := r.resolve(cfg, debugHTTPSetting, "", "false")
+	if cfg.DebugHTTP, err = strconv.ParseBool(debugHTTP); err != nil {
+		return nil, fmt.Errorf("invalid debug-http %q from %s", debugHTTP, cfg.sources[debugHTTPSetting.name])
+	}
+	// Dumping bodies implies tracing requests.
+	debugHTTPBody := r.resolve(cfg, debugHTTPBodySetting, "", "false")
+	if cfg.DebugHTTPBodies, err = strconv.ParseBool(debugHTTPBody); err != nil {
+		return nil, fmt.Errorf("invalid debug-http-body %q from %s", debugHTTPBody, cfg.sources[debugHTTPBodySetting.name])
+	}
+	cfg.DebugHTTP = cfg.DebugHTTP || cfg.DebugHTTPBodies
+	// The request traces are logged at info, so tracing lowers a warn or
+	// error level to info rather than have it hide them.
+	if level, _ := logging.ParseLevel(cfg.LogLevel); cfg.DebugHTTP && level > slog.LevelInfo {
+		cfg.LogLevel = "info"
+	}
+
+	cfg.Cassette = r.resolve(cfg, cassetteSetting, "", "")
+	cfg.CassetteMode = r.resolve(cfg, cassetteModeSetting, "", "replay")
+	if cfg.CassetteMode != "record" && cfg.CassetteMode != "replay" {
+		return nil, fmt.Errorf("invalid cassette-mode %q from %s: expected record or replay", cfg.CassetteMode, cfg.sources[cassetteModeSetting.name])
+	}
+
+	if flags != nil && flags.Lookup("columns") != nil {
+		cfg.Columns, _ = flags.GetStringSlice("columns")
+	}
+	if flags != nil && flags.Lookup("limit") != nil {
+		cfg.Limit, _ = flags.GetInt("limit")
+	}
+	if flags != nil && flags.Lookup("no-cache") != nil {
+		cfg.NoCache, _ = flags.GetBool("no-cache")
+	}
+	if flags != nil && flags.Lookup("refresh") != nil {
+		cfg.RefreshCache, _ = flags.GetBool("refresh")
+	}
+
+	if _, err := url.Parse(cfg.APIURL); err != nil {
+		return nil, fmt.Errorf("invalid API URL from %s: %w", cfg.sources[apiURLSetting.name], err)
+	}
+
+	return cfg, nil
+}
+
+// DefaultCacheDir returns digitalocean-go in the user's cache directory, or
+// "" when there is none, which disables caching.
+func DefaultCacheDir() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "digitalocean-go")
+}
+
+// RequireToken makes sure DOToken is set, fetching it from the context's
+// token store if needed, and reports ErrNoToken when there is none.
+func (c *Config) RequireToken() error {
+	if c.DOToken == "" && c.tokenStore != nil {
+		token, err := c.tokenStore.Get(c.Context)
+		if errors.Is(err, ErrSecretNotFound) {
+			return fmt.Errorf("%w: context %q has no token in its %s token store", ErrNoToken, c.Context, c.tokenStoreName)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read token from the %s token store: %w", c.tokenStoreName, err)
+		}
+		c.DOToken = token
+	}
+
+	if c.DOToken == "" {
+		return ErrNoToken
+	}
+	return nil
+}
+
+// Settings returns each resolved setting with the layer it came from. The
+// token is redacted.
+func (c *Config) Settings() []Setting {
+	token := redact(c.DOToken)
+	if token == "" && c.tokenStore != nil {
+		token = fmt.Sprintf("(in %s token store)", c.tokenStoreName)
+	}
+
+	values := map[string]string{
+		contextSetting.name: c.Context,
+		tokenSetting.name:   token,
+		regionSetting.name:  c.Region,
+		sizeSetting.name:    c.Size,
+		outputSetting.name:  c.Output,
+		apiURLSetting.name:  c.APIURL,
+		retriesSetting.name: strconv.Itoa(c.MaxRetries),
+		rateSetting.name:    strconv.FormatFloat(c.RateLimit, 'f', -1, 64),
+
+		cacheDirSetting.name: c.CacheDir,
+		cacheTTLSetting.name: c.CacheTTL.String(),
+
+		logLevelSetting.name:  c.LogLevel,
+		logFormatSetting.name: c.LogFormat,
+		logFileSetting.name:   c.LogFile,
+
+		debugHTTPSetting.name:     strconv.FormatBool(c.DebugHTTP),
+		debugHTTPBodySetting.name: strconv.FormatBool(c.DebugHTTPBodies),
+
+		cassetteSetting.name:     c.Cassette,
+		cassetteModeSetting.name: c.CassetteMode,
+	}
+
+	settings := make([]Setting, 0, len(settingOrder))
+	for _, s := range settingOrder {
+		source := c.sources[s.name]
+		if source == "" {
+			source = SourceDefault
+		}
+		settings = append(settings, Setting{Name: s.name, Value: values[s.name], Source: source})
+	}
+	return settings
+}
+
+func redact(token string) string {
+	if token == "" {
+		return ""
+	}
+	if len(token) <= 8 {
+		return "********"
+	}
+	return "********" + token[len(token)-4:]
+}
+
+type resolver struct {
+	flags  *pflag.FlagSet
+	dotenv map[string]string
+}
+
+// resolve returns the first value found for s and records its source on cfg.
+func (r *resolver) resolve(cfg *Config, s setting, fromFile, fallback string) string {
+	value, source := r.lookup(s, fromFile)
+	if value == "" {
+		value, source = fallback, SourceDefault
+	}
+	cfg.sources[s.name] = source
+	return value
+}
+
+func (r *resolver) lookup(s setting, fromFile string) (string, Source) {
+	if s.flag != "" && r.flags != nil {
+		if flag := r.flags.Lookup(s.flag); flag != nil && flag.Changed {
+			return flag.Value.String(), SourceFlag
+		}
+	}
+	if value := os.Getenv(s.env); value != "" {
+		return value, SourceEnv
+	}
+	if value := r.dotenv[s.env]; value != "" {
+		return value, SourceDotEnv
+	}
+	if fromFile != "" {
+		return fromFile, SourceFile
+	}
+	return "", SourceDefault
+}
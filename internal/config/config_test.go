@@ -0,0 +1,144 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/pflag"
+)
+
+// chdir switches to dir for the duration of the test.
+func chdir(t *testing.T, dir string) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(wd) })
+}
+
+func TestLoadLayers(t *testing.T) {
+	dir := t.TempDir()
+	chdir(t, dir)
+
+	path := filepath.Join(dir, "config.yaml")
+	t.Setenv("DO_CONFIG", path)
+	t.Setenv("DO_TOKEN", "")
+	t.Setenv("DO_CONTEXT", "")
+	t.Setenv("DO_REGION", "fra1")
+	t.Setenv("DO_SIZE", "")
+	t.Setenv("DO_OUTPUT", "")
+	t.Setenv("DO_API_URL", "")
+	t.Setenv("DO_MAX_RETRIES", "5")
+	t.Setenv("DO_RATE_LIMIT", "")
+	cacheDir := t.TempDir()
+	t.Setenv("DO_CACHE_DIR", cacheDir)
+	t.Setenv("DO_CACHE_TTL", "")
+	t.Setenv("DO_LOG_LEVEL", "debug")
+	t.Setenv("DO_LOG_FORMAT", "")
+	t.Setenv("DO_LOG_FILE", "")
+	t.Setenv("DO_DEBUG", "1")
+	t.Setenv("DO_DEBUG_BODY", "")
+
+	file := &File{
+		CurrentContext: "work",
+		Contexts: map[string]Context{
+			"work": {Token: "file-token", Region: "ams3", Size: "s-1vcpu-1gb", Output: "yaml"},
+		},
+	}
+	if err := file.Write(path); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(".env", []byte("DO_SIZE=s-2vcpu-2gb\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	flags.String("output", "", "")
+	flags.String("token", "", "")
+	if err := flags.Parse([]string{"--output", "json"}); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := Load(flags)
+	if err != nil {
+		t.Fatalf("Load returned error: %v", err)
+	}
+
+	expected := map[string]Setting{
+		"context": {Value: "work", Source: SourceFile},
+		"token":   {Value: "********oken", Source: SourceFile},
+		"region":  {Value: "fra1", Source: SourceEnv},
+		"size":    {Value: "s-2vcpu-2gb", Source: SourceDotEnv},
+		"output":  {Value: "json", Source: SourceFlag},
+		"api-url": {Value: DefaultAPIURL, Source: SourceDefault},
+
+		"max-retries": {Value: "5", Source: SourceEnv},
+		"rate-limit":  {Value: "4", Source: SourceDefault},
+		"cache-dir":   {Value: cacheDir, Source: SourceEnv},
+		"cache-ttl":   {Value: "1m0s", Source: SourceDefault},
+
+		"log-level":  {Value: "debug", Source: SourceEnv},
+		"log-format": {Value: "text", Source: SourceDefault},
+		"log-file":   {Value: "", Source: SourceDefault},
+
+		"debug-http":      {Value: "true", Source: SourceEnv},
+		"debug-http-body": {Value: "false", Source: SourceDefault},
+
+		"cassette":      {Value: "", Source: SourceDefault},
+		"cassette-mode": {Value: "replay", Source: SourceDefault},
+	}
+	for _, s := range cfg.Settings() {
+		want := expected[s.Name]
+		if s.Value != want.Value || s.Source != want.Source {
+			t.Errorf("%s: expected %q from %s, got %q from %s", s.Name, want.Value, want.Source, s.Value, s.Source)
+		}
+	}
+	if cfg.DOToken != "file-token" || cfg.MaxRetries != 5 {
+		t.Errorf("Unexpected token %q or retries %d", cfg.DOToken, cfg.MaxRetries)
+	}
+}
+
+func TestDebugHTTPLowersLogLevel(t *testing.T) {
+	chdir(t, t.TempDir())
+	t.Setenv("DO_CONFIG", filepath.Join(t.TempDir(), "missing.yaml"))
+	t.Setenv("DO_DEBUG_BODY", "")
+
+	for _, tt := range []struct {
+		level, debug, want string
+	}{
+		{"error", "1", "info"},
+		{"warn", "1", "info"},
+		{"debug", "1", "debug"},
+		{"error", "", "error"},
+	} {
+		t.Setenv("DO_LOG_LEVEL", tt.level)
+		t.Setenv("DO_DEBUG", tt.debug)
+		cfg, err := Load(nil)
+		if err != nil {
+			t.Fatalf("Load returned error: %v", err)
+		}
+		if cfg.LogLevel != tt.want {
+			t.Errorf("log-level %s with debug-http %q: expected %s, got %s", tt.level, tt.debug, tt.want, cfg.LogLevel)
+		}
+	}
+}
+
+func TestLoadWithoutToken(t *testing.T) {
+	chdir(t, t.TempDir())
+	t.Setenv("DO_CONFIG", filepath.Join(t.TempDir(), "missing.yaml"))
+	t.Setenv("DO_TOKEN", "")
+	t.Setenv("DO_CONTEXT", "")
+
+	cfg, err := Load(nil)
+	if err != nil {
+		t.Fatalf("Load without .env or config file returned error: %v", err)
+	}
+	if err := cfg.RequireToken(); !errors.Is(err, ErrNoToken) {
+		t.Errorf("Expected ErrNoToken, got %v", err)
+	}
+}
@@ -0,0 +1,107 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/felipepimentel/digitalocean-go/internal/atomicfile"
+	"gopkg.in/yaml.v2"
+)
+
+// File is the on-disk configuration holding named contexts, one per
+// DigitalOcean account or team.
+type File struct {
+	CurrentContext string             `yaml:"current-context,omitempty"`
+	Contexts       map[string]Context `yaml:"contexts,omitempty"`
+}
+
+// Context holds the settings used when a context is selected. The token is
+// either kept in Token or, when TokenStore names a backend (see
+// NewSecretStore), in that store under the context's name.
+type Context struct {
+	Token      string `yaml:"token,omitempty"`
+	TokenStore string `yaml:"token-store,omitempty"`
+	Region     string `yaml:"region,omitempty"`
+	Size       string `yaml:"size,omitempty"`
+	Output     string `yaml:"output,omitempty"`
+	APIURL     string `yaml:"api-url,omitempty"`
+}
+
+// FilePath returns the location of the config file: $DO_CONFIG if set,
+// otherwise digitalocean-go/config.yaml in the user's config directory.
+func FilePath() (string, error) {
+	if path := os.Getenv("DO_CONFIG"); path != "" {
+		return path, nil
+	}
+
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "digitalocean-go", "config.yaml"), nil
+}
+
+// ReadFile reads the config file at path. A missing file yields an empty
+// configuration.
+func ReadFile(path string) (*File, error) {
+	file := &File{Contexts: map[string]Context{}}
+
+	raw, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return file, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := yaml.Unmarshal(raw, file); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	if file.Contexts == nil {
+		file.Contexts = map[string]Context{}
+	}
+	return file, nil
+}
+
+// Write saves the file to path. The file holds API tokens, so it is only
+// readable by its owner, and it is replaced atomically, so a failed write
+// leaves the previous contexts intact.
+func (f *File) Write(path string) error {
+	raw, err := yaml.Marshal(f)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	return atomicfile.Write(path, raw)
+}
+
+// Context returns the named context. An empty name selects nothing and
+// returns a zero Context.
+func (f *File) Context(name string) (Context, error) {
+	if name == "" {
+		return Context{}, nil
+	}
+
+	ctx, ok := f.Contexts[name]
+	if !ok {
+		return Context{}, fmt.Errorf("context %q not found", name)
+	}
+	return ctx, nil
+}
+
+// ContextNames returns the names of all contexts in sorted order.
+func (f *File) ContextNames() []string {
+	names := make([]string, 0, len(f.Contexts))
+	for name := range f.Contexts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
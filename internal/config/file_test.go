@@ -0,0 +1,52 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFileRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "config.yaml")
+
+	file, err := ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile on a missing file returned error: %v", err)
+	}
+	if len(file.Contexts) != 0 {
+		t.Errorf("Expected no contexts, got %d", len(file.Contexts))
+	}
+
+	file.CurrentContext = "work"
+	file.Contexts["work"] = Context{Token: "secret", Region: "ams3", APIURL: "http://localhost:8080/"}
+	file.Contexts["personal"] = Context{Token: "other", Output: "json"}
+	if err := file.Write(path); err != nil {
+		t.Fatalf("Write returned error: %v", err)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("Stat returned error: %v", err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("Expected mode 0600, got %v", info.Mode().Perm())
+	}
+
+	file, err = ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile returned error: %v", err)
+	}
+	ctx, err := file.Context("work")
+	if err != nil {
+		t.Fatalf("Context returned error: %v", err)
+	}
+	if ctx.Region != "ams3" || ctx.Token != "secret" || ctx.APIURL != "http://localhost:8080/" {
+		t.Errorf("Unexpected context: %+v", ctx)
+	}
+	if names := file.ContextNames(); len(names) != 2 || names[0] != "personal" {
+		t.Errorf("Unexpected context names: %v", names)
+	}
+	if _, err := file.Context("missing"); err == nil {
+		t.Error("Expected an error for a missing context")
+	}
+}
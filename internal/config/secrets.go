@@ -0,0 +1,85 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/term"
+)
+
+// SecretStore keeps API tokens outside the config file. Keys are context
+// names.
+type SecretStore interface {
+	Get(key string) (string, error)
+	Set(key, secret string) error
+	Delete(key string) error
+}
+
+// ErrSecretNotFound is returned by SecretStore.Get for unknown keys.
+var ErrSecretNotFound = errors.New("secret not found")
+
+// PassphraseFunc supplies the passphrase protecting the encrypted file store.
+type PassphraseFunc func() (string, error)
+
+// NewSecretStore returns the backend named by a context's token-store
+// setting: "keyring" for the desktop Secret Service, "file" for the
+// passphrase-encrypted secrets file next to the config file, or
+// "helper:NAME" for an external credential helper.
+func NewSecretStore(name string, passphrase PassphraseFunc) (SecretStore, error) {
+	switch {
+	case name == "keyring":
+		return &keyringStore{service: "digitalocean-go"}, nil
+	case name == "file":
+		path, err := SecretsFilePath()
+		if err != nil {
+			return nil, err
+		}
+		return &fileStore{path: path, passphrase: passphrase}, nil
+	case strings.HasPrefix(name, "helper:"):
+		program := strings.TrimPrefix(name, "helper:")
+		if program == "" {
+			return nil, fmt.Errorf("token store %q does not name a credential helper", name)
+		}
+		return &helperStore{program: program}, nil
+	default:
+		return nil, fmt.Errorf("unknown token store %q: expected keyring, file or helper:NAME", name)
+	}
+}
+
+// SecretsFilePath returns the location of the encrypted secrets file:
+// $DO_SECRETS_FILE if set, otherwise secrets.enc beside the config file.
+func SecretsFilePath() (string, error) {
+	if path := os.Getenv("DO_SECRETS_FILE"); path != "" {
+		return path, nil
+	}
+
+	path, err := FilePath()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(filepath.Dir(path), "secrets.enc"), nil
+}
+
+// TerminalPassphrase reads the passphrase from $DO_PASSPHRASE or, when
+// stdin is a terminal, prompts for it without echoing.
+func TerminalPassphrase() (string, error) {
+	if passphrase := os.Getenv("DO_PASSPHRASE"); passphrase != "" {
+		return passphrase, nil
+	}
+
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		return "", errors.New("the encrypted token store needs a passphrase: set DO_PASSPHRASE")
+	}
+
+	fmt.Fprint(os.Stderr, "Passphrase for the token store: ")
+	passphrase, err := term.ReadPassword(fd)
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return "", err
+	}
+	return string(passphrase), nil
+}
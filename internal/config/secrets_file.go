@@ -0,0 +1,173 @@
+package config
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/felipepimentel/digitalocean-go/internal/atomicfile"
+	"golang.org/x/crypto/scrypt"
+)
+
+// scrypt parameters recommended for interactive logins.
+const (
+	scryptN      = 1 << 15
+	scryptR      = 8
+	scryptP      = 1
+	scryptKeyLen = 32
+)
+
+// fileStore keeps secrets in a single file encrypted with AES-256-GCM under
+// a key derived from a passphrase with scrypt.
+type fileStore struct {
+	path       string
+	passphrase PassphraseFunc
+}
+
+// encryptedFile is the on-disk layout of the secrets file. Byte slices are
+// base64 encoded by encoding/json.
+type encryptedFile struct {
+	Version int    `json:"version"`
+	Salt    []byte `json:"salt"`
+	Nonce   []byte `json:"nonce"`
+	Data    []byte `json:"data"`
+}
+
+func (s *fileStore) Get(key string) (string, error) {
+	secrets, _, err := s.load()
+	if err != nil {
+		return "", err
+	}
+
+	secret, ok := secrets[key]
+	if !ok {
+		return "", ErrSecretNotFound
+	}
+	return secret, nil
+}
+
+func (s *fileStore) Set(key, secret string) error {
+	secrets, aead, err := s.load()
+	if err != nil {
+		return err
+	}
+
+	secrets[key] = secret
+	return s.save(secrets, aead)
+}
+
+func (s *fileStore) Delete(key string) error {
+	secrets, aead, err := s.load()
+	if err != nil {
+		return err
+	}
+
+	if _, ok := secrets[key]; !ok {
+		return ErrSecretNotFound
+	}
+	delete(secrets, key)
+	return s.save(secrets, aead)
+}
+
+// sealer pairs a cipher with the salt its key was derived from.
+type sealer struct {
+	cipher.AEAD
+	salt []byte
+}
+
+func (s *fileStore) load() (map[string]string, *sealer, error) {
+	raw, err := os.ReadFile(s.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		salt := make([]byte, 16)
+		if _, err := rand.Read(salt); err != nil {
+			return nil, nil, err
+		}
+		aead, err := s.cipher(salt)
+		if err != nil {
+			return nil, nil, err
+		}
+		return map[string]string{}, aead, nil
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var file encryptedFile
+	if err := json.Unmarshal(raw, &file); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse %s: %w", s.path, err)
+	}
+	if file.Version != 1 {
+		return nil, nil, fmt.Errorf("unsupported secrets file version %d", file.Version)
+	}
+
+	aead, err := s.cipher(file.Salt)
+	if err != nil {
+		return nil, nil, err
+	}
+	plaintext, err := aead.Open(nil, file.Nonce, file.Data, nil)
+	if err != nil {
+		return nil, nil, errors.New("failed to decrypt the secrets file: wrong passphrase or corrupted file")
+	}
+
+	secrets := map[string]string{}
+	if err := json.Unmarshal(plaintext, &secrets); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse decrypted secrets: %w", err)
+	}
+	return secrets, aead, nil
+}
+
+func (s *fileStore) cipher(salt []byte) (*sealer, error) {
+	if s.passphrase == nil {
+		return nil, errors.New("no passphrase available for the encrypted token store")
+	}
+	passphrase, err := s.passphrase()
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := scrypt.Key([]byte(passphrase), salt, scryptN, scryptR, scryptP, scryptKeyLen)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &sealer{AEAD: aead, salt: salt}, nil
+}
+
+func (s *fileStore) save(secrets map[string]string, aead *sealer) error {
+	plaintext, err := json.Marshal(secrets)
+	if err != nil {
+		return err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return err
+	}
+	raw, err := json.Marshal(encryptedFile{
+		Version: 1,
+		Salt:    aead.salt,
+		Nonce:   nonce,
+		Data:    aead.Seal(nil, nonce, plaintext, nil),
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(s.path), 0700); err != nil {
+		return err
+	}
+	return atomicfile.Write(s.path, raw)
+}
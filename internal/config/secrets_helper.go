@@ -0,0 +1,92 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// helperStore delegates to an external credential helper. The protocol is
+// this tool's own: it borrows the action names of Docker credential helpers,
+// but not their JSON, so Docker helpers cannot be used. The helper is run
+// with one of the actions get, store or erase as its only argument:
+//
+//	get    stdin: the key          stdout: {"Key": "...", "Secret": "..."}
+//	store  stdin: {"Key": "...", "Secret": "..."}
+//	erase  stdin: the key
+//
+// A helper that does not know a key must exit non-zero and print a message
+// containing "not found", in any letter case, on stdout or stderr. Get and
+// Delete then return ErrSecretNotFound; any other failure is returned with
+// the helper's output.
+//
+// A bare program name is looked up on $PATH as
+// digitalocean-go-credential-NAME; anything containing a slash is run as is.
+type helperStore struct {
+	program string
+}
+
+type helperCredential struct {
+	Key    string `json:"Key"`
+	Secret string `json:"Secret"`
+}
+
+func (s *helperStore) Get(key string) (string, error) {
+	out, err := s.run("get", []byte(key))
+	if err != nil {
+		return "", err
+	}
+
+	var cred helperCredential
+	if err := json.Unmarshal(out, &cred); err != nil {
+		return "", fmt.Errorf("credential helper %s returned invalid output: %w", s.program, err)
+	}
+	return cred.Secret, nil
+}
+
+func (s *helperStore) Set(key, secret string) error {
+	input, err := json.Marshal(helperCredential{Key: key, Secret: secret})
+	if err != nil {
+		return err
+	}
+	_, err = s.run("store", input)
+	return err
+}
+
+func (s *helperStore) Delete(key string) error {
+	_, err := s.run("erase", []byte(key))
+	return err
+}
+
+func (s *helperStore) path() string {
+	if strings.ContainsRune(s.program, '/') {
+		return s.program
+	}
+	return "digitalocean-go-credential-" + s.program
+}
+
+func (s *helperStore) run(action string, input []byte) ([]byte, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command(s.path(), action)
+	cmd.Stdin = bytes.NewReader(input)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		var exitErr *exec.ExitError
+		if !errors.As(err, &exitErr) {
+			return nil, fmt.Errorf("failed to run credential helper %s: %w", s.path(), err)
+		}
+
+		message := strings.TrimSpace(stdout.String() + " " + stderr.String())
+		if strings.Contains(strings.ToLower(message), "not found") {
+			return nil, ErrSecretNotFound
+		}
+		return nil, fmt.Errorf("credential helper %s %s failed: %s", s.path(), action, message)
+	}
+
+	return stdout.Bytes(), nil
+}
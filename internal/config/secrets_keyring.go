@@ -0,0 +1,62 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// keyringStore keeps tokens in the desktop keyring through the freedesktop
+// Secret Service (GNOME Keyring, KWallet), using libsecret's secret-tool.
+type keyringStore struct {
+	service string
+}
+
+func (s *keyringStore) Get(key string) (string, error) {
+	out, err := s.run(nil, "lookup", "service", s.service, "context", key)
+	if err != nil {
+		return "", err
+	}
+
+	// secret-tool exits successfully with no output for unknown keys on
+	// some versions and with status 1 on others.
+	if len(out) == 0 {
+		return "", ErrSecretNotFound
+	}
+	return strings.TrimRight(string(out), "\n"), nil
+}
+
+func (s *keyringStore) Set(key, secret string) error {
+	label := fmt.Sprintf("%s token (%s)", s.service, key)
+	_, err := s.run([]byte(secret), "store", "--label", label, "service", s.service, "context", key)
+	return err
+}
+
+func (s *keyringStore) Delete(key string) error {
+	_, err := s.run(nil, "clear", "service", s.service, "context", key)
+	return err
+}
+
+func (s *keyringStore) run(input []byte, args ...string) ([]byte, error) {
+	path, err := exec.LookPath("secret-tool")
+	if err != nil {
+		return nil, errors.New("the keyring token store needs secret-tool (libsecret) and a running Secret Service")
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command(path, args...)
+	cmd.Stdin = bytes.NewReader(input)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && args[0] == "lookup" && stderr.Len() == 0 {
+			return nil, ErrSecretNotFound
+		}
+		return nil, fmt.Errorf("secret-tool %s failed: %s", args[0], strings.TrimSpace(stderr.String()))
+	}
+	return stdout.Bytes(), nil
+}
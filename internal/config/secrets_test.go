@@ -0,0 +1,148 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestFileStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "secrets.enc")
+	passphrase := func() (string, error) { return "correct horse", nil }
+	store := &fileStore{path: path, passphrase: passphrase}
+
+	if _, err := store.Get("work"); !errors.Is(err, ErrSecretNotFound) {
+		t.Errorf("Expected ErrSecretNotFound from an empty store, got %v", err)
+	}
+	if err := store.Set("work", "dop_v1_secret"); err != nil {
+		t.Fatalf("Set returned error: %v", err)
+	}
+	if err := store.Set("personal", "dop_v1_other"); err != nil {
+		t.Fatalf("Set returned error: %v", err)
+	}
+
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(raw), "dop_v1") {
+		t.Error("Secrets file contains a plaintext token")
+	}
+
+	token, err := store.Get("work")
+	if err != nil || token != "dop_v1_secret" {
+		t.Errorf("Expected the stored token, got %q (%v)", token, err)
+	}
+
+	wrong := &fileStore{path: path, passphrase: func() (string, error) { return "wrong", nil }}
+	if _, err := wrong.Get("work"); err == nil {
+		t.Error("Expected an error with the wrong passphrase")
+	}
+
+	if err := store.Delete("work"); err != nil {
+		t.Fatalf("Delete returned error: %v", err)
+	}
+	if _, err := store.Get("work"); !errors.Is(err, ErrSecretNotFound) {
+		t.Errorf("Expected ErrSecretNotFound after Delete, got %v", err)
+	}
+	if token, _ := store.Get("personal"); token != "dop_v1_other" {
+		t.Errorf("Expected the other token to survive, got %q", token)
+	}
+}
+
+// helperScript is a credential helper keeping secrets as files in a
+// directory, with keys passed on stdin.
+const helperScript = `#!/bin/sh
+dir="$(dirname "$0")/store"
+mkdir -p "$dir"
+case "$1" in
+get)
+	key=$(cat)
+	[ -f "$dir/$key" ] || { echo "credentials not found"; exit 1; }
+	printf '{"Key":"%s","Secret":"%s"}' "$key" "$(cat "$dir/$key")"
+	;;
+store)
+	input=$(cat)
+	key=$(echo "$input" | sed 's/.*"Key":"\([^"]*\)".*/\1/')
+	echo "$input" | sed 's/.*"Secret":"\([^"]*\)".*/\1/' > "$dir/$key"
+	;;
+erase)
+	rm -f "$dir/$(cat)"
+	;;
+*)
+	exit 2
+	;;
+esac
+`
+
+func TestHelperStore(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("helper script requires a POSIX shell")
+	}
+
+	path := filepath.Join(t.TempDir(), "digitalocean-go-credential-test")
+	if err := os.WriteFile(path, []byte(helperScript), 0700); err != nil {
+		t.Fatal(err)
+	}
+
+	store, err := NewSecretStore("helper:"+path, nil)
+	if err != nil {
+		t.Fatalf("NewSecretStore returned error: %v", err)
+	}
+
+	if _, err := store.Get("work"); !errors.Is(err, ErrSecretNotFound) {
+		t.Errorf("Expected ErrSecretNotFound, got %v", err)
+	}
+	if err := store.Set("work", "dop_v1_secret"); err != nil {
+		t.Fatalf("Set returned error: %v", err)
+	}
+	token, err := store.Get("work")
+	if err != nil || token != "dop_v1_secret" {
+		t.Errorf("Expected the stored token, got %q (%v)", token, err)
+	}
+	if err := store.Delete("work"); err != nil {
+		t.Fatalf("Delete returned error: %v", err)
+	}
+	if _, err := store.Get("work"); !errors.Is(err, ErrSecretNotFound) {
+		t.Errorf("Expected ErrSecretNotFound after Delete, got %v", err)
+	}
+}
+
+func TestLoadTokenFromStore(t *testing.T) {
+	chdir(t, t.TempDir())
+	dir := t.TempDir()
+	t.Setenv("DO_CONFIG", filepath.Join(dir, "config.yaml"))
+	t.Setenv("DO_SECRETS_FILE", filepath.Join(dir, "secrets.enc"))
+	t.Setenv("DO_PASSPHRASE", "correct horse")
+	t.Setenv("DO_TOKEN", "")
+	t.Setenv("DO_CONTEXT", "")
+
+	store, err := NewSecretStore("file", TerminalPassphrase)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Set("work", "dop_v1_secret"); err != nil {
+		t.Fatal(err)
+	}
+	file := &File{CurrentContext: "work", Contexts: map[string]Context{"work": {TokenStore: "file"}}}
+	if err := file.Write(filepath.Join(dir, "config.yaml")); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := Load(nil)
+	if err != nil {
+		t.Fatalf("Load returned error: %v", err)
+	}
+	if cfg.DOToken != "" {
+		t.Error("Expected the token to be fetched lazily")
+	}
+	if err := cfg.RequireToken(); err != nil {
+		t.Fatalf("RequireToken returned error: %v", err)
+	}
+	if cfg.DOToken != "dop_v1_secret" {
+		t.Errorf("Expected the stored token, got %q", cfg.DOToken)
+	}
+}
@@ -0,0 +1,31 @@
+package configcmd
+
+import (
+	"github.com/felipepimentel/digitalocean-go/internal/config"
+	"github.com/felipepimentel/digitalocean-go/internal/output"
+	"github.com/spf13/cobra"
+)
+
+func Cmd(cfg *config.Config) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:         "config",
+		Short:       "Inspect the effective configuration",
+		Annotations: map[string]string{config.NoTokenAnnotation: "true"},
+	}
+
+	cmd.AddCommand(
+		viewCmd(cfg),
+	)
+
+	return cmd
+}
+
+func viewCmd(cfg *config.Config) *cobra.Command {
+	return &cobra.Command{
+		Use:   "view",
+		Short: "Show each setting and where its value came from",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return output.Fprint(cmd.OutOrStdout(), cfg.Settings(), output.FromConfig(cfg))
+		},
+	}
+}
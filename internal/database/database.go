@@ -7,60 +7,87 @@ import (
 	"github.com/felipepimentel/digitalocean-go/internal/api"
 	"github.com/felipepimentel/digitalocean-go/internal/config"
 	"github.com/felipepimentel/digitalocean-go/internal/logging"
+	"github.com/felipepimentel/digitalocean-go/internal/output"
+	"github.com/felipepimentel/digitalocean-go/internal/progress"
 	"github.com/spf13/cobra"
 )
 
-func Cmd(cfg *config.Config) *cobra.Command {
+func Cmd(cfg *config.Config, newClient func(*config.Config) api.DatabaseAPI) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "database",
 		Short: "Manage DigitalOcean managed databases",
 	}
 
 	cmd.AddCommand(
-		listCmd(cfg),
-		createCmd(cfg),
-		deleteCmd(cfg),
+		listCmd(cfg, newClient),
+		createCmd(cfg, newClient),
+		deleteCmd(cfg, newClient),
 	)
 
 	return cmd
 }
 
-func listCmd(cfg *config.Config) *cobra.Command {
+func listCmd(cfg *config.Config, newClient func(*config.Config) api.DatabaseAPI) *cobra.Command {
 	return &cobra.Command{
 		Use:   "list",
 		Short: "List all managed databases",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			client := api.NewClient(cfg)
-			databases, err := client.ListDatabases(context.Background())
+			client := newClient(cfg)
+			databases, err := client.ListDatabases(context.Background(), api.PageOptionsFromConfig(cfg))
 			if err != nil {
-				logging.ErrorLogger.Printf("Failed to list databases: %v", err)
+				logging.Debug("failed to list databases", "err", err)
 				return err
 			}
 
-			for _, db := range databases {
-				fmt.Printf("ID: %s, Name: %s, Engine: %s, Version: %s\n", db.ID, db.Name, db.EngineSlug, db.VersionSlug)
-			}
-			return nil
+			return output.Fprint(cmd.OutOrStdout(), databases, output.FromConfig(cfg))
 		},
 	}
 }
 
-func createCmd(cfg *config.Config) *cobra.Command {
+func createCmd(cfg *config.Config, newClient func(*config.Config) api.DatabaseAPI) *cobra.Command {
 	var name, engine, version, size, region string
+	var wait progress.WaitFlags
 
 	cmd := &cobra.Command{
 		Use:   "create",
 		Short: "Create a new managed database",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			client := api.NewClient(cfg)
+			if region == "" {
+				region = cfg.Region
+			}
+			if region == "" {
+				return fmt.Errorf("required flag \"region\" not set and the context has no default region")
+			}
+
+			client := newClient(cfg)
 			database, err := client.CreateDatabase(context.Background(), name, engine, version, size, region)
 			if err != nil {
-				logging.ErrorLogger.Printf("Failed to create database: %v", err)
+				logging.Debug("failed to create database", "err", err)
 				return err
 			}
 
-			fmt.Printf("Database created: ID: %s, Name: %s\n", database.ID, database.Name)
-			return nil
+			// A failed wait still prints the database, as it was created, with
+			// its last known state.
+			var waitErr error
+			if wait.Wait {
+				if err := wait.Run(cmd, fmt.Sprintf("Waiting for database %s to come online", name), api.DatabaseOnline(client, database.ID)); err != nil {
+					waitErr = fmt.Errorf("database %s was created but is not online: %w", database.ID, err)
+				}
+				current, err := client.GetDatabase(context.Background(), database.ID)
+				if err != nil {
+					logging.Debug("failed to get database", "err", err)
+					if waitErr == nil {
+						waitErr = err
+					}
+				} else {
+					database = current
+				}
+			}
+
+			if err := output.Fprint(cmd.OutOrStdout(), database, output.FromConfig(cfg)); err != nil {
+				return err
+			}
+			return waitErr
 		},
 	}
 
@@ -68,31 +95,41 @@ func createCmd(cfg *config.Config) *cobra.Command {
 	cmd.Flags().StringVar(&engine, "engine", "", "Database engine (e.g., pg, mysql)")
 	cmd.Flags().StringVar(&version, "version", "", "Database version")
 	cmd.Flags().StringVar(&size, "size", "db-s-1vcpu-1gb", "Database size")
-	cmd.Flags().StringVar(&region, "region", "", "Database region")
+	cmd.Flags().StringVar(&region, "region", "", "Database region (defaults to the context region)")
+
+	wait.Register(cmd.Flags(), "the database is online")
 
 	cmd.MarkFlagRequired("name")
 	cmd.MarkFlagRequired("engine")
 	cmd.MarkFlagRequired("version")
-	cmd.MarkFlagRequired("region")
 
 	return cmd
 }
 
-func deleteCmd(cfg *config.Config) *cobra.Command {
-	return &cobra.Command{
+func deleteCmd(cfg *config.Config, newClient func(*config.Config) api.DatabaseAPI) *cobra.Command {
+	var wait progress.WaitFlags
+
+	cmd := &cobra.Command{
 		Use:   "delete [database_id]",
 		Short: "Delete a managed database",
 		Args:  cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			client := api.NewClient(cfg)
+			client := newClient(cfg)
 			err := client.DeleteDatabase(context.Background(), args[0])
 			if err != nil {
-				logging.ErrorLogger.Printf("Failed to delete database: %v", err)
+				logging.Debug("failed to delete database", "err", err)
+				return err
+			}
+			if err := wait.Run(cmd, fmt.Sprintf("Waiting for database %s to be deleted", args[0]), api.DatabaseDeleted(client, args[0])); err != nil {
 				return err
 			}
 
-			fmt.Printf("Database %s deleted\n", args[0])
+			fmt.Fprintf(cmd.OutOrStdout(), "Database %s deleted\n", args[0])
 			return nil
 		},
 	}
-}
\ No newline at end of file
+
+	wait.Register(cmd.Flags(), "the database is gone")
+
+	return cmd
+}
@@ -0,0 +1,62 @@
+package database
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/felipepimentel/digitalocean-go/internal/api"
+	"github.com/felipepimentel/digitalocean-go/internal/api/apitest"
+	"github.com/felipepimentel/digitalocean-go/internal/api/fake"
+	"github.com/felipepimentel/digitalocean-go/internal/config"
+)
+
+// These tests replay the cassettes in testdata. Re-record them with
+// DO_RECORD=1 go test ./internal/database.
+
+func TestListReplay(t *testing.T) {
+	out, err := apitest.Run(Cmd(&config.Config{Output: "table"}, apitest.Replay[api.DatabaseAPI](t, "list")), "list")
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	for _, want := range []string{"prod-db", "pg", "online"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected %q in the output:\n%s", want, out)
+		}
+	}
+}
+
+func TestCreateDeleteReplay(t *testing.T) {
+	newClient := apitest.Replay[api.DatabaseAPI](t, "create_delete")
+	cfg := &config.Config{Output: "json", Region: "nyc1"}
+
+	out, err := apitest.Run(Cmd(cfg, newClient), "create", "--name", "staging-db", "--engine", "pg", "--version", "16")
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if !strings.Contains(out, `"name": "staging-db"`) || !strings.Contains(out, `"status": "creating"`) {
+		t.Errorf("Unexpected create output:\n%s", out)
+	}
+
+	out, err = apitest.Run(Cmd(cfg, newClient), "delete", "9cc10173-e9ea-4176-9dbc-a4cee4c4ff30")
+	if err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if !strings.Contains(out, "9cc10173-e9ea-4176-9dbc-a4cee4c4ff30") {
+		t.Errorf("Unexpected delete output %q", out)
+	}
+}
+
+func TestCreateWaitFailure(t *testing.T) {
+	client := fake.New()
+	client.Errors["GetDatabase"] = errors.New("boom")
+
+	out, err := apitest.Run(Cmd(&config.Config{Output: "json", Region: "nyc1"}, fake.Factory[api.DatabaseAPI](client)),
+		"create", "--name", "staging", "--engine", "pg", "--version", "16", "--wait")
+	if err == nil || !strings.Contains(err.Error(), "was created but is not online: boom") {
+		t.Errorf("Expected the wait error, got %v", err)
+	}
+	if !strings.Contains(out, `"name": "staging"`) {
+		t.Errorf("Expected the created database to be printed:\n%s", out)
+	}
+}
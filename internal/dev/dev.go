@@ -0,0 +1,92 @@
+package dev
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/felipepimentel/digitalocean-go/internal/config"
+	"github.com/felipepimentel/digitalocean-go/internal/fakeapi"
+	"github.com/spf13/cobra"
+)
+
+func Cmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:         "dev",
+		Short:       "Tools for developing against the CLI offline",
+		Annotations: map[string]string{config.NoTokenAnnotation: "true"},
+	}
+
+	cmd.AddCommand(
+		fakeAPICmd(),
+	)
+
+	return cmd
+}
+
+func fakeAPICmd() *cobra.Command {
+	var addr, fixtures, token string
+	var empty bool
+	var delay time.Duration
+
+	cmd := &cobra.Command{
+		Use:   "fake-api",
+		Short: "Serve an in-memory fake of the DigitalOcean API",
+		Long: `Serve an in-memory fake of the droplet, VPC, Kubernetes, database, domain
+and balance endpoints used by this CLI. State is lost when the server stops.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts := fakeapi.Options{ProvisionDelay: delay, Token: token}
+			switch {
+			case fixtures != "":
+				f, err := fakeapi.LoadFixtures(fixtures)
+				if err != nil {
+					return err
+				}
+				opts.Fixtures = f
+			case !empty:
+				opts.Fixtures = fakeapi.DefaultFixtures()
+			}
+
+			listener, err := net.Listen("tcp", addr)
+			if err != nil {
+				return err
+			}
+			server := &http.Server{Handler: fakeapi.New(opts), ReadHeaderTimeout: 10 * time.Second}
+
+			url := fmt.Sprintf("http://%s/", listener.Addr())
+			if token == "" {
+				token = "fake"
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Fake DigitalOcean API listening on %s\n", url)
+			fmt.Fprintf(cmd.OutOrStdout(), "Point the CLI at it with:\n\n  export DO_API_URL=%s DO_TOKEN=%s\n\n", url, token)
+
+			ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt)
+			defer stop()
+			go func() {
+				<-ctx.Done()
+				shutdown, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+				defer cancel()
+				server.Shutdown(shutdown)
+			}()
+
+			if err := server.Serve(listener); !errors.Is(err, http.ErrServerClosed) {
+				return err
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&addr, "addr", "127.0.0.1:8080", "Address to listen on")
+	cmd.Flags().StringVar(&fixtures, "fixtures", "", "JSON file to seed the server with instead of the built-in fixtures")
+	cmd.Flags().BoolVar(&empty, "empty", false, "Start without any resources")
+	cmd.Flags().DurationVar(&delay, "provision-delay", 5*time.Second, "How long created resources stay provisioning")
+	cmd.Flags().StringVar(&token, "token", "", "Only accept this API token (any token is accepted by default)")
+
+	return cmd
+}
@@ -0,0 +1,331 @@
+package domain
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/digitalocean/godo"
+	"github.com/felipepimentel/digitalocean-go/internal/api"
+	"github.com/felipepimentel/digitalocean-go/internal/api/apitest"
+	"github.com/felipepimentel/digitalocean-go/internal/api/fake"
+	"github.com/felipepimentel/digitalocean-go/internal/config"
+)
+
+// These tests replay the cassettes in testdata. Re-record them with
+// DO_RECORD=1 go test ./internal/domain.
+
+func TestListReplay(t *testing.T) {
+	out, err := apitest.Run(Cmd(&config.Config{Output: "name"}, apitest.Replay[api.DomainAPI](t, "list")), "list")
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if out != "example.com\n" {
+		t.Errorf("Unexpected list output %q", out)
+	}
+}
+
+func TestRecordsReplay(t *testing.T) {
+	newClient := apitest.Replay[api.DomainAPI](t, "records")
+	cfg := &config.Config{Output: "table"}
+
+	out, err := apitest.Run(Cmd(cfg, newClient), "record", "list", "--domain", "example.com")
+	if err != nil {
+		t.Fatalf("record list: %v", err)
+	}
+	for _, want := range []string{"SOA", "ns1.digitalocean.com", "mail.example.com.", "v=spf1"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected %q in the output:\n%s", want, out)
+		}
+	}
+
+	cfg.Output = "name"
+	out, err = apitest.Run(Cmd(cfg, newClient), "record", "create", "--domain", "example.com", "--type", "a", "--name", "api", "--data", "198.51.100.9")
+	if err != nil {
+		t.Fatalf("record create: %v", err)
+	}
+	id := strings.TrimSpace(out)
+
+	cfg.Output = "jsonpath={.data} {.ttl}"
+	out, err = apitest.Run(Cmd(cfg, newClient), "record", "update", id, "--domain", "example.com", "--data", "198.51.100.10", "--ttl", "300")
+	if err != nil {
+		t.Fatalf("record update: %v", err)
+	}
+	if out != "198.51.100.10 300" {
+		t.Errorf("Unexpected record update output %q", out)
+	}
+
+	cfg.Output = "jsonpath={.name} {.data}"
+	out, err = apitest.Run(Cmd(cfg, newClient), "record", "get", id, "--domain", "example.com")
+	if err != nil {
+		t.Fatalf("record get: %v", err)
+	}
+	if out != "api 198.51.100.10" {
+		t.Errorf("Unexpected record get output %q", out)
+	}
+
+	out, err = apitest.Run(Cmd(cfg, newClient), "record", "delete", id, "--domain", "example.com")
+	if err != nil {
+		t.Fatalf("record delete: %v", err)
+	}
+	if !strings.Contains(out, id) {
+		t.Errorf("Unexpected record delete output %q", out)
+	}
+}
+
+func TestValidateRecord(t *testing.T) {
+	tests := []struct {
+		record godo.DomainRecordEditRequest
+		err    string
+	}{
+		{godo.DomainRecordEditRequest{Type: "A", Name: "www", Data: "198.51.100.7"}, ""},
+		{godo.DomainRecordEditRequest{Type: "A", Name: "www", Data: "2001:db8::1"}, "IPv4"},
+		{godo.DomainRecordEditRequest{Type: "AAAA", Name: "www", Data: "2001:db8::1"}, ""},
+		{godo.DomainRecordEditRequest{Type: "A", Name: "www", Data: "198.51.100.7", Priority: 10}, "priority only applies"},
+		{godo.DomainRecordEditRequest{Type: "A", Name: "www", Data: "198.51.100.7", TTL: 10}, "at least 30"},
+		{godo.DomainRecordEditRequest{Type: "CNAME", Name: "www", Data: "@"}, ""},
+		{godo.DomainRecordEditRequest{Type: "CNAME", Name: "www", Data: "not a host"}, "hostname"},
+		{godo.DomainRecordEditRequest{Type: "MX", Name: "@", Data: "mail.example.com.", Priority: 10}, ""},
+		{godo.DomainRecordEditRequest{Type: "SRV", Name: "_sip._tcp", Data: "sip.example.com.", Priority: 10, Weight: 5, Port: 5060}, ""},
+		{godo.DomainRecordEditRequest{Type: "SRV", Name: "_sip._tcp", Data: "sip.example.com."}, "port"},
+		{godo.DomainRecordEditRequest{Type: "SRV", Name: "sip", Data: "sip.example.com.", Port: 5060}, "_sip._tcp"},
+		{godo.DomainRecordEditRequest{Type: "CAA", Name: "@", Data: "letsencrypt.org", Tag: "issue"}, ""},
+		{godo.DomainRecordEditRequest{Type: "CAA", Name: "@", Data: "letsencrypt.org"}, "needs a tag"},
+		{godo.DomainRecordEditRequest{Type: "CAA", Name: "@", Data: "letsencrypt.org", Tag: "issue", Flags: 300}, "between 0 and 255"},
+		{godo.DomainRecordEditRequest{Type: "TXT", Name: "@", Data: "v=spf1 -all", Tag: "issue"}, "only apply to CAA"},
+		{godo.DomainRecordEditRequest{Type: "SOA", Name: "@", Data: "x"}, "unsupported record type"},
+	}
+
+	for _, tt := range tests {
+		err := validateRecord(&tt.record)
+		if tt.err == "" && err != nil {
+			t.Errorf("%+v: unexpected error %v", tt.record, err)
+		}
+		if tt.err != "" && (err == nil || !strings.Contains(err.Error(), tt.err)) {
+			t.Errorf("%+v: expected an error containing %q, got %v", tt.record, tt.err, err)
+		}
+	}
+}
+
+func TestCreateDeleteReplay(t *testing.T) {
+	newClient := apitest.Replay[api.DomainAPI](t, "create_delete")
+	cfg := &config.Config{Output: "name"}
+
+	out, err := apitest.Run(Cmd(cfg, newClient), "create", "--name", "example.org")
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if out != "example.org\n" {
+		t.Errorf("Unexpected create output %q", out)
+	}
+
+	if _, err := apitest.Run(Cmd(cfg, newClient), "delete", "example.org"); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+}
+
+func TestExportImportRoundTrip(t *testing.T) {
+	source := fake.New()
+	source.Domains = []godo.Domain{{Name: "example.com"}}
+	// Target hostnames come without a trailing dot, as the API returns them.
+	source.Records["example.com"] = []godo.DomainRecord{
+		{ID: 1, Type: "SOA", Name: "@", Data: "1800", TTL: 1800},
+		{ID: 2, Type: "NS", Name: "@", Data: "ns1.digitalocean.com", TTL: 1800},
+		{ID: 3, Type: "A", Name: "www", Data: "198.51.100.7", TTL: 300},
+		{ID: 4, Type: "MX", Name: "@", Data: "mail.example.com", Priority: 10, TTL: 1800},
+		{ID: 5, Type: "TXT", Name: "@", Data: "v=spf1 include:\"quoted\" " + strings.Repeat("x", 300), TTL: 3600},
+		{ID: 6, Type: "CAA", Name: "@", Data: "letsencrypt.org", Tag: "issue", TTL: 1800},
+		{ID: 7, Type: "SRV", Name: "_sip._tcp", Data: "sip.example.com", Priority: 10, Weight: 5, Port: 5060, TTL: 1800},
+		{ID: 8, Type: "CNAME", Name: "docs", Data: "example.github.io", TTL: 1800},
+		{ID: 9, Type: "CNAME", Name: "blog", Data: "@", TTL: 1800},
+		{ID: 10, Type: "NS", Name: "dev", Data: "ns1.example.net", TTL: 1800},
+	}
+	cfg := &config.Config{}
+
+	path := filepath.Join(t.TempDir(), "example.com.db")
+	if _, err := apitest.Run(Cmd(cfg, fake.Factory[api.DomainAPI](source)), "export", "example.com", "--file", path); err != nil {
+		t.Fatalf("export: %v", err)
+	}
+	zone, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"ns1.digitalocean.com. hostmaster", "10 mail.example.com.\n", "5060 sip.example.com.\n", "example.github.io.\n", "CNAME @\n", "ns1.example.net.\n"} {
+		if !strings.Contains(string(zone), want) {
+			t.Errorf("Expected %q in the zone file:\n%s", want, zone)
+		}
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("Expected the zone file to be readable by its owner only, got %v", info.Mode())
+	}
+
+	target := fake.New()
+	target.Domains = []godo.Domain{{Name: "example.com"}}
+	target.Records["example.com"] = []godo.DomainRecord{
+		{ID: 1, Type: "SOA", Name: "@", Data: "1800", TTL: 1800},
+		{ID: 2, Type: "NS", Name: "@", Data: "ns1.digitalocean.com.", TTL: 1800},
+		{ID: 3, Type: "A", Name: "www", Data: "198.51.100.7", TTL: 300},
+	}
+	out, err := apitest.Run(Cmd(cfg, fake.Factory[api.DomainAPI](target)), "import", "example.com", path)
+	if err != nil {
+		t.Fatalf("import: %v", err)
+	}
+	if !strings.Contains(out, "Imported 7 records into example.com (1 already present, 2 managed by DigitalOcean skipped)") {
+		t.Errorf("Unexpected import output %q", out)
+	}
+
+	imported := target.Records["example.com"]
+	if len(imported) != len(source.Records["example.com"]) {
+		t.Fatalf("Expected %d records after the import, got %+v", len(source.Records["example.com"]), imported)
+	}
+	for i, want := range source.Records["example.com"] {
+		got := imported[i]
+		got.ID = want.ID
+		got.Data = strings.TrimSuffix(got.Data, ".")
+		if got != want {
+			t.Errorf("Record %d: expected %+v, got %+v", i, want, got)
+		}
+	}
+
+	// Importing the export back into the domain it came from is a no-op.
+	out, err = apitest.Run(Cmd(cfg, fake.Factory[api.DomainAPI](source)), "import", "example.com", path)
+	if err != nil || !strings.Contains(out, "Imported 0 records into example.com (8 already present, 2 managed by DigitalOcean skipped)") {
+		t.Errorf("Expected nothing to import, got %v, %q", err, out)
+	}
+}
+
+func TestImportValidatesFirst(t *testing.T) {
+	client := fake.New()
+	client.Domains = []godo.Domain{{Name: "example.com"}}
+
+	path := filepath.Join(t.TempDir(), "zone.db")
+	zone := "www A 198.51.100.7\nbad A not-an-ip\n"
+	if err := os.WriteFile(path, []byte(zone), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := apitest.Run(Cmd(&config.Config{}, fake.Factory[api.DomainAPI](client)), "import", "example.com", path)
+	if err == nil || !strings.Contains(err.Error(), "IPv4") {
+		t.Errorf("Expected a validation error, got %v", err)
+	}
+	if len(client.Records["example.com"]) != 0 {
+		t.Errorf("Expected no records to be created, got %+v", client.Records["example.com"])
+	}
+}
+
+func syncFixture() *fake.Client {
+	client := fake.New()
+	client.Domains = []godo.Domain{{Name: "example.com"}}
+	client.Records["example.com"] = []godo.DomainRecord{
+		{ID: 1, Type: "SOA", Name: "@", Data: "1800", TTL: 1800},
+		{ID: 2, Type: "NS", Name: "@", Data: "ns1.digitalocean.com", TTL: 1800},
+		{ID: 3, Type: "NS", Name: "dev", Data: "ns1.example.net", TTL: 1800},
+		{ID: 4, Type: "A", Name: "www", Data: "198.51.100.7", TTL: 1800},
+		{ID: 5, Type: "MX", Name: "@", Data: "mail.example.com", Priority: 10, TTL: 1800},
+		{ID: 6, Type: "TXT", Name: "old", Data: "stale", TTL: 1800},
+	}
+	return client
+}
+
+const syncYAML = `domain: example.com
+records:
+  - {type: a, name: www, data: 198.51.100.7, ttl: 300}
+  - {type: MX, name: example.com., data: mail.example.com., priority: 20}
+  - {type: CNAME, name: api, data: "@"}
+`
+
+func runSync(t *testing.T, client *fake.Client, stdin string, args ...string) (string, error) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "records.yaml")
+	if err := os.WriteFile(path, []byte(syncYAML), 0600); err != nil {
+		t.Fatal(err)
+	}
+	cmd := Cmd(&config.Config{}, fake.Factory[api.DomainAPI](client))
+	cmd.SetIn(strings.NewReader(stdin))
+	return apitest.Run(cmd, append([]string{"sync", "-f", path}, args...)...)
+}
+
+func TestSyncPlan(t *testing.T) {
+	client := syncFixture()
+
+	out, err := runSync(t, client, "", "--dry-run")
+	if err != nil {
+		t.Fatalf("sync --dry-run: %v", err)
+	}
+	for _, want := range []string{
+		`+ CNAME api "@"`,
+		`~ A www "198.51.100.7" ttl=1800`,
+		`=> A www "198.51.100.7" ttl=300`,
+		`=> MX @ "mail.example.com" priority=20`,
+		`- TXT old "stale"`,
+		"Plan for example.com: 1 to create, 2 to update, 1 to delete, 0 unchanged",
+		"1 protected NS records left alone",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected %q in the plan:\n%s", want, out)
+		}
+	}
+	if len(client.Records["example.com"]) != 6 {
+		t.Errorf("Expected --dry-run to change nothing, got %+v", client.Records["example.com"])
+	}
+
+	if _, err := runSync(t, client, "n\n"); err == nil || !strings.Contains(err.Error(), "cancelled") {
+		t.Errorf("Expected a declined sync to be cancelled, got %v", err)
+	}
+	if len(client.Records["example.com"]) != 6 {
+		t.Errorf("Expected a declined sync to change nothing, got %+v", client.Records["example.com"])
+	}
+}
+
+func TestSyncApply(t *testing.T) {
+	client := syncFixture()
+
+	out, err := runSync(t, client, "yes\n")
+	if err != nil {
+		t.Fatalf("sync: %v", err)
+	}
+	if !strings.Contains(out, "Applied: 1 created, 2 updated, 1 deleted") {
+		t.Errorf("Unexpected sync output:\n%s", out)
+	}
+
+	types := map[string]bool{}
+	for _, r := range client.Records["example.com"] {
+		types[r.Type+" "+r.Name] = true
+		if r.Type == "A" && r.TTL != 300 {
+			t.Errorf("Expected the A record TTL to be updated, got %d", r.TTL)
+		}
+	}
+	for _, want := range []string{"SOA @", "NS @", "NS dev", "A www", "MX @", "CNAME api"} {
+		if !types[want] {
+			t.Errorf("Expected a %s record after the sync, got %v", want, types)
+		}
+	}
+	if types["TXT old"] {
+		t.Error("Expected the TXT record to be pruned")
+	}
+
+	out, err = runSync(t, client, "", "--auto-approve")
+	if err != nil || !strings.Contains(out, "example.com is in sync (3 records unchanged)") {
+		t.Errorf("Expected a second sync to find nothing to do, got %v:\n%s", err, out)
+	}
+}
+
+func TestSyncWithoutPrune(t *testing.T) {
+	client := syncFixture()
+
+	out, err := runSync(t, client, "", "--auto-approve", "--prune=false", "--protect-ns=false")
+	if err != nil {
+		t.Fatalf("sync: %v", err)
+	}
+	if !strings.Contains(out, "0 deleted") {
+		t.Errorf("Expected nothing to be deleted:\n%s", out)
+	}
+	if len(client.Records["example.com"]) != 7 {
+		t.Errorf("Expected one record to be added, got %+v", client.Records["example.com"])
+	}
+}
@@ -0,0 +1,361 @@
+package domain
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/digitalocean/godo"
+	"github.com/felipepimentel/digitalocean-go/internal/api"
+	"github.com/felipepimentel/digitalocean-go/internal/config"
+	"github.com/felipepimentel/digitalocean-go/internal/logging"
+	"github.com/felipepimentel/digitalocean-go/internal/output"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// recordTypes are the record types that can be created through the API.
+var recordTypes = []string{"A", "AAAA", "CAA", "CNAME", "MX", "NS", "SRV", "TXT"}
+
+var caaTags = []string{"issue", "issuewild", "iodef"}
+
+// minTTL is the lowest TTL the API accepts.
+const minTTL = 30
+
+func recordCmd(cfg *config.Config, newClient func(*config.Config) api.DomainAPI) *cobra.Command {
+	var domainName string
+
+	cmd := &cobra.Command{
+		Use:   "record",
+		Short: "Manage the DNS records of a domain",
+	}
+
+	cmd.AddCommand(
+		listRecordsCmd(cfg, newClient, &domainName),
+		getRecordCmd(cfg, newClient, &domainName),
+		createRecordCmd(cfg, newClient, &domainName),
+		updateRecordCmd(cfg, newClient, &domainName),
+		deleteRecordCmd(cfg, newClient, &domainName),
+	)
+
+	cmd.PersistentFlags().StringVar(&domainName, "domain", "", "Domain name")
+	cmd.MarkPersistentFlagRequired("domain")
+
+	return cmd
+}
+
+func listRecordsCmd(cfg *config.Config, newClient func(*config.Config) api.DomainAPI, domainName *string) *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List the DNS records of a domain",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client := newClient(cfg)
+			records, err := client.ListDomainRecords(context.Background(), *domainName, api.PageOptionsFromConfig(cfg))
+			if err != nil {
+				logging.Debug("failed to list domain records", "domain", *domainName, "err", err)
+				return err
+			}
+
+			return output.Fprint(cmd.OutOrStdout(), records, output.FromConfig(cfg))
+		},
+	}
+}
+
+func getRecordCmd(cfg *config.Config, newClient func(*config.Config) api.DomainAPI, domainName *string) *cobra.Command {
+	return &cobra.Command{
+		Use:   "get [record_id]",
+		Short: "Show a DNS record",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			id, err := parseRecordID(args[0])
+			if err != nil {
+				return err
+			}
+
+			client := newClient(cfg)
+			record, err := client.GetDomainRecord(context.Background(), *domainName, id)
+			if err != nil {
+				logging.Debug("failed to get domain record", "domain", *domainName, "id", id, "err", err)
+				return err
+			}
+
+			return output.Fprint(cmd.OutOrStdout(), record, output.FromConfig(cfg))
+		},
+	}
+}
+
+func createRecordCmd(cfg *config.Config, newClient func(*config.Config) api.DomainAPI, domainName *string) *cobra.Command {
+	var flags recordFlags
+
+	cmd := &cobra.Command{
+		Use:   "create",
+		Short: "Create a DNS record",
+		Example: `  digitalocean-cli domain record create --domain example.com --type A --name www --data 198.51.100.7
+  digitalocean-cli domain record create --domain example.com --type MX --name @ --data mail.example.com. --priority 10
+  digitalocean-cli domain record create --domain example.com --type SRV --name _sip._tcp --data sip.example.com. --priority 10 --weight 5 --port 5060
+  digitalocean-cli domain record create --domain example.com --type CAA --name @ --data letsencrypt.org --tag issue`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			req := &godo.DomainRecordEditRequest{}
+			flags.apply(cmd.Flags(), req)
+			if err := validateRecord(req); err != nil {
+				return err
+			}
+
+			client := newClient(cfg)
+			record, err := client.CreateDomainRecord(context.Background(), *domainName, req)
+			if err != nil {
+				logging.Debug("failed to create domain record", "domain", *domainName, "err", err)
+				return err
+			}
+
+			return output.Fprint(cmd.OutOrStdout(), record, output.FromConfig(cfg))
+		},
+	}
+
+	flags.register(cmd.Flags(), true)
+	cmd.MarkFlagRequired("type")
+	cmd.MarkFlagRequired("name")
+	cmd.MarkFlagRequired("data")
+
+	return cmd
+}
+
+func updateRecordCmd(cfg *config.Config, newClient func(*config.Config) api.DomainAPI, domainName *string) *cobra.Command {
+	var flags recordFlags
+
+	cmd := &cobra.Command{
+		Use:   "update [record_id]",
+		Short: "Update a DNS record",
+		Long: `Update a DNS record. Fields without a flag keep their current value. The
+type of a record cannot be changed; delete and recreate it instead.`,
+		Example: `  digitalocean-cli domain record update 3352896 --domain example.com --data 198.51.100.8 --ttl 300`,
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			id, err := parseRecordID(args[0])
+			if err != nil {
+				return err
+			}
+
+			ctx := context.Background()
+			client := newClient(cfg)
+			current, err := client.GetDomainRecord(ctx, *domainName, id)
+			if err != nil {
+				logging.Debug("failed to get domain record", "domain", *domainName, "id", id, "err", err)
+				return err
+			}
+
+			req := editRequest(current)
+			flags.apply(cmd.Flags(), req)
+			if err := validateRecord(req); err != nil {
+				return err
+			}
+
+			record, err := client.UpdateDomainRecord(ctx, *domainName, id, req)
+			if err != nil {
+				logging.Debug("failed to update domain record", "domain", *domainName, "id", id, "err", err)
+				return err
+			}
+
+			return output.Fprint(cmd.OutOrStdout(), record, output.FromConfig(cfg))
+		},
+	}
+
+	flags.register(cmd.Flags(), false)
+
+	return cmd
+}
+
+func deleteRecordCmd(cfg *config.Config, newClient func(*config.Config) api.DomainAPI, domainName *string) *cobra.Command {
+	return &cobra.Command{
+		Use:   "delete [record_id]",
+		Short: "Delete a DNS record",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			id, err := parseRecordID(args[0])
+			if err != nil {
+				return err
+			}
+
+			client := newClient(cfg)
+			err = client.DeleteDomainRecord(context.Background(), *domainName, id)
+			if err != nil {
+				logging.Debug("failed to delete domain record", "domain", *domainName, "id", id, "err", err)
+				return err
+			}
+
+			fmt.Fprintf(cmd.OutOrStdout(), "Record %d deleted from domain %s\n", id, *domainName)
+			return nil
+		},
+	}
+}
+
+func parseRecordID(arg string) (int, error) {
+	id, err := strconv.Atoi(arg)
+	if err != nil || id <= 0 {
+		return 0, fmt.Errorf("invalid record ID %q", arg)
+	}
+	return id, nil
+}
+
+// recordFlags are the record fields settable from the command line.
+type recordFlags struct {
+	godo.DomainRecordEditRequest
+}
+
+// register adds a flag per record field. The type can only be set when
+// creating a record.
+func (f *recordFlags) register(flags *pflag.FlagSet, withType bool) {
+	if withType {
+		flags.StringVar(&f.Type, "type", "", "Record type: "+strings.Join(recordTypes, ", "))
+	}
+	flags.StringVar(&f.Name, "name", "", "Record name relative to the domain, or @ for the domain itself")
+	flags.StringVar(&f.Data, "data", "", "Record data, e.g. an IP address, a hostname or text")
+	flags.IntVar(&f.TTL, "ttl", 0, "Time to live in seconds (default 1800 for new records)")
+	flags.IntVar(&f.Priority, "priority", 0, "Priority of MX and SRV records")
+	flags.IntVar(&f.Port, "port", 0, "Port of SRV records")
+	flags.IntVar(&f.Weight, "weight", 0, "Weight of SRV records")
+	flags.IntVar(&f.Flags, "flags", 0, "Flags of CAA records (0-255)")
+	flags.StringVar(&f.Tag, "tag", "", "Tag of CAA records: "+strings.Join(caaTags, ", "))
+}
+
+// apply copies the fields whose flags were set onto req.
+func (f *recordFlags) apply(flags *pflag.FlagSet, req *godo.DomainRecordEditRequest) {
+	fields := map[string]func(){
+		"type":     func() { req.Type = f.Type },
+		"name":     func() { req.Name = f.Name },
+		"data":     func() { req.Data = f.Data },
+		"ttl":      func() { req.TTL = f.TTL },
+		"priority": func() { req.Priority = f.Priority },
+		"port":     func() { req.Port = f.Port },
+		"weight":   func() { req.Weight = f.Weight },
+		"flags":    func() { req.Flags = f.Flags },
+		"tag":      func() { req.Tag = f.Tag },
+	}
+	for name, set := range fields {
+		if flags.Changed(name) {
+			set()
+		}
+	}
+	req.Type = strings.ToUpper(req.Type)
+}
+
+func editRequest(r *godo.DomainRecord) *godo.DomainRecordEditRequest {
+	return &godo.DomainRecordEditRequest{
+		Type:     r.Type,
+		Name:     r.Name,
+		Data:     r.Data,
+		Priority: r.Priority,
+		Port:     r.Port,
+		TTL:      r.TTL,
+		Weight:   r.Weight,
+		Flags:    r.Flags,
+		Tag:      r.Tag,
+	}
+}
+
+// validateRecord checks a record against the rules of its type before it
+// is sent, so that mistakes get a clearer message than the API's.
+func validateRecord(r *godo.DomainRecordEditRequest) error {
+	if !contains(recordTypes, r.Type) {
+		return fmt.Errorf("unsupported record type %q: expected one of %s", r.Type, strings.Join(recordTypes, ", "))
+	}
+	if r.Name == "" {
+		return fmt.Errorf("%s record needs a name; use @ for the domain itself", r.Type)
+	}
+	if r.Data == "" {
+		return fmt.Errorf("%s record needs data", r.Type)
+	}
+	if r.TTL != 0 && r.TTL < minTTL {
+		return fmt.Errorf("TTL must be at least %d seconds, got %d", minTTL, r.TTL)
+	}
+
+	usesPriority := r.Type == "MX" || r.Type == "SRV"
+	if r.Priority != 0 && !usesPriority {
+		return fmt.Errorf("priority only applies to MX and SRV records, not %s", r.Type)
+	}
+	if (r.Port != 0 || r.Weight != 0) && r.Type != "SRV" {
+		return fmt.Errorf("port and weight only apply to SRV records, not %s", r.Type)
+	}
+	if (r.Flags != 0 || r.Tag != "") && r.Type != "CAA" {
+		return fmt.Errorf("flags and tag only apply to CAA records, not %s", r.Type)
+	}
+	if usesPriority && !inRange(r.Priority, 0, 65535) {
+		return fmt.Errorf("priority must be between 0 and 65535, got %d", r.Priority)
+	}
+
+	switch r.Type {
+	case "A":
+		if ip := net.ParseIP(r.Data); ip == nil || ip.To4() == nil {
+			return fmt.Errorf("A record data must be an IPv4 address, got %q", r.Data)
+		}
+	case "AAAA":
+		if ip := net.ParseIP(r.Data); ip == nil || ip.To4() != nil {
+			return fmt.Errorf("AAAA record data must be an IPv6 address, got %q", r.Data)
+		}
+	case "CNAME", "NS", "MX":
+		if !validHostname(r.Data) {
+			return fmt.Errorf("%s record data must be a hostname, got %q", r.Type, r.Data)
+		}
+	case "SRV":
+		if !strings.HasPrefix(r.Name, "_") {
+			return fmt.Errorf("SRV record name must start with the service, e.g. _sip._tcp, got %q", r.Name)
+		}
+		if !validHostname(r.Data) {
+			return fmt.Errorf("SRV record data must be the target hostname, got %q", r.Data)
+		}
+		if !inRange(r.Port, 1, 65535) {
+			return fmt.Errorf("SRV record needs a port between 1 and 65535, got %d", r.Port)
+		}
+		if !inRange(r.Weight, 0, 65535) {
+			return fmt.Errorf("weight must be between 0 and 65535, got %d", r.Weight)
+		}
+	case "CAA":
+		if !contains(caaTags, r.Tag) {
+			return fmt.Errorf("CAA record needs a tag: expected one of %s, got %q", strings.Join(caaTags, ", "), r.Tag)
+		}
+		if !inRange(r.Flags, 0, 255) {
+			return fmt.Errorf("CAA flags must be between 0 and 255, got %d", r.Flags)
+		}
+	}
+	return nil
+}
+
+// validHostname accepts @, relative names and fully qualified names ending
+// in a dot.
+func validHostname(name string) bool {
+	if name == "@" {
+		return true
+	}
+	name = strings.TrimSuffix(name, ".")
+	if name == "" || len(name) > 253 {
+		return false
+	}
+	for _, label := range strings.Split(name, ".") {
+		if label == "" || len(label) > 63 || strings.HasPrefix(label, "-") || strings.HasSuffix(label, "-") {
+			return false
+		}
+		for _, c := range label {
+			if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '-' || c == '_') {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func inRange(n, min, max int) bool {
+	return n >= min && n <= max
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
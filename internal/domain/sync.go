@@ -0,0 +1,332 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/digitalocean/godo"
+	"github.com/felipepimentel/digitalocean-go/internal/api"
+	"github.com/felipepimentel/digitalocean-go/internal/config"
+	"github.com/felipepimentel/digitalocean-go/internal/logging"
+	"github.com/felipepimentel/digitalocean-go/internal/prompt"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v2"
+)
+
+// recordSet is the file read by domain sync.
+type recordSet struct {
+	Domain  string       `yaml:"domain"`
+	Records []recordSpec `yaml:"records"`
+}
+
+type recordSpec struct {
+	Type     string `yaml:"type"`
+	Name     string `yaml:"name"`
+	Data     string `yaml:"data"`
+	TTL      int    `yaml:"ttl"`
+	Priority int    `yaml:"priority"`
+	Port     int    `yaml:"port"`
+	Weight   int    `yaml:"weight"`
+	Flags    int    `yaml:"flags"`
+	Tag      string `yaml:"tag"`
+}
+
+// syncOptions select which existing records a sync may change.
+type syncOptions struct {
+	// prune deletes records missing from the desired set.
+	prune bool
+	// protectNS leaves existing NS records alone. The SOA record and the
+	// domain's own name servers are always left alone.
+	protectNS bool
+}
+
+type recordUpdate struct {
+	current godo.DomainRecord
+	desired godo.DomainRecord
+}
+
+// syncPlan lists the changes that bring a domain to the desired records.
+type syncPlan struct {
+	create    []godo.DomainRecord
+	update    []recordUpdate
+	delete    []godo.DomainRecord
+	unchanged int
+	// protected counts records that differ from the desired set but are
+	// left alone.
+	protected int
+}
+
+func (p syncPlan) empty() bool {
+	return len(p.create) == 0 && len(p.update) == 0 && len(p.delete) == 0
+}
+
+func syncCmd(cfg *config.Config, newClient func(*config.Config) api.DomainAPI) *cobra.Command {
+	var file string
+	var autoApprove, dryRun bool
+	opts := syncOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "sync [domain_name] -f records.yaml",
+		Short: "Make the DNS records of a domain match a file",
+		Long: `Make the DNS records of a domain match the records listed in a YAML file.
+
+Records are matched on type, name and data. Matched records whose other
+fields differ are updated, missing records are created and, unless
+--prune=false, records missing from the file are deleted. Fields left out of
+the file, such as ttl, keep their current value on updated records.
+
+The plan is printed and only applied after confirmation, or with
+--auto-approve. NS records are protected unless --protect-ns=false; the SOA
+record and the domain's own name servers are never changed.
+
+The domain can also be given as "domain:" in the file:
+
+  domain: example.com
+  records:
+    - {type: A, name: www, data: 198.51.100.7, ttl: 300}
+    - {type: MX, name: "@", data: mail.example.com., priority: 10}`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if file == "-" && !autoApprove && !dryRun {
+				return errors.New("reading records from stdin leaves no way to confirm: pass --auto-approve or --dry-run")
+			}
+			set, err := readRecordSet(cmd.InOrStdin(), file)
+			if err != nil {
+				return err
+			}
+			name := set.Domain
+			if len(args) > 0 {
+				if name != "" && !strings.EqualFold(name, args[0]) {
+					return fmt.Errorf("%s is for domain %s, not %s", file, name, args[0])
+				}
+				name = args[0]
+			}
+			if name == "" {
+				return fmt.Errorf("no domain given: pass it as an argument or set domain in %s", file)
+			}
+
+			desired, err := desiredRecords(set, name)
+			if err != nil {
+				return fmt.Errorf("%s: %w", file, err)
+			}
+
+			ctx := context.Background()
+			client := newClient(cfg)
+			existing, err := client.ListDomainRecords(ctx, name, api.PageOptions{})
+			if err != nil {
+				logging.Debug("failed to list domain records", "domain", name, "err", err)
+				return err
+			}
+
+			plan := planSync(existing, desired, opts)
+			out := cmd.OutOrStdout()
+			printPlan(out, name, plan)
+			if plan.empty() || dryRun {
+				return nil
+			}
+
+			if !autoApprove {
+				ok, err := prompt.Confirm(cmd, "Apply these changes?")
+				if err != nil {
+					return err
+				}
+				if !ok {
+					return errors.New("sync cancelled")
+				}
+			}
+			return applyPlan(ctx, out, client, name, plan)
+		},
+	}
+
+	cmd.Flags().StringVarP(&file, "file", "f", "", "YAML file with the desired records (- for stdin)")
+	cmd.Flags().BoolVar(&autoApprove, "auto-approve", false, "Apply the plan without asking for confirmation")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Only print the plan")
+	cmd.Flags().BoolVar(&opts.prune, "prune", true, "Delete records missing from the file")
+	cmd.Flags().BoolVar(&opts.protectNS, "protect-ns", true, "Never update or delete existing NS records")
+	cmd.MarkFlagRequired("file")
+
+	return cmd
+}
+
+func readRecordSet(stdin io.Reader, path string) (*recordSet, error) {
+	var data []byte
+	var err error
+	if path == "-" {
+		data, err = io.ReadAll(stdin)
+	} else {
+		data, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var set recordSet
+	if err := yaml.UnmarshalStrict(data, &set); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return &set, nil
+}
+
+// desiredRecords normalises and validates the records of set. Records
+// managed by DigitalOcean are dropped.
+func desiredRecords(set *recordSet, domain string) ([]godo.DomainRecord, error) {
+	var records []godo.DomainRecord
+	seen := map[string]bool{}
+	for i, spec := range set.Records {
+		r := godo.DomainRecord{
+			Type:     strings.ToUpper(spec.Type),
+			Name:     relativeName(spec.Name, domain),
+			Data:     spec.Data,
+			TTL:      spec.TTL,
+			Priority: spec.Priority,
+			Port:     spec.Port,
+			Weight:   spec.Weight,
+			Flags:    spec.Flags,
+			Tag:      spec.Tag,
+		}
+		if isManaged(r) {
+			continue
+		}
+		if err := validateRecord(editRequest(&r)); err != nil {
+			return nil, fmt.Errorf("record %d (%s): %w", i+1, describeRecord(r), err)
+		}
+		if seen[recordKey(r)] {
+			return nil, fmt.Errorf("record %d (%s) is listed twice", i+1, describeRecord(r))
+		}
+		seen[recordKey(r)] = true
+		records = append(records, r)
+	}
+	return records, nil
+}
+
+// relativeName makes fully qualified names relative to domain.
+func relativeName(name, domain string) string {
+	if !strings.HasSuffix(name, ".") {
+		return name
+	}
+	name = strings.TrimSuffix(strings.ToLower(name), ".")
+	domain = strings.ToLower(strings.TrimSuffix(domain, "."))
+	if name == domain {
+		return "@"
+	}
+	return strings.TrimSuffix(name, "."+domain)
+}
+
+// recordKey identifies a record for matching: its type, name and data.
+// Target hostnames match with or without a trailing dot.
+func recordKey(r godo.DomainRecord) string {
+	r = trimTarget(r)
+	return strings.Join([]string{r.Type, strings.ToLower(r.Name), r.Data}, "\x00")
+}
+
+// planSync works out how to turn existing into desired.
+func planSync(existing, desired []godo.DomainRecord, opts syncOptions) syncPlan {
+	byKey := map[string][]int{}
+	for i, r := range existing {
+		byKey[recordKey(r)] = append(byKey[recordKey(r)], i)
+	}
+	matched := make([]bool, len(existing))
+
+	var plan syncPlan
+	for _, d := range desired {
+		candidates := byKey[recordKey(d)]
+		if len(candidates) == 0 {
+			plan.create = append(plan.create, d)
+			continue
+		}
+		i := candidates[0]
+		byKey[recordKey(d)] = candidates[1:]
+		matched[i] = true
+
+		current := existing[i]
+		merged := current
+		merged.Priority, merged.Port, merged.Weight = d.Priority, d.Port, d.Weight
+		merged.Flags, merged.Tag = d.Flags, d.Tag
+		if d.TTL != 0 {
+			merged.TTL = d.TTL
+		}
+		switch {
+		case trimTarget(merged) == trimTarget(current):
+			plan.unchanged++
+		case protected(current, opts):
+			plan.protected++
+		default:
+			plan.update = append(plan.update, recordUpdate{current: current, desired: merged})
+		}
+	}
+
+	for i, r := range existing {
+		switch {
+		case matched[i] || isManaged(r):
+		case !opts.prune:
+		case protected(r, opts):
+			plan.protected++
+		default:
+			plan.delete = append(plan.delete, r)
+		}
+	}
+	return plan
+}
+
+func protected(r godo.DomainRecord, opts syncOptions) bool {
+	return isManaged(r) || (opts.protectNS && r.Type == "NS")
+}
+
+func printPlan(w io.Writer, domain string, plan syncPlan) {
+	for _, r := range plan.create {
+		fmt.Fprintf(w, "+ %s\n", describeRecord(r))
+	}
+	for _, u := range plan.update {
+		fmt.Fprintf(w, "~ %s\n    => %s\n", describeRecord(u.current), describeRecord(u.desired))
+	}
+	for _, r := range plan.delete {
+		fmt.Fprintf(w, "- %s\n", describeRecord(r))
+	}
+
+	if plan.empty() {
+		fmt.Fprintf(w, "%s is in sync (%d records unchanged)\n", domain, plan.unchanged)
+	} else {
+		fmt.Fprintf(w, "Plan for %s: %d to create, %d to update, %d to delete, %d unchanged\n",
+			domain, len(plan.create), len(plan.update), len(plan.delete), plan.unchanged)
+	}
+	if plan.protected > 0 {
+		fmt.Fprintf(w, "%d protected NS records left alone; pass --protect-ns=false to change them\n", plan.protected)
+	}
+}
+
+// applyPlan updates, then creates, then deletes, so that records are only
+// removed once their replacements exist.
+func applyPlan(ctx context.Context, w io.Writer, client api.DomainAPI, domain string, plan syncPlan) error {
+	var created, updated, deleted int
+	fail := func(action string, r godo.DomainRecord, err error) error {
+		logging.Debug("failed to sync domain record", "domain", domain, "action", action, "err", err)
+		return fmt.Errorf("failed to %s %s after %d created, %d updated and %d deleted: %w",
+			action, describeRecord(r), created, updated, deleted, err)
+	}
+
+	for _, u := range plan.update {
+		if _, err := client.UpdateDomainRecord(ctx, domain, u.current.ID, editRequest(&u.desired)); err != nil {
+			return fail("update", u.current, err)
+		}
+		updated++
+	}
+	for _, r := range plan.create {
+		if _, err := client.CreateDomainRecord(ctx, domain, editRequest(&r)); err != nil {
+			return fail("create", r, err)
+		}
+		created++
+	}
+	for _, r := range plan.delete {
+		if err := client.DeleteDomainRecord(ctx, domain, r.ID); err != nil {
+			return fail("delete", r, err)
+		}
+		deleted++
+	}
+
+	fmt.Fprintf(w, "Applied: %d created, %d updated, %d deleted\n", created, updated, deleted)
+	return nil
+}
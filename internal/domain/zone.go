@@ -0,0 +1,201 @@
+package domain
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/digitalocean/godo"
+	"github.com/felipepimentel/digitalocean-go/internal/api"
+	"github.com/felipepimentel/digitalocean-go/internal/atomicfile"
+	"github.com/felipepimentel/digitalocean-go/internal/config"
+	"github.com/felipepimentel/digitalocean-go/internal/logging"
+	"github.com/felipepimentel/digitalocean-go/internal/zonefile"
+	"github.com/spf13/cobra"
+)
+
+const formatBIND = "bind"
+
+func exportCmd(cfg *config.Config, newClient func(*config.Config) api.DomainAPI) *cobra.Command {
+	var format, file string
+
+	cmd := &cobra.Command{
+		Use:   "export [domain_name]",
+		Short: "Export the DNS records of a domain as a zone file",
+		Long: `Export the DNS records of a domain as an RFC 1035 (BIND) zone file, to
+stdout or to --file. The file is readable by you only and replaced
+atomically.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if format != formatBIND {
+				return fmt.Errorf("unsupported format %q: only %s is supported", format, formatBIND)
+			}
+
+			client := newClient(cfg)
+			records, err := client.ListDomainRecords(context.Background(), args[0], api.PageOptions{})
+			if err != nil {
+				logging.Debug("failed to list domain records", "domain", args[0], "err", err)
+				return err
+			}
+
+			if file == "" {
+				return zonefile.Write(cmd.OutOrStdout(), args[0], records)
+			}
+			var buf bytes.Buffer
+			if err := zonefile.Write(&buf, args[0], records); err != nil {
+				return err
+			}
+			if err := atomicfile.Write(file, buf.Bytes()); err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Exported %d records of %s to %s\n", len(records), args[0], file)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&format, "format", formatBIND, "Zone file format (only bind is supported)")
+	cmd.Flags().StringVarP(&file, "file", "f", "", "Write the zone file here instead of to stdout")
+
+	return cmd
+}
+
+func importCmd(cfg *config.Config, newClient func(*config.Config) api.DomainAPI) *cobra.Command {
+	var dryRun bool
+
+	cmd := &cobra.Command{
+		Use:   "import [domain_name] [zone_file]",
+		Short: "Create DNS records from a zone file",
+		Long: `Create the records of an RFC 1035 (BIND) zone file in an existing domain.
+Use - to read the zone file from stdin.
+
+The SOA record and the name servers of the domain itself are managed by
+DigitalOcean and skipped. Records the domain already has are left alone, so
+an import can be repeated after a partial failure. Every record is validated
+before any is created.`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			name := args[0]
+			records, err := readZone(cmd.InOrStdin(), args[1], name)
+			if err != nil {
+				return err
+			}
+
+			ctx := context.Background()
+			client := newClient(cfg)
+			existing, err := client.ListDomainRecords(ctx, name, api.PageOptions{})
+			if err != nil {
+				logging.Debug("failed to list domain records", "domain", name, "err", err)
+				return err
+			}
+
+			var create []godo.DomainRecord
+			var managed, present int
+			for _, r := range records {
+				if isManaged(r) {
+					managed++
+					continue
+				}
+				if err := validateRecord(editRequest(&r)); err != nil {
+					return fmt.Errorf("%s: %w", describeRecord(r), err)
+				}
+				if containsRecord(existing, r) {
+					present++
+					continue
+				}
+				create = append(create, r)
+			}
+
+			out := cmd.OutOrStdout()
+			if dryRun {
+				for _, r := range create {
+					fmt.Fprintf(out, "+ %s\n", describeRecord(r))
+				}
+				fmt.Fprintf(out, "Would import %d records into %s (%d already present, %d managed by DigitalOcean skipped)\n", len(create), name, present, managed)
+				return nil
+			}
+
+			for i, r := range create {
+				if _, err := client.CreateDomainRecord(ctx, name, editRequest(&r)); err != nil {
+					logging.Debug("failed to create domain record", "domain", name, "err", err)
+					return fmt.Errorf("imported %d of %d records, failed on %s: %w", i, len(create), describeRecord(r), err)
+				}
+			}
+			fmt.Fprintf(out, "Imported %d records into %s (%d already present, %d managed by DigitalOcean skipped)\n", len(create), name, present, managed)
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Show the records that would be created without creating them")
+
+	return cmd
+}
+
+func readZone(stdin io.Reader, path, domain string) ([]godo.DomainRecord, error) {
+	r := stdin
+	if path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		r = f
+	}
+
+	records, err := zonefile.Parse(r, domain)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return records, nil
+}
+
+// isManaged reports whether DigitalOcean maintains r itself: the SOA record
+// and the name servers of the domain.
+func isManaged(r godo.DomainRecord) bool {
+	return r.Type == "SOA" || (r.Type == "NS" && r.Name == "@")
+}
+
+// containsRecord reports whether records holds r, ignoring IDs, and TTLs
+// when r has none. Target hostnames match with or without a trailing dot,
+// as zone files qualify them and DigitalOcean returns them without one.
+func containsRecord(records []godo.DomainRecord, r godo.DomainRecord) bool {
+	r = trimTarget(r)
+	for _, existing := range records {
+		existing = trimTarget(existing)
+		existing.ID = r.ID
+		if r.TTL == 0 {
+			existing.TTL = 0
+		}
+		if existing == r {
+			return true
+		}
+	}
+	return false
+}
+
+// trimTarget drops the trailing dot of r's target hostname.
+func trimTarget(r godo.DomainRecord) godo.DomainRecord {
+	switch r.Type {
+	case "CNAME", "NS", "MX", "SRV":
+		r.Data = strings.TrimSuffix(r.Data, ".")
+	}
+	return r
+}
+
+func describeRecord(r godo.DomainRecord) string {
+	s := fmt.Sprintf("%s %s %q", r.Type, r.Name, r.Data)
+	switch r.Type {
+	case "MX":
+		s += fmt.Sprintf(" priority=%d", r.Priority)
+	case "SRV":
+		s += fmt.Sprintf(" priority=%d weight=%d port=%d", r.Priority, r.Weight, r.Port)
+	case "CAA":
+		s += fmt.Sprintf(" flags=%d tag=%s", r.Flags, r.Tag)
+	}
+	if r.TTL != 0 {
+		s += fmt.Sprintf(" ttl=%d", r.TTL)
+	}
+	return s
+}
@@ -0,0 +1,337 @@
+package droplet
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/digitalocean/godo"
+	"github.com/felipepimentel/digitalocean-go/internal/api"
+	"github.com/felipepimentel/digitalocean-go/internal/config"
+	"github.com/felipepimentel/digitalocean-go/internal/logging"
+	"github.com/felipepimentel/digitalocean-go/internal/output"
+	"github.com/felipepimentel/digitalocean-go/internal/progress"
+	"github.com/felipepimentel/digitalocean-go/internal/prompt"
+	"github.com/spf13/cobra"
+)
+
+// actionCmds are the droplet power and lifecycle actions.
+func actionCmds(cfg *config.Config, newClient func(*config.Config) api.DropletAPI) []*cobra.Command {
+	simple := []struct {
+		use, short, actionType string
+	}{
+		{"power-on", "Power droplets on", api.ActionPowerOn},
+		{"power-off", "Power droplets off at once, like pulling the plug", api.ActionPowerOff},
+		{"shutdown", "Shut droplets down gracefully", api.ActionShutdown},
+		{"reboot", "Reboot droplets gracefully", api.ActionReboot},
+		{"power-cycle", "Power droplets off and back on at once", api.ActionPowerCycle},
+		{"enable-ipv6", "Enable IPv6 networking on droplets", api.ActionEnableIPv6},
+		{"enable-backups", "Enable automated backups of droplets", api.ActionEnableBackups},
+		{"password-reset", "Reset the root password of droplets and email the new one", api.ActionPasswordReset},
+	}
+
+	var cmds []*cobra.Command
+	for _, a := range simple {
+		req := api.DropletActionRequest{Type: a.actionType}
+		cmds = append(cmds, actionCmd(cfg, newClient, a.use, a.short, func(droplets []godo.Droplet) ([]api.DropletActionRequest, error) {
+			reqs := make([]api.DropletActionRequest, len(droplets))
+			for i := range reqs {
+				reqs[i] = req
+			}
+			return reqs, nil
+		}))
+	}
+	return append(cmds, rebuildCmd(cfg, newClient), renameCmd(cfg, newClient))
+}
+
+// actionCmd builds a command that selects droplets by ID, name or tag,
+// takes the action requests returns on each and prints the actions.
+func actionCmd(cfg *config.Config, newClient func(*config.Config) api.DropletAPI, use, short string,
+	requests func(droplets []godo.Droplet) ([]api.DropletActionRequest, error)) *cobra.Command {
+	var tags []string
+	var wait progress.WaitFlags
+
+	cmd := &cobra.Command{
+		Use:   use + " [droplet_id_or_name ...]",
+		Short: short,
+		Long: short + `.
+
+Droplets are selected by ID or name, and with --tag by tag. The action is
+taken on every selected droplet, even if it fails on some of them, and the
+actions are printed.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 0 && len(tags) == 0 {
+				return fmt.Errorf("select droplets by ID, name or --tag")
+			}
+
+			ctx := context.Background()
+			client := newClient(refreshed(cfg))
+			droplets, err := selectDroplets(ctx, client, args, tags)
+			if err != nil {
+				return err
+			}
+			reqs, err := requests(droplets)
+			if err != nil {
+				return err
+			}
+
+			var actions []godo.Action
+			var failures []string
+			for i, d := range droplets {
+				action, err := client.RunDropletAction(ctx, d.ID, reqs[i])
+				if err != nil {
+					logging.Debug("failed to run droplet action", "id", d.ID, "action", reqs[i].Type, "err", err)
+					failures = append(failures, fmt.Sprintf("%s (%d): %v", d.Name, d.ID, err))
+					continue
+				}
+				actions = append(actions, *action)
+			}
+
+			// A failed wait still prints the actions, as they were taken,
+			// with their last known status.
+			var waitErr error
+			if len(actions) > 0 && wait.Wait {
+				checks := make([]api.Check, len(actions))
+				for i, a := range actions {
+					checks[i] = api.ActionDone(client, a.ID)
+				}
+				waitErr = wait.Run(cmd, fmt.Sprintf("Waiting for %d %s actions", len(actions), use), api.All(checks...))
+				if err := refreshActions(ctx, client, actions); waitErr == nil {
+					waitErr = err
+				}
+			}
+
+			if len(actions) > 0 {
+				if err := output.Fprint(cmd.OutOrStdout(), actions, output.FromConfig(cfg)); err != nil {
+					return err
+				}
+			}
+			if len(failures) > 0 {
+				err := fmt.Errorf("%s failed on %d of %d droplets: %s", use, len(failures), len(droplets), strings.Join(failures, "; "))
+				if waitErr != nil {
+					return fmt.Errorf("%w; %v", waitErr, err)
+				}
+				return err
+			}
+			return waitErr
+		},
+	}
+
+	cmd.Flags().StringSliceVar(&tags, "tag", nil, "Select the droplets with this tag")
+	wait.Register(cmd.Flags(), "the actions complete")
+
+	return cmd
+}
+
+func rebuildCmd(cfg *config.Config, newClient func(*config.Config) api.DropletAPI) *cobra.Command {
+	var image string
+	var yes bool
+
+	var cmd *cobra.Command
+	cmd = actionCmd(cfg, newClient, "rebuild", "Rebuild droplets from an image, erasing their disks", func(droplets []godo.Droplet) ([]api.DropletActionRequest, error) {
+		reqs := make([]api.DropletActionRequest, len(droplets))
+		names := make([]string, len(droplets))
+		for i, d := range droplets {
+			names[i] = fmt.Sprintf("%s (%d)", d.Name, d.ID)
+			reqs[i] = api.DropletActionRequest{Type: api.ActionRebuild, Image: parseImage(image)}
+			if image != "" {
+				continue
+			}
+			if d.Image == nil || (d.Image.ID == 0 && d.Image.Slug == "") {
+				return nil, fmt.Errorf("droplet %s (%d) has no image to rebuild from: pass --image", d.Name, d.ID)
+			}
+			reqs[i].Image = godo.DropletCreateImage{ID: d.Image.ID, Slug: d.Image.Slug}
+		}
+
+		fmt.Fprintf(cmd.ErrOrStderr(), "Warning: rebuilding %s erases their disks. Everything on them is lost.\n", strings.Join(names, ", "))
+		if !yes {
+			ok, err := prompt.Confirm(cmd, "Rebuild the droplets?")
+			if err != nil {
+				return nil, err
+			}
+			if !ok {
+				return nil, errors.New("rebuild cancelled")
+			}
+		}
+		return reqs, nil
+	})
+	cmd.Long += `
+
+The disks of the droplets are erased, so rebuild asks for confirmation unless
+given --yes.`
+	cmd.Flags().StringVarP(&image, "image", "i", "", "Image slug or ID to rebuild from (defaults to each droplet's current image)")
+	cmd.Flags().BoolVarP(&yes, "yes", "y", false, "Rebuild without asking for confirmation")
+
+	return cmd
+}
+
+// renameData is what the rename --to template is executed with.
+type renameData struct {
+	ID   int
+	Name string
+	// Index counts the selected droplets from 1.
+	Index int
+	Count int
+}
+
+func renameCmd(cfg *config.Config, newClient func(*config.Config) api.DropletAPI) *cobra.Command {
+	var to string
+
+	cmd := actionCmd(cfg, newClient, "rename", "Rename droplets", func(droplets []godo.Droplet) ([]api.DropletActionRequest, error) {
+		tmpl, err := template.New("name").Parse(to)
+		if err != nil {
+			return nil, fmt.Errorf("invalid name template %q: %w", to, err)
+		}
+
+		reqs := make([]api.DropletActionRequest, len(droplets))
+		seen := map[string]bool{}
+		for i, d := range droplets {
+			var b strings.Builder
+			if err := tmpl.Execute(&b, renameData{ID: d.ID, Name: d.Name, Index: i + 1, Count: len(droplets)}); err != nil {
+				return nil, fmt.Errorf("invalid name template %q: %w", to, err)
+			}
+			name := b.String()
+			if len(name) > 255 || !namePattern.MatchString(name) {
+				return nil, fmt.Errorf("invalid droplet name %q: use letters, digits, dashes and dots", name)
+			}
+			if seen[name] {
+				return nil, fmt.Errorf("droplet name %q is used twice: use a template such as web-{{.Index}} to rename several droplets", name)
+			}
+			seen[name] = true
+			reqs[i] = api.DropletActionRequest{Type: api.ActionRename, Name: name}
+		}
+		return reqs, nil
+	})
+	cmd.Long += `
+
+--to is a template executed for each droplet with its .ID, its current .Name,
+its .Index among the selected droplets, counting from 1, and the .Count of
+droplets, e.g. "{{.Name}}-old".`
+	cmd.Flags().StringVar(&to, "to", "", "New name, or a name template")
+	cmd.MarkFlagRequired("to")
+
+	return cmd
+}
+
+// refreshActions replaces actions with their current state. Those that
+// cannot be fetched keep their last known state, and the first error is
+// returned.
+func refreshActions(ctx context.Context, client api.DropletAPI, actions []godo.Action) error {
+	var first error
+	for i, a := range actions {
+		action, err := client.GetAction(ctx, a.ID)
+		if err != nil {
+			logging.Debug("failed to get action", "id", a.ID, "err", err)
+			if first == nil {
+				first = err
+			}
+			continue
+		}
+		actions[i] = *action
+	}
+	return first
+}
+
+// refreshed returns a copy of cfg whose clients refetch cached lists
+// instead of reading them, for commands that select droplets to change:
+// acting on a stale list could hit a droplet that was renamed, retagged or
+// resized since.
+func refreshed(cfg *config.Config) *config.Config {
+	fresh := *cfg
+	fresh.RefreshCache = true
+	return &fresh
+}
+
+// selectDroplets returns the droplets args, each an ID or a name, and tags
+// select, without duplicates. Commands that change the droplets build the
+// client with refreshed, so the selection is current.
+func selectDroplets(ctx context.Context, client api.DropletAPI, args, tags []string) ([]godo.Droplet, error) {
+	var all []godo.Droplet
+	list := func() ([]godo.Droplet, error) {
+		if all != nil {
+			return all, nil
+		}
+		droplets, err := client.ListDroplets(ctx, api.PageOptions{})
+		if err != nil {
+			logging.Debug("failed to list droplets", "err", err)
+			return nil, err
+		}
+		all = droplets
+		return all, nil
+	}
+
+	var selected []godo.Droplet
+	seen := map[int]bool{}
+	add := func(d godo.Droplet) {
+		if !seen[d.ID] {
+			seen[d.ID] = true
+			selected = append(selected, d)
+		}
+	}
+
+	for _, arg := range args {
+		if id, err := strconv.Atoi(arg); err == nil {
+			d, err := client.GetDroplet(ctx, id)
+			if api.IsNotFound(err) {
+				return nil, fmt.Errorf("droplet %d not found", id)
+			}
+			if err != nil {
+				logging.Debug("failed to get droplet", "id", id, "err", err)
+				return nil, err
+			}
+			add(*d)
+			continue
+		}
+
+		droplets, err := list()
+		if err != nil {
+			return nil, err
+		}
+		var ids []string
+		var match godo.Droplet
+		for _, d := range droplets {
+			if d.Name == arg {
+				ids = append(ids, strconv.Itoa(d.ID))
+				match = d
+			}
+		}
+		switch len(ids) {
+		case 0:
+			return nil, fmt.Errorf("no droplet is named %s", arg)
+		case 1:
+			add(match)
+		default:
+			return nil, fmt.Errorf("%d droplets are named %s: select one by ID (%s)", len(ids), arg, strings.Join(ids, ", "))
+		}
+	}
+
+	for _, tag := range tags {
+		droplets, err := list()
+		if err != nil {
+			return nil, err
+		}
+		found := false
+		for _, d := range droplets {
+			if contains(d.Tags, tag) {
+				add(d)
+				found = true
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("no droplet is tagged %s", tag)
+		}
+	}
+	return selected, nil
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
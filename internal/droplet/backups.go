@@ -0,0 +1,168 @@
+package droplet
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/digitalocean/godo"
+	"github.com/felipepimentel/digitalocean-go/internal/api"
+	"github.com/felipepimentel/digitalocean-go/internal/config"
+	"github.com/felipepimentel/digitalocean-go/internal/logging"
+	"github.com/felipepimentel/digitalocean-go/internal/output"
+	"github.com/felipepimentel/digitalocean-go/internal/progress"
+	"github.com/felipepimentel/digitalocean-go/internal/prompt"
+	"github.com/spf13/cobra"
+)
+
+func backupsCmd(cfg *config.Config, newClient func(*config.Config) api.DropletAPI) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "backups",
+		Short: "Manage droplet backups",
+	}
+
+	cmd.AddCommand(
+		backupsListCmd(cfg, newClient),
+		backupsRestoreCmd(cfg, newClient),
+	)
+
+	return cmd
+}
+
+func backupsListCmd(cfg *config.Config, newClient func(*config.Config) api.DropletAPI) *cobra.Command {
+	return &cobra.Command{
+		Use:   "list <droplet_id_or_name>",
+		Short: "List the backups of a droplet",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client := newClient(cfg)
+			_, backups, err := dropletBackups(context.Background(), client, args[0])
+			if err != nil {
+				return err
+			}
+			return output.Fprint(cmd.OutOrStdout(), backups, output.FromConfig(cfg))
+		},
+	}
+}
+
+func backupsRestoreCmd(cfg *config.Config, newClient func(*config.Config) api.DropletAPI) *cobra.Command {
+	var yes bool
+	var wait progress.WaitFlags
+
+	cmd := &cobra.Command{
+		Use:   "restore <droplet_id_or_name> [backup_id]",
+		Short: "Restore a droplet from one of its backups",
+		Long: `Restore a droplet from one of its backups, by default the newest.
+
+The droplet's disk is replaced by the backup, so everything written since it
+was taken is lost. It asks for confirmation unless --yes is given.`,
+		Args: cobra.RangeArgs(1, 2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			backupID := 0
+			if len(args) > 1 {
+				id, err := strconv.Atoi(args[1])
+				if err != nil {
+					return fmt.Errorf("invalid backup ID %q", args[1])
+				}
+				backupID = id
+			}
+
+			ctx := context.Background()
+			client := newClient(refreshed(cfg))
+			d, backups, err := dropletBackups(ctx, client, args[0])
+			if err != nil {
+				return err
+			}
+			if len(backups) == 0 {
+				return fmt.Errorf("droplet %s (%d) has no backups: enable them with droplet enable-backups", d.Name, d.ID)
+			}
+
+			var backup godo.Image
+			if backupID != 0 {
+				if backup, err = findBackup(backups, backupID); err != nil {
+					return fmt.Errorf("%w of droplet %s (%d)", err, d.Name, d.ID)
+				}
+			} else {
+				backup = newestBackup(backups)
+			}
+
+			fmt.Fprintf(cmd.ErrOrStderr(), "Warning: restoring %s (%d) from backup %s (%d), taken %s, replaces its disk. Everything written since is lost.\n",
+				d.Name, d.ID, backup.Name, backup.ID, backup.Created)
+			if !yes {
+				ok, err := prompt.Confirm(cmd, "Restore the droplet?")
+				if err != nil {
+					return err
+				}
+				if !ok {
+					return errors.New("restore cancelled")
+				}
+			}
+
+			action, err := client.RunDropletAction(ctx, d.ID, api.DropletActionRequest{Type: api.ActionRestore, Image: godo.DropletCreateImage{ID: backup.ID}})
+			if err != nil {
+				logging.Debug("failed to restore droplet", "id", d.ID, "backup", backup.ID, "err", err)
+				return err
+			}
+			actions := []godo.Action{*action}
+			var waitErr error
+			if wait.Wait {
+				waitErr = wait.Run(cmd, "Restoring "+d.Name, api.ActionDone(client, action.ID))
+				if err := refreshActions(ctx, client, actions); waitErr == nil {
+					waitErr = err
+				}
+			}
+			if err := output.Fprint(cmd.OutOrStdout(), actions, output.FromConfig(cfg)); err != nil {
+				return err
+			}
+			return waitErr
+		},
+	}
+
+	cmd.Flags().BoolVarP(&yes, "yes", "y", false, "Restore without asking for confirmation")
+	wait.Register(cmd.Flags(), "the droplet is restored")
+
+	return cmd
+}
+
+// dropletBackups returns the droplet arg selects and its backups.
+func dropletBackups(ctx context.Context, client api.DropletAPI, arg string) (godo.Droplet, []godo.Image, error) {
+	droplets, err := selectDroplets(ctx, client, []string{arg}, nil)
+	if err != nil {
+		return godo.Droplet{}, nil, err
+	}
+	d := droplets[0]
+	backups, err := client.ListDropletBackups(ctx, d.ID)
+	if err != nil {
+		logging.Debug("failed to list backups", "id", d.ID, "err", err)
+		return d, nil, err
+	}
+	return d, backups, nil
+}
+
+func findBackup(backups []godo.Image, id int) (godo.Image, error) {
+	ids := make([]string, len(backups))
+	for i, b := range backups {
+		if b.ID == id {
+			return b, nil
+		}
+		ids[i] = strconv.Itoa(b.ID)
+	}
+	return godo.Image{}, fmt.Errorf("%d is not one of the backups (%s)", id, strings.Join(ids, ", "))
+}
+
+// newestBackup returns the backup taken last. Unreadable creation times
+// count as oldest.
+func newestBackup(backups []godo.Image) godo.Image {
+	var newest godo.Image
+	var newestAt time.Time
+	for i, b := range backups {
+		created, _ := time.Parse(time.RFC3339, b.Created)
+		if i == 0 || created.After(newestAt) {
+			newest, newestAt = b, created
+		}
+	}
+	return newest
+}
@@ -0,0 +1,433 @@
+package droplet
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/digitalocean/godo"
+	"github.com/felipepimentel/digitalocean-go/internal/api"
+	"github.com/felipepimentel/digitalocean-go/internal/config"
+	"github.com/felipepimentel/digitalocean-go/internal/logging"
+	"github.com/felipepimentel/digitalocean-go/internal/output"
+	"github.com/felipepimentel/digitalocean-go/internal/progress"
+	"github.com/spf13/cobra"
+)
+
+// maxUserData is the largest user-data the API accepts.
+const maxUserData = 64 * 1024
+
+var (
+	namePattern        = regexp.MustCompile(`^[a-zA-Z0-9]([a-zA-Z0-9.-]*[a-zA-Z0-9])?$`)
+	tagPattern         = regexp.MustCompile(`^[a-zA-Z0-9_:-]+$`)
+	uuidPattern        = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+	fingerprintPattern = regexp.MustCompile(`^[0-9a-fA-F]{2}(:[0-9a-fA-F]{2}){15}$`)
+)
+
+// createFlags holds the flags of droplet create as given, before they are
+// turned into a request.
+type createFlags struct {
+	names                  []string
+	count                  int
+	rollback               bool
+	region, size           string
+	image, snapshot        string
+	sshKeys, tags, volumes []string
+	userDataFile, vpcUUID  string
+	ipv6, backups          bool
+	monitoring             bool
+	wait                   progress.WaitFlags
+}
+
+// nameData is what --name templates are executed with.
+type nameData struct {
+	// Index counts the droplets from 1.
+	Index int
+	Count int
+}
+
+func createCmd(cfg *config.Config, newClient func(*config.Config) api.DropletAPI) *cobra.Command {
+	var f createFlags
+
+	cmd := &cobra.Command{
+		Use:   "create",
+		Short: "Create a new droplet",
+		Long: `Create a new droplet.
+
+The image is a slug or a numeric image ID; --snapshot creates the droplet
+from a snapshot ID instead. SSH keys are given by ID or MD5 fingerprint, and
+volumes by ID. The request is validated before it is sent.
+
+Several droplets are created by repeating --name, or with --count and a name
+template such as web-{{.Index}}, where .Index counts from 1 and .Count is the
+number of droplets. They are created in batches of 10. If a batch fails, the
+droplets already created are listed, or deleted again with --rollback.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if !cmd.Flags().Changed("region") && cfg.Region != "" {
+				f.region = cfg.Region
+			}
+			if !cmd.Flags().Changed("size") && cfg.Size != "" {
+				f.size = cfg.Size
+			}
+			if cmd.Flags().Changed("image") && f.snapshot != "" {
+				return fmt.Errorf("--image and --snapshot cannot be used together")
+			}
+
+			names, err := expandNames(f.names, f.count)
+			if err != nil {
+				return err
+			}
+			req, err := f.request(cmd.InOrStdin())
+			if err != nil {
+				return err
+			}
+			if len(names) > 1 && len(req.Volumes) > 0 {
+				return fmt.Errorf("volumes can only be attached when creating a single droplet")
+			}
+			for _, name := range names {
+				req.Name = name
+				if err := validateCreate(req); err != nil {
+					return err
+				}
+			}
+
+			ctx := context.Background()
+			client := newClient(cfg)
+			if len(names) == 1 {
+				droplet, links, err := client.CreateDroplet(ctx, req)
+				if err != nil {
+					logging.Debug("failed to create droplet", "err", err)
+					return err
+				}
+				droplets, err := waitUntilActive(cmd, &f.wait, client, []godo.Droplet{*droplet}, links)
+				return printCreated(cmd, cfg, &droplets[0], err)
+			}
+
+			droplets, links, err := createBatches(ctx, client, req, names)
+			if err == nil {
+				droplets, err = waitUntilActive(cmd, &f.wait, client, droplets, links)
+				return printCreated(cmd, cfg, droplets, err)
+			}
+			if len(droplets) == 0 {
+				return err
+			}
+			if f.rollback {
+				return rollback(ctx, cmd.ErrOrStderr(), client, droplets, err)
+			}
+			return printCreated(cmd, cfg, droplets, fmt.Errorf("%w; the %d droplets listed were created and left running", err, len(droplets)))
+		},
+	}
+
+	cmd.Flags().StringArrayVarP(&f.names, "name", "n", nil, "Droplet name or name template; repeat to create several droplets")
+	cmd.Flags().IntVar(&f.count, "count", 1, "Number of droplets to create from a --name template")
+	cmd.Flags().BoolVar(&f.rollback, "rollback", false, "Delete the droplets already created if creating more fails")
+	cmd.Flags().StringVarP(&f.region, "region", "r", "nyc1", "Droplet region (defaults to the context region)")
+	cmd.Flags().StringVarP(&f.size, "size", "s", "s-1vcpu-1gb", "Droplet size (defaults to the context size)")
+	cmd.Flags().StringVarP(&f.image, "image", "i", "ubuntu-20-04-x64", "Droplet image slug or ID")
+	cmd.Flags().StringVar(&f.snapshot, "snapshot", "", "Create the droplet from this snapshot ID instead of an image")
+	cmd.Flags().StringSliceVar(&f.sshKeys, "ssh-keys", nil, "SSH key IDs or fingerprints to add to the root account")
+	cmd.Flags().StringVar(&f.userDataFile, "user-data-file", "", "File with cloud-init user data (- for stdin)")
+	cmd.Flags().StringVar(&f.vpcUUID, "vpc-uuid", "", "UUID of the VPC to place the droplet in (defaults to the region's default VPC)")
+	cmd.Flags().StringSliceVar(&f.tags, "tag", nil, "Tags to apply to the droplet")
+	cmd.Flags().StringSliceVar(&f.volumes, "volumes", nil, "IDs of block storage volumes to attach")
+	cmd.Flags().BoolVar(&f.ipv6, "enable-ipv6", false, "Enable IPv6 networking")
+	cmd.Flags().BoolVar(&f.backups, "enable-backups", false, "Enable automated backups")
+	cmd.Flags().BoolVar(&f.monitoring, "enable-monitoring", false, "Install the monitoring agent")
+
+	f.wait.Register(cmd.Flags(), "the droplets are active")
+
+	cmd.MarkFlagRequired("name")
+
+	return cmd
+}
+
+// request builds the create request, without a name, reading user data
+// from its file.
+func (f *createFlags) request(stdin io.Reader) (*godo.DropletCreateRequest, error) {
+	req := &godo.DropletCreateRequest{
+		Region:     f.region,
+		Size:       f.size,
+		Image:      parseImage(f.image),
+		Tags:       f.tags,
+		VPCUUID:    f.vpcUUID,
+		IPv6:       f.ipv6,
+		Backups:    f.backups,
+		Monitoring: f.monitoring,
+	}
+
+	if f.snapshot != "" {
+		id, err := strconv.Atoi(f.snapshot)
+		if err != nil || id <= 0 {
+			return nil, fmt.Errorf("invalid snapshot ID %q", f.snapshot)
+		}
+		req.Image = godo.DropletCreateImage{ID: id}
+	}
+
+	for _, key := range f.sshKeys {
+		sshKey, err := parseSSHKey(key)
+		if err != nil {
+			return nil, err
+		}
+		req.SSHKeys = append(req.SSHKeys, sshKey)
+	}
+	for _, id := range f.volumes {
+		req.Volumes = append(req.Volumes, godo.DropletCreateVolume{ID: id})
+	}
+
+	if f.userDataFile != "" {
+		data, err := readUserData(stdin, f.userDataFile)
+		if err != nil {
+			return nil, err
+		}
+		req.UserData = data
+	}
+	return req, nil
+}
+
+// expandNames turns the --name values into one name per droplet. A single
+// name is repeated count times, so it must be a template; each name is
+// executed as a template with its nameData.
+func expandNames(names []string, count int) ([]string, error) {
+	switch {
+	case count < 1:
+		return nil, fmt.Errorf("--count must be at least 1, got %d", count)
+	case len(names) == 1 && count > 1:
+		if !strings.Contains(names[0], "{{") {
+			return nil, fmt.Errorf("--count %d needs a name template such as %s-{{.Index}}", count, names[0])
+		}
+		for len(names) < count {
+			names = append(names, names[0])
+		}
+	case count > 1 && count != len(names):
+		return nil, fmt.Errorf("--count %d does not match the %d names given", count, len(names))
+	}
+
+	expanded := make([]string, len(names))
+	seen := map[string]bool{}
+	for i, name := range names {
+		tmpl, err := template.New("name").Parse(name)
+		if err != nil {
+			return nil, fmt.Errorf("invalid name template %q: %w", name, err)
+		}
+		var b strings.Builder
+		if err := tmpl.Execute(&b, nameData{Index: i + 1, Count: len(names)}); err != nil {
+			return nil, fmt.Errorf("invalid name template %q: %w", name, err)
+		}
+		if seen[b.String()] {
+			return nil, fmt.Errorf("droplet name %q is used twice", b.String())
+		}
+		seen[b.String()] = true
+		expanded[i] = b.String()
+	}
+	return expanded, nil
+}
+
+// createBatches creates a droplet per name with multi-create requests of up
+// to api.MaxMultiCreate names and returns them with the links to their
+// create actions. On failure it returns the droplets created so far along
+// with the error.
+func createBatches(ctx context.Context, client api.DropletAPI, req *godo.DropletCreateRequest, names []string) ([]godo.Droplet, []godo.LinkAction, error) {
+	var created []godo.Droplet
+	var links []godo.LinkAction
+	for start := 0; start < len(names); start += api.MaxMultiCreate {
+		batch := names[start:min(start+api.MaxMultiCreate, len(names))]
+		droplets, batchLinks, err := client.CreateDroplets(ctx, &godo.DropletMultiCreateRequest{
+			Names:      batch,
+			Region:     req.Region,
+			Size:       req.Size,
+			Image:      req.Image,
+			SSHKeys:    req.SSHKeys,
+			Backups:    req.Backups,
+			IPv6:       req.IPv6,
+			Monitoring: req.Monitoring,
+			UserData:   req.UserData,
+			Tags:       req.Tags,
+			VPCUUID:    req.VPCUUID,
+		})
+		if err != nil {
+			logging.Debug("failed to create droplets", "names", batch, "err", err)
+			return created, links, fmt.Errorf("created %d of %d droplets, failed to create %s: %w",
+				len(created), len(names), strings.Join(names[start:], ", "), err)
+		}
+		created = append(created, droplets...)
+		links = append(links, createLinks(droplets, batchLinks)...)
+	}
+	return created, links, nil
+}
+
+// rollback deletes the droplets created before cause, reporting progress on
+// w, and returns cause along with the outcome.
+func rollback(ctx context.Context, w io.Writer, client api.DropletAPI, droplets []godo.Droplet, cause error) error {
+	fmt.Fprintf(w, "Rolling back: deleting the %d droplets already created\n", len(droplets))
+	var left []string
+	for _, d := range droplets {
+		if err := client.DeleteDroplet(ctx, d.ID); err != nil {
+			logging.Debug("failed to delete droplet", "id", d.ID, "err", err)
+			left = append(left, fmt.Sprintf("%s (%d): %v", d.Name, d.ID, err))
+		}
+	}
+	if len(left) > 0 {
+		return fmt.Errorf("%w; rollback failed to delete %d droplets, delete them by hand: %s",
+			cause, len(left), strings.Join(left, "; "))
+	}
+	return fmt.Errorf("%w; rolled back the %d droplets already created", cause, len(droplets))
+}
+
+// createLinks lines the create action links up with the droplets they
+// belong to. The API lists them in droplet order; if the counts differ the
+// links are dropped and waiting falls back to the droplets' status.
+func createLinks(droplets []godo.Droplet, links []godo.LinkAction) []godo.LinkAction {
+	if len(links) != len(droplets) {
+		return make([]godo.LinkAction, len(droplets))
+	}
+	return links
+}
+
+// printCreated prints the created droplets and returns err, so that a
+// failure after creating them, such as a failed wait, doesn't hide what was
+// created.
+func printCreated(cmd *cobra.Command, cfg *config.Config, droplets interface{}, err error) error {
+	if err == nil {
+		return output.Fprint(cmd.OutOrStdout(), droplets, output.FromConfig(cfg))
+	}
+	if printErr := output.Fprint(cmd.OutOrStdout(), droplets, output.FromConfig(cfg)); printErr != nil {
+		logging.Debug("failed to print the created droplets", "err", printErr)
+	}
+	return err
+}
+
+// waitUntilActive waits for droplets when --wait is set and returns them as
+// they are once active, with their addresses. links holds the create action
+// of each droplet. On failure it returns the droplets as last seen along
+// with the error.
+func waitUntilActive(cmd *cobra.Command, wait *progress.WaitFlags, client api.DropletAPI, droplets []godo.Droplet, links []godo.LinkAction) ([]godo.Droplet, error) {
+	if !wait.Wait {
+		return droplets, nil
+	}
+
+	links = createLinks(droplets, links)
+	checks := make([]api.Check, len(droplets))
+	for i, d := range droplets {
+		checks[i] = api.DropletReady(client, d.ID, links[i].ID)
+	}
+	message := fmt.Sprintf("Waiting for %d droplets to become active", len(droplets))
+	if len(droplets) == 1 {
+		message = fmt.Sprintf("Waiting for droplet %s to become active", droplets[0].Name)
+	}
+	waitErr := wait.Run(cmd, message, api.All(checks...))
+	if waitErr != nil {
+		waitErr = fmt.Errorf("droplets were created but did not become active: %w", waitErr)
+	}
+
+	current := append([]godo.Droplet{}, droplets...)
+	for i, d := range droplets {
+		droplet, err := client.GetDroplet(context.Background(), d.ID)
+		if err != nil {
+			logging.Debug("failed to get droplet", "id", d.ID, "err", err)
+			if waitErr == nil {
+				waitErr = err
+			}
+			continue
+		}
+		current[i] = *droplet
+	}
+	return current, waitErr
+}
+
+// parseImage takes a numeric image as an ID and anything else as a slug.
+func parseImage(image string) godo.DropletCreateImage {
+	if id, err := strconv.Atoi(image); err == nil {
+		return godo.DropletCreateImage{ID: id}
+	}
+	return godo.DropletCreateImage{Slug: image}
+}
+
+func parseSSHKey(key string) (godo.DropletCreateSSHKey, error) {
+	if id, err := strconv.Atoi(key); err == nil && id > 0 {
+		return godo.DropletCreateSSHKey{ID: id}, nil
+	}
+	if fingerprintPattern.MatchString(key) {
+		return godo.DropletCreateSSHKey{Fingerprint: strings.ToLower(key)}, nil
+	}
+	return godo.DropletCreateSSHKey{}, fmt.Errorf("invalid SSH key %q: expected an ID or an MD5 fingerprint", key)
+}
+
+func readUserData(stdin io.Reader, path string) (string, error) {
+	var data []byte
+	var err error
+	if path == "-" {
+		data, err = io.ReadAll(io.LimitReader(stdin, maxUserData+1))
+	} else {
+		data, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read user data: %w", err)
+	}
+	if len(data) == 0 {
+		return "", fmt.Errorf("user data in %s is empty", path)
+	}
+	if len(data) > maxUserData {
+		return "", fmt.Errorf("user data in %s is larger than %d KiB", path, maxUserData/1024)
+	}
+	return string(data), nil
+}
+
+// validateCreate checks req the way the API would, so that a bad
+// combination of flags fails before anything is created.
+func validateCreate(req *godo.DropletCreateRequest) error {
+	if len(req.Name) > 255 || !namePattern.MatchString(req.Name) {
+		return fmt.Errorf("invalid droplet name %q: use letters, digits, dashes and dots", req.Name)
+	}
+	if req.Region == "" {
+		return fmt.Errorf("a region is required")
+	}
+	if req.Size == "" {
+		return fmt.Errorf("a size is required")
+	}
+	if req.Image.ID <= 0 && req.Image.Slug == "" {
+		return fmt.Errorf("an image or snapshot is required")
+	}
+	if req.VPCUUID != "" && !uuidPattern.MatchString(req.VPCUUID) {
+		return fmt.Errorf("invalid VPC UUID %q", req.VPCUUID)
+	}
+	if len(req.UserData) > maxUserData {
+		return fmt.Errorf("user data is larger than %d KiB", maxUserData/1024)
+	}
+
+	seen := map[string]bool{}
+	for _, tag := range req.Tags {
+		if len(tag) > 255 || !tagPattern.MatchString(tag) {
+			return fmt.Errorf("invalid tag %q: use letters, digits, colons, dashes and underscores", tag)
+		}
+		if seen["tag "+tag] {
+			return fmt.Errorf("tag %q is given twice", tag)
+		}
+		seen["tag "+tag] = true
+	}
+	for _, key := range req.SSHKeys {
+		id := key.Fingerprint
+		if id == "" {
+			id = strconv.Itoa(key.ID)
+		}
+		if seen["key "+id] {
+			return fmt.Errorf("SSH key %s is given twice", id)
+		}
+		seen["key "+id] = true
+	}
+	for _, v := range req.Volumes {
+		if !uuidPattern.MatchString(v.ID) {
+			return fmt.Errorf("invalid volume ID %q", v.ID)
+		}
+		if seen["volume "+v.ID] {
+			return fmt.Errorf("volume %s is given twice", v.ID)
+		}
+		seen["volume "+v.ID] = true
+	}
+	return nil
+}
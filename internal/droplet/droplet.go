@@ -2,99 +2,83 @@ package droplet
 
 import (
 	"context"
+	"fmt"
 	"strconv"
 
 	"github.com/felipepimentel/digitalocean-go/internal/api"
 	"github.com/felipepimentel/digitalocean-go/internal/config"
 	"github.com/felipepimentel/digitalocean-go/internal/logging"
+	"github.com/felipepimentel/digitalocean-go/internal/output"
+	"github.com/felipepimentel/digitalocean-go/internal/progress"
 	"github.com/spf13/cobra"
 )
 
-func Cmd(cfg *config.Config) *cobra.Command {
+func Cmd(cfg *config.Config, newClient func(*config.Config) api.DropletAPI) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "droplet",
 		Short: "Manage DigitalOcean droplets",
 	}
 
 	cmd.AddCommand(
-		listCmd(cfg),
-		createCmd(cfg),
-		deleteCmd(cfg),
+		listCmd(cfg, newClient),
+		createCmd(cfg, newClient),
+		deleteCmd(cfg, newClient),
+		resizeCmd(cfg, newClient),
+		snapshotCmd(cfg, newClient),
+		backupsCmd(cfg, newClient),
 	)
+	cmd.AddCommand(actionCmds(cfg, newClient)...)
 
 	return cmd
 }
 
-func listCmd(cfg *config.Config) *cobra.Command {
+func listCmd(cfg *config.Config, newClient func(*config.Config) api.DropletAPI) *cobra.Command {
 	return &cobra.Command{
 		Use:   "list",
 		Short: "List all droplets",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			client := api.NewClient(cfg)
-			droplets, err := client.ListDroplets(context.Background())
+			client := newClient(cfg)
+			droplets, err := client.ListDroplets(context.Background(), api.PageOptionsFromConfig(cfg))
 			if err != nil {
-				logging.ErrorLogger.Printf("Failed to list droplets: %v", err)
+				logging.Debug("failed to list droplets", "err", err)
 				return err
 			}
 
-			for _, d := range droplets {
-				logging.InfoLogger.Printf("ID: %d, Name: %s, Status: %s", d.ID, d.Name, d.Status)
-			}
-			return nil
+			return output.Fprint(cmd.OutOrStdout(), droplets, output.FromConfig(cfg))
 		},
 	}
 }
 
-func createCmd(cfg *config.Config) *cobra.Command {
-	var name, region, size, image string
+func deleteCmd(cfg *config.Config, newClient func(*config.Config) api.DropletAPI) *cobra.Command {
+	var wait progress.WaitFlags
 
 	cmd := &cobra.Command{
-		Use:   "create",
-		Short: "Create a new droplet",
-		RunE: func(cmd *cobra.Command, args []string) error {
-			client := api.NewClient(cfg)
-			droplet, err := client.CreateDroplet(context.Background(), name, region, size, image)
-			if err != nil {
-				logging.ErrorLogger.Printf("Failed to create droplet: %v", err)
-				return err
-			}
-
-			logging.InfoLogger.Printf("Droplet created: ID: %d, Name: %s", droplet.ID, droplet.Name)
-			return nil
-		},
-	}
-
-	cmd.Flags().StringVarP(&name, "name", "n", "", "Droplet name")
-	cmd.Flags().StringVarP(&region, "region", "r", "nyc1", "Droplet region")
-	cmd.Flags().StringVarP(&size, "size", "s", "s-1vcpu-1gb", "Droplet size")
-	cmd.Flags().StringVarP(&image, "image", "i", "ubuntu-20-04-x64", "Droplet image")
-
-	cmd.MarkFlagRequired("name")
-
-	return cmd
-}
-
-func deleteCmd(cfg *config.Config) *cobra.Command {
-	return &cobra.Command{
 		Use:   "delete [droplet_id]",
 		Short: "Delete a droplet",
 		Args:  cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			id, err := strconv.Atoi(args[0])
 			if err != nil {
-				logging.ErrorLogger.Printf("Invalid droplet ID: %v", err)
+				logging.Debug("invalid droplet ID", "err", err)
 				return err
 			}
 
-			client := api.NewClient(cfg)
+			client := newClient(cfg)
 			err = client.DeleteDroplet(context.Background(), id)
 			if err != nil {
-				logging.ErrorLogger.Printf("Failed to delete droplet: %v", err)
+				logging.Debug("failed to delete droplet", "err", err)
+				return err
+			}
+			if err := wait.Run(cmd, fmt.Sprintf("Waiting for droplet %d to be deleted", id), api.DropletDeleted(client, id)); err != nil {
 				return err
 			}
 
-			logging.InfoLogger.Printf("Droplet with ID %d deleted successfully", id)
+			fmt.Fprintf(cmd.OutOrStdout(), "Droplet with ID %d deleted successfully\n", id)
 			return nil
 		},
 	}
+
+	wait.Register(cmd.Flags(), "the droplet is gone")
+
+	return cmd
 }
@@ -1,14 +1,26 @@
 package droplet
 
 import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/digitalocean/godo"
+	"github.com/felipepimentel/digitalocean-go/internal/api"
+	"github.com/felipepimentel/digitalocean-go/internal/api/apitest"
+	"github.com/felipepimentel/digitalocean-go/internal/api/fake"
 	"github.com/felipepimentel/digitalocean-go/internal/config"
 )
 
 func TestCmd(t *testing.T) {
 	cfg := &config.Config{}
-	cmd := Cmd(cfg)
+	cmd := Cmd(cfg, fake.Factory[api.DropletAPI](fake.New()))
 
 	if cmd.Use != "droplet" {
 		t.Errorf("Expected Use to be 'droplet', got '%s'", cmd.Use)
@@ -18,7 +30,683 @@ func TestCmd(t *testing.T) {
 		t.Errorf("Expected Short to be 'Manage DigitalOcean droplets', got '%s'", cmd.Short)
 	}
 
-	if len(cmd.Commands()) != 3 {
-		t.Errorf("Expected 3 subcommands, got %d", len(cmd.Commands()))
+	if len(cmd.Commands()) != 16 {
+		t.Errorf("Expected 16 subcommands, got %d", len(cmd.Commands()))
+	}
+}
+
+func run(cfg *config.Config, client *fake.Client, args ...string) (string, error) {
+	return apitest.Run(Cmd(cfg, fake.Factory[api.DropletAPI](client)), args...)
+}
+
+func TestCreateListDelete(t *testing.T) {
+	cfg := &config.Config{Output: "name", Region: "ams3"}
+	client := fake.New()
+
+	if _, err := run(cfg, client, "create", "--name", "web-1"); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if len(client.Droplets) != 1 {
+		t.Fatalf("Expected 1 droplet, got %d", len(client.Droplets))
+	}
+	created := client.Droplets[0]
+	if created.Region.Slug != "ams3" {
+		t.Errorf("Expected the context region ams3, got %s", created.Region.Slug)
+	}
+
+	out, err := run(cfg, client, "list")
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if strings.TrimSpace(out) != "1" {
+		t.Errorf("Expected list to print the droplet ID 1, got %q", out)
+	}
+
+	out, err = run(cfg, client, "delete", "1")
+	if err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if !strings.Contains(out, "Droplet with ID 1 deleted") {
+		t.Errorf("Unexpected delete output %q", out)
+	}
+	if len(client.Droplets) != 0 {
+		t.Errorf("Expected the droplet to be deleted, %d left", len(client.Droplets))
+	}
+}
+
+func TestCreateOptions(t *testing.T) {
+	cfg := &config.Config{Output: "name"}
+	client := fake.New()
+	userData := filepath.Join(t.TempDir(), "cloud-init.yaml")
+	if err := os.WriteFile(userData, []byte("#cloud-config\npackages: [nginx]\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := run(cfg, client, "create", "--name", "web-1", "--snapshot", "7555620",
+		"--ssh-keys", "512189,3B:16:BF:E4:8B:00:8B:B8:59:8C:A9:D3:F0:19:45:FA",
+		"--user-data-file", userData, "--vpc-uuid", "5a4981aa-9653-4bd1-bef5-d6bff52042e4",
+		"--tag", "web", "--tag", "env:prod", "--volumes", "506f78a4-e098-11e5-ad9f-000f53306ae1",
+		"--enable-ipv6", "--enable-backups", "--enable-monitoring")
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	d := client.Droplets[0]
+	if d.Image.ID != 7555620 {
+		t.Errorf("Expected the snapshot image 7555620, got %+v", d.Image)
+	}
+	if !reflect.DeepEqual(d.Tags, []string{"web", "env:prod"}) {
+		t.Errorf("Unexpected tags %v", d.Tags)
+	}
+	if d.VPCUUID != "5a4981aa-9653-4bd1-bef5-d6bff52042e4" || len(d.VolumeIDs) != 1 {
+		t.Errorf("Expected the VPC and volume to be set, got %s and %v", d.VPCUUID, d.VolumeIDs)
+	}
+	if !reflect.DeepEqual(d.Features, []string{"backups", "ipv6", "monitoring"}) {
+		t.Errorf("Unexpected features %v", d.Features)
+	}
+
+	req, err := (&createFlags{region: "nyc1", size: "s-1vcpu-1gb", image: "1000",
+		sshKeys: []string{"512189", "3B:16:BF:E4:8B:00:8B:B8:59:8C:A9:D3:F0:19:45:FA"}, userDataFile: "-"}).
+		request(strings.NewReader("#!/bin/sh\n"))
+	if err != nil {
+		t.Fatalf("request: %v", err)
+	}
+	if req.Image.ID != 1000 || req.UserData != "#!/bin/sh\n" {
+		t.Errorf("Expected image ID 1000 and user data from stdin, got %+v", req)
+	}
+	wantKeys := []godo.DropletCreateSSHKey{{ID: 512189}, {Fingerprint: "3b:16:bf:e4:8b:00:8b:b8:59:8c:a9:d3:f0:19:45:fa"}}
+	if !reflect.DeepEqual(req.SSHKeys, wantKeys) {
+		t.Errorf("Unexpected SSH keys %+v", req.SSHKeys)
+	}
+}
+
+func TestCreateValidation(t *testing.T) {
+	cfg := &config.Config{Output: "name"}
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{[]string{"--name", "web_1"}, "invalid droplet name"},
+		{[]string{"--name", "web-1", "--image", "ubuntu-22-04-x64", "--snapshot", "7555620"}, "cannot be used together"},
+		{[]string{"--name", "web-1", "--snapshot", "nightly"}, "invalid snapshot ID"},
+		{[]string{"--name", "web-1", "--ssh-keys", "my-laptop"}, "invalid SSH key"},
+		{[]string{"--name", "web-1", "--ssh-keys", "1,1"}, "given twice"},
+		{[]string{"--name", "web-1", "--vpc-uuid", "default"}, "invalid VPC UUID"},
+		{[]string{"--name", "web-1", "--tag", "bad tag"}, "invalid tag"},
+		{[]string{"--name", "web-1", "--volumes", "data"}, "invalid volume ID"},
+		{[]string{"--name", "web-1", "--name", "web-2", "--volumes", "506f78a4-e098-11e5-ad9f-000f53306ae1"}, "single droplet"},
+		{[]string{"--name", "web-1", "--user-data-file", filepath.Join(t.TempDir(), "missing")}, "failed to read user data"},
+	}
+
+	for _, tt := range tests {
+		client := fake.New()
+		_, err := run(cfg, client, append([]string{"create"}, tt.args...)...)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%v: expected an error containing %q, got %v", tt.args, tt.want, err)
+		}
+		if len(client.Droplets) != 0 {
+			t.Errorf("%v: expected no droplet to be created", tt.args)
+		}
+	}
+}
+
+func TestCreateMany(t *testing.T) {
+	cfg := &config.Config{Output: "name"}
+	client := fake.New()
+
+	out, err := run(cfg, client, "create", "--name", `web-{{printf "%02d" .Index}}`, "--count", "25", "--tag", "web")
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if len(client.Droplets) != 25 || len(strings.Fields(out)) != 25 {
+		t.Fatalf("Expected 25 droplets to be created and printed, got %d and %q", len(client.Droplets), out)
+	}
+	if client.Droplets[0].Name != "web-01" || client.Droplets[24].Name != "web-25" {
+		t.Errorf("Unexpected names %s and %s", client.Droplets[0].Name, client.Droplets[24].Name)
+	}
+
+	if _, err := run(cfg, client, "create", "--name", "db-a", "--name", "db-b"); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if len(client.Droplets) != 27 || client.Droplets[26].Name != "db-b" {
+		t.Errorf("Expected db-a and db-b to be created, got %+v", client.Droplets[25:])
+	}
+}
+
+func TestCreateManyPartialFailure(t *testing.T) {
+	cfg := &config.Config{Output: "name"}
+	client := fake.New()
+	client.DropletLimit = 15
+
+	out, err := run(cfg, client, "create", "--name", "web-{{.Index}}", "--count", "20")
+	if err == nil || !strings.Contains(err.Error(), "created 10 of 20 droplets, failed to create web-11, web-12") ||
+		!strings.Contains(err.Error(), "left running") {
+		t.Errorf("Expected the partial failure to be reported, got %v", err)
+	}
+	if ids := strings.Fields(strings.Split(out, "Error:")[0]); len(client.Droplets) != 10 || len(ids) != 10 {
+		t.Errorf("Expected the 10 created droplets to be kept and printed, got %d and %q", len(client.Droplets), out)
+	}
+
+	client = fake.New()
+	client.DropletLimit = 15
+	out, err = run(cfg, client, "create", "--name", "web-{{.Index}}", "--count", "20", "--rollback")
+	if err == nil || !strings.Contains(err.Error(), "rolled back the 10 droplets") {
+		t.Errorf("Expected the rollback to be reported, got %v", err)
+	}
+	if len(client.Droplets) != 0 || !strings.Contains(out, "Rolling back") {
+		t.Errorf("Expected every droplet to be deleted, %d left:\n%s", len(client.Droplets), out)
+	}
+}
+
+func TestExpandNames(t *testing.T) {
+	names, err := expandNames([]string{"web-{{.Index}}-of-{{.Count}}"}, 3)
+	if err != nil || !reflect.DeepEqual(names, []string{"web-1-of-3", "web-2-of-3", "web-3-of-3"}) {
+		t.Errorf("Unexpected names %v, %v", names, err)
+	}
+
+	for _, tt := range []struct {
+		names []string
+		count int
+		want  string
+	}{
+		{[]string{"web"}, 3, "needs a name template"},
+		{[]string{"a", "b"}, 3, "does not match"},
+		{[]string{"web-{{.Index"}, 2, "invalid name template"},
+		{[]string{"web-{{.Name}}"}, 2, "invalid name template"},
+		{[]string{"web-{{.Count}}"}, 2, "used twice"},
+		{[]string{"web"}, 0, "at least 1"},
+	} {
+		if _, err := expandNames(tt.names, tt.count); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("expandNames(%v, %d): expected an error containing %q, got %v", tt.names, tt.count, tt.want, err)
+		}
+	}
+}
+
+func TestWait(t *testing.T) {
+	cfg := &config.Config{Output: "name"}
+	client := fake.New()
+
+	out, err := run(cfg, client, "create", "--name", "web-1", "--wait")
+	if err != nil || out != "1\n" {
+		t.Fatalf("create --wait: %v, %q", err, out)
+	}
+	if _, err := run(cfg, client, "delete", "1", "--wait", "--wait-timeout", "1m"); err != nil {
+		t.Fatalf("delete --wait: %v", err)
+	}
+
+	// Earlier errored actions on the droplet don't matter, only its create
+	// action does.
+	client.Actions = append(client.Actions, godo.Action{ID: 100, Type: "power_on", Status: "errored", ResourceID: 102, ResourceType: "droplet"})
+	out, err = run(cfg, client, "create", "--name", "web-2", "--wait")
+	if err != nil || out != "101\n" {
+		t.Fatalf("create --wait: %v, %q", err, out)
+	}
+
+	// IDs are handed out after the highest one in use, so web-3 gets 103
+	// and its create action 104.
+	client.CreateStatus = "errored"
+	out, err = run(cfg, client, "create", "--name", "web-3", "--wait")
+	if err == nil || !strings.Contains(err.Error(), "droplet 103: create action 104 errored") {
+		t.Errorf("Expected the errored create action to fail the wait, got %v", err)
+	}
+	if !strings.Contains(out, "103\n") {
+		t.Errorf("Expected the created droplet to be printed, got %q", out)
+	}
+}
+
+func actionFixture() *fake.Client {
+	client := fake.New()
+	client.Droplets = []godo.Droplet{
+		{ID: 1, Name: "web-1", Status: "active", Tags: []string{"web"}, Image: &godo.Image{ID: 1000}},
+		{ID: 2, Name: "web-2", Status: "active", Tags: []string{"web"}, Image: &godo.Image{ID: 1000}},
+		{ID: 3, Name: "db", Status: "active", Image: &godo.Image{Slug: "ubuntu-22-04-x64"}},
+		{ID: 4, Name: "db", Status: "active"},
+	}
+	return client
+}
+
+func TestActions(t *testing.T) {
+	cfg := &config.Config{Output: "json"}
+	client := actionFixture()
+
+	out, err := run(cfg, client, "power-off", "--tag", "web", "1", "--wait")
+	if err != nil {
+		t.Fatalf("power-off: %v", err)
+	}
+	if client.Droplets[0].Status != "off" || client.Droplets[1].Status != "off" || client.Droplets[2].Status != "active" {
+		t.Errorf("Expected web-1 and web-2 to be off, got %+v", client.Droplets)
+	}
+	if strings.Count(out, `"type": "power_off"`) != 2 || strings.Count(out, `"status": "completed"`) != 2 {
+		t.Errorf("Expected two completed power_off actions:\n%s", out)
+	}
+
+	if _, err := run(cfg, client, "power-on", "web-2"); err != nil || client.Droplets[1].Status != "active" {
+		t.Errorf("Expected web-2 to be powered on by name, got %v", err)
+	}
+	if out, err := runInput(client, "n\n", "rebuild", "3"); err == nil || err.Error() != "rebuild cancelled" ||
+		!strings.Contains(out, "erases their disks") || strings.Contains(out, `"type": "rebuild"`) {
+		t.Errorf("Expected the rebuild to be cancelled after the warning, got %v:\n%s", err, out)
+	}
+	if _, err := run(cfg, client, "rebuild", "3", "--yes"); err != nil || client.Droplets[2].Image.Slug != "ubuntu-22-04-x64" {
+		t.Errorf("Expected db to be rebuilt from its own image, got %v", err)
+	}
+	if _, err := run(cfg, client, "rename", "--tag", "web", "--to", "{{.Name}}-old"); err != nil {
+		t.Fatalf("rename: %v", err)
+	}
+	if client.Droplets[0].Name != "web-1-old" || client.Droplets[1].Name != "web-2-old" {
+		t.Errorf("Unexpected names after rename: %s, %s", client.Droplets[0].Name, client.Droplets[1].Name)
+	}
+}
+
+func TestActionErrors(t *testing.T) {
+	cfg := &config.Config{Output: "name"}
+	for _, tt := range []struct {
+		args []string
+		want string
+	}{
+		{[]string{"reboot"}, "select droplets"},
+		{[]string{"reboot", "db"}, "2 droplets are named db: select one by ID (3, 4)"},
+		{[]string{"reboot", "cache"}, "no droplet is named cache"},
+		{[]string{"reboot", "99"}, "droplet 99 not found"},
+		{[]string{"reboot", "--tag", "cache"}, "no droplet is tagged cache"},
+		{[]string{"rename", "--tag", "web", "--to", "web"}, "used twice"},
+		{[]string{"rebuild", "4"}, "no image to rebuild from"},
+	} {
+		if _, err := run(cfg, actionFixture(), tt.args...); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%v: expected an error containing %q, got %v", tt.args, tt.want, err)
+		}
+	}
+
+	client := actionFixture()
+	client.Errors["RunDropletAction"] = errors.New("boom")
+	if _, err := run(cfg, client, "shutdown", "--tag", "web"); err == nil ||
+		!strings.Contains(err.Error(), "shutdown failed on 2 of 2 droplets: web-1 (1): boom") {
+		t.Errorf("Expected every failure to be reported, got %v", err)
+	}
+}
+
+func TestSelectionBypassesCache(t *testing.T) {
+	for _, tt := range []struct {
+		args    []string
+		refresh bool
+	}{
+		{[]string{"list"}, false},
+		{[]string{"snapshot", "list", "--tag", "web"}, false},
+		{[]string{"reboot", "--tag", "web"}, true},
+		{[]string{"resize", "web-1", "--size", "s-1vcpu-1gb"}, true},
+		{[]string{"backups", "restore", "web-1", "--yes"}, true},
+		{[]string{"snapshot", "prune", "--tag", "web", "--keep-last", "1", "--yes"}, true},
+	} {
+		cfg := &config.Config{Output: "name"}
+		client := actionFixture()
+		var refresh bool
+		cmd := Cmd(cfg, func(c *config.Config) api.DropletAPI {
+			refresh = c.RefreshCache
+			return client
+		})
+		// Only the config the client is built with matters here, not
+		// whether the command succeeds on the fixture.
+		apitest.Run(cmd, tt.args...)
+		if refresh != tt.refresh {
+			t.Errorf("%v: expected RefreshCache %v, got %v", tt.args, tt.refresh, refresh)
+		}
+		if cfg.RefreshCache {
+			t.Errorf("%v: the shared config was changed", tt.args)
+		}
+	}
+}
+
+func resizeFixture() *fake.Client {
+	client := fake.New()
+	small := godo.Size{Slug: "s-1vcpu-1gb", Disk: 25, PriceMonthly: 6, Available: true, Regions: []string{"nyc1", "ams3"}}
+	client.Sizes = []godo.Size{
+		{Slug: "s-1vcpu-512mb-10gb", Disk: 10, PriceMonthly: 4, Available: true, Regions: []string{"nyc1"}},
+		small,
+		{Slug: "s-1vcpu-2gb", Disk: 50, PriceMonthly: 12, Available: true, Regions: []string{"nyc1", "ams3"}},
+		{Slug: "s-2vcpu-4gb", Vcpus: 2, Disk: 80, PriceMonthly: 24, Available: true, Regions: []string{"nyc1", "ams3"}},
+		{Slug: "s-4vcpu-8gb", Vcpus: 4, Disk: 160, PriceMonthly: 48, Available: true, Regions: []string{"ams3"}},
+	}
+	client.Droplets = []godo.Droplet{
+		{ID: 1, Name: "web-1", Status: "active", Disk: 25, Size: &small, SizeSlug: small.Slug, Region: &godo.Region{Slug: "nyc1"}},
+	}
+	return client
+}
+
+// runInput runs the command with JSON output, answering prompts with input.
+func runInput(client *fake.Client, input string, args ...string) (string, error) {
+	cmd := Cmd(&config.Config{Output: "json"}, fake.Factory[api.DropletAPI](client))
+	cmd.SetIn(strings.NewReader(input))
+	return apitest.Run(cmd, args...)
+}
+
+func TestResize(t *testing.T) {
+	client := resizeFixture()
+
+	out, err := runInput(client, "", "resize", "web-1", "--size", "s-2vcpu-4gb")
+	if err != nil {
+		t.Fatalf("resize: %v", err)
+	}
+	if !strings.Contains(out, "Monthly price: $6.00 -> $24.00 (+$18.00)") {
+		t.Errorf("Expected the price difference:\n%s", out)
+	}
+	for _, action := range []string{"shutdown", "resize", "power_on"} {
+		if !strings.Contains(out, `"type": "`+action+`"`) {
+			t.Errorf("Expected a %s action:\n%s", action, out)
+		}
+	}
+	d := client.Droplets[0]
+	if d.SizeSlug != "s-2vcpu-4gb" || d.Disk != 25 || d.Status != "active" {
+		t.Errorf("Expected an active droplet resized without its disk, got %s, %d GB, %s", d.SizeSlug, d.Disk, d.Status)
+	}
+
+	out, err = runInput(client, "n\n", "resize", "web-1", "--size", "s-1vcpu-1gb", "--disk")
+	if err == nil || err.Error() != "resize cancelled" || !strings.Contains(out, "cannot be undone") {
+		t.Errorf("Expected the disk resize to be cancelled after the warning, got %v:\n%s", err, out)
+	}
+	if client.Droplets[0].Status != "active" {
+		t.Errorf("Expected a cancelled resize to leave the droplet on, got %s", client.Droplets[0].Status)
+	}
+
+	client.Droplets[0].Status = "off"
+	out, err = runInput(client, "y\n", "resize", "web-1", "--size", "s-1vcpu-2gb", "--disk")
+	if err != nil {
+		t.Fatalf("resize --disk: %v", err)
+	}
+	if d := client.Droplets[0]; d.Disk != 50 || d.Status != "off" || strings.Contains(out, "power_on") {
+		t.Errorf("Expected an off droplet to be resized with its disk and left off, got %d GB, %s:\n%s", d.Disk, d.Status, out)
+	}
+	if !strings.Contains(out, "$24.00 -> $12.00 (-$12.00)") {
+		t.Errorf("Expected a price decrease:\n%s", out)
+	}
+}
+
+func TestResizeErrors(t *testing.T) {
+	for _, tt := range []struct {
+		size string
+		want string
+	}{
+		{"s-8vcpu-16gb", "unknown size s-8vcpu-16gb; sizes available to this droplet include s-1vcpu-2gb ($12.00/mo), s-2vcpu-4gb ($24.00/mo)"},
+		{"s-4vcpu-8gb", "size s-4vcpu-8gb is not available in nyc1; sizes available to this droplet include s-2vcpu-4gb ($24.00/mo), s-1vcpu-2gb ($12.00/mo)"},
+		{"s-1vcpu-512mb-10gb", "smaller than the droplet's 25 GB disk"},
+		{"s-1vcpu-1gb", "already has size s-1vcpu-1gb"},
+	} {
+		client := resizeFixture()
+		if _, err := runInput(client, "", "resize", "web-1", "--size", tt.size); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: expected an error containing %q, got %v", tt.size, tt.want, err)
+		}
+		if len(client.Actions) != 0 {
+			t.Errorf("%s: expected no actions, got %+v", tt.size, client.Actions)
+		}
+	}
+}
+
+func snapshotFixture() *fake.Client {
+	client := actionFixture()
+	now := time.Now()
+	snapshot := func(id, droplet, days int) godo.Snapshot {
+		return godo.Snapshot{
+			ID:           strconv.Itoa(id),
+			Name:         fmt.Sprintf("snap-%d", id),
+			ResourceID:   strconv.Itoa(droplet),
+			ResourceType: "droplet",
+			Created:      now.Add(-time.Duration(days) * 24 * time.Hour).Format(time.RFC3339),
+		}
+	}
+	client.Snapshots = []godo.Snapshot{
+		snapshot(101, 1, 60), snapshot(102, 1, 50), snapshot(103, 1, 40),
+		snapshot(104, 1, 10), snapshot(105, 1, 5), snapshot(106, 1, 1),
+		snapshot(201, 2, 45),
+		snapshot(901, 9, 90), // of a deleted droplet
+	}
+	return client
+}
+
+func snapshotIDs(client *fake.Client) string {
+	ids := make([]string, len(client.Snapshots))
+	for i, s := range client.Snapshots {
+		ids[i] = s.ID
+	}
+	return strings.Join(ids, ",")
+}
+
+func TestSnapshots(t *testing.T) {
+	cfg := &config.Config{Output: "json"}
+	client := snapshotFixture()
+
+	if _, err := run(cfg, client, "snapshot", "create", "--tag", "web", "--name", "{{.Name}}-nightly", "--wait"); err != nil {
+		t.Fatalf("snapshot create: %v", err)
+	}
+	if n := len(client.Snapshots); n != 10 || client.Snapshots[8].Name != "web-1-nightly" || client.Snapshots[9].Name != "web-2-nightly" {
+		t.Errorf("Expected a snapshot of each web droplet, got %+v", client.Snapshots[8:])
+	}
+
+	out, err := run(cfg, client, "snapshot", "list", "web-2")
+	if err != nil {
+		t.Fatalf("snapshot list: %v", err)
+	}
+	if !strings.Contains(out, "snap-201") || !strings.Contains(out, "web-2-nightly") || strings.Contains(out, "snap-101") {
+		t.Errorf("Expected only the snapshots of web-2:\n%s", out)
+	}
+	if out, _ := run(cfg, client, "snapshot", "list"); !strings.Contains(out, "snap-901") {
+		t.Errorf("Expected every snapshot without a selection:\n%s", out)
+	}
+
+	_, err = run(cfg, client, "snapshot", "delete", "901", "999")
+	if err == nil || !strings.Contains(err.Error(), "failed to delete 1 of 2 snapshots: 999: not found") {
+		t.Errorf("Expected the missing snapshot to be reported, got %v", err)
+	}
+	if strings.Contains(snapshotIDs(client), "901") {
+		t.Errorf("Expected snapshot 901 to be deleted, got %s", snapshotIDs(client))
+	}
+}
+
+func TestSnapshotPrune(t *testing.T) {
+	client := snapshotFixture()
+
+	out, err := runInput(client, "", "snapshot", "prune", "--tag", "web", "--keep-last", "2", "--older-than", "30d", "--dry-run")
+	if err != nil {
+		t.Fatalf("prune --dry-run: %v", err)
+	}
+	if !strings.Contains(out, "Keeping the last 2 of each droplet and those taken in the last 30d, 3 of 7 snapshots are deleted") {
+		t.Errorf("Expected the plan:\n%s", out)
+	}
+	if len(client.Snapshots) != 8 {
+		t.Errorf("Expected a dry run to delete nothing, got %s", snapshotIDs(client))
+	}
+
+	if _, err := runInput(client, "n\n", "snapshot", "prune", "web-1", "--keep-last", "4"); err == nil || err.Error() != "prune cancelled" {
+		t.Errorf("Expected the prune to be cancelled, got %v", err)
+	}
+	if _, err := runInput(client, "", "snapshot", "prune", "web-1", "--keep-last", "4", "--yes"); err != nil {
+		t.Fatalf("prune --keep-last: %v", err)
+	}
+	if ids := snapshotIDs(client); ids != "103,104,105,106,201,901" {
+		t.Errorf("Expected the two oldest snapshots of web-1 to be deleted, got %s", ids)
+	}
+
+	if _, err := runInput(client, "", "snapshot", "prune", "--all", "--older-than", "30d", "--yes"); err != nil {
+		t.Fatalf("prune --all: %v", err)
+	}
+	if ids := snapshotIDs(client); ids != "104,105,106" {
+		t.Errorf("Expected every snapshot older than 30 days to be deleted, got %s", ids)
+	}
+	if out, err := runInput(client, "", "snapshot", "prune", "--all", "--older-than", "30d", "--yes"); err != nil || !strings.Contains(out, "Nothing to prune") {
+		t.Errorf("Expected nothing left to prune, got %v:\n%s", err, out)
+	}
+
+	for _, tt := range []struct {
+		args []string
+		want string
+	}{
+		{[]string{"--keep-last", "7"}, "select droplets"},
+		{[]string{"--all", "--tag", "web", "--keep-last", "7"}, "cannot be combined"},
+		{[]string{"--all"}, "set a retention policy"},
+		{[]string{"--all", "--keep-last", "-1"}, "must not be negative"},
+		{[]string{"--all", "--older-than", "30x"}, `invalid age "30x"`},
+	} {
+		args := append([]string{"snapshot", "prune"}, tt.args...)
+		if _, err := runInput(snapshotFixture(), "", args...); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%v: expected an error containing %q, got %v", tt.args, tt.want, err)
+		}
+	}
+}
+
+func TestParseAge(t *testing.T) {
+	for in, want := range map[string]time.Duration{
+		"30d": 30 * 24 * time.Hour,
+		"2w":  14 * 24 * time.Hour,
+		"36h": 36 * time.Hour,
+	} {
+		if got, err := parseAge(in); err != nil || got != want {
+			t.Errorf("parseAge(%q) = %v, %v; want %v", in, got, err, want)
+		}
+	}
+	for d, want := range map[time.Duration]string{14 * 24 * time.Hour: "14d", 36 * time.Hour: "36h", 90 * time.Minute: "1h30m", 45 * time.Second: "45s"} {
+		if got := formatAge(d); got != want {
+			t.Errorf("formatAge(%v) = %q, want %q", d, got, want)
+		}
+	}
+	for _, in := range []string{"", "d", "0d", "-1d", "1.5d", "month"} {
+		if _, err := parseAge(in); err == nil {
+			t.Errorf("parseAge(%q): expected an error", in)
+		}
+	}
+}
+
+func backupsFixture() *fake.Client {
+	client := actionFixture()
+	client.Backups = map[int][]godo.Image{
+		1: {
+			{ID: 501, Name: "web-1 weekly", Type: "backup", Created: "2024-01-08T04:00:00Z"},
+			{ID: 502, Name: "web-1 weekly", Type: "backup", Created: "2024-01-15T04:00:00Z"},
+		},
+	}
+	return client
+}
+
+func TestBackups(t *testing.T) {
+	client := backupsFixture()
+
+	out, err := runInput(client, "", "backups", "list", "web-1")
+	if err != nil {
+		t.Fatalf("backups list: %v", err)
+	}
+	if !strings.Contains(out, `"id": 501`) || !strings.Contains(out, `"id": 502`) {
+		t.Errorf("Expected both backups:\n%s", out)
+	}
+
+	if _, err := runInput(client, "\n", "backups", "restore", "web-1"); err == nil || err.Error() != "restore cancelled" {
+		t.Errorf("Expected the restore to be cancelled, got %v", err)
+	}
+	out, err = runInput(client, "yes\n", "backups", "restore", "web-1")
+	if err != nil {
+		t.Fatalf("backups restore: %v", err)
+	}
+	if !strings.Contains(out, "from backup web-1 weekly (502)") || !strings.Contains(out, `"type": "restore"`) {
+		t.Errorf("Expected the newest backup to be restored:\n%s", out)
+	}
+	if _, err := runInput(client, "", "backups", "restore", "1", "501", "--yes", "--wait"); err != nil {
+		t.Errorf("backups restore 501: %v", err)
+	}
+
+	for _, tt := range []struct {
+		args []string
+		want string
+	}{
+		{[]string{"web-1", "999"}, "999 is not one of the backups (501, 502) of droplet web-1 (1)"},
+		{[]string{"web-1", "latest"}, `invalid backup ID "latest"`},
+		{[]string{"web-2"}, "droplet web-2 (2) has no backups"},
+	} {
+		args := append([]string{"backups", "restore", "--yes"}, tt.args...)
+		if _, err := runInput(client, "", args...); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%v: expected an error containing %q, got %v", tt.args, tt.want, err)
+		}
+	}
+}
+
+func TestWaitFailurePrintsActions(t *testing.T) {
+	for _, tt := range []struct {
+		client *fake.Client
+		args   []string
+		want   string
+	}{
+		{actionFixture(), []string{"reboot", "--tag", "web", "--wait"}, `"type": "reboot"`},
+		{backupsFixture(), []string{"backups", "restore", "web-1", "--yes", "--wait"}, `"type": "restore"`},
+		{resizeFixture(), []string{"resize", "web-1", "--size", "s-2vcpu-4gb"}, `"type": "shutdown"`},
+	} {
+		tt.client.Errors["GetAction"] = errors.New("boom")
+		out, err := runInput(tt.client, "", tt.args...)
+		if err == nil || !strings.Contains(err.Error(), "boom") {
+			t.Errorf("%v: expected the wait to fail, got %v", tt.args, err)
+		}
+		if !strings.Contains(out, tt.want) {
+			t.Errorf("%v: expected the actions taken to be printed:\n%s", tt.args, out)
+		}
+	}
+}
+
+func TestListLimit(t *testing.T) {
+	cfg := &config.Config{Output: "name", Limit: 2}
+	client := fake.New()
+	client.Droplets = []godo.Droplet{{ID: 1, Name: "a"}, {ID: 2, Name: "b"}, {ID: 3, Name: "c"}}
+
+	out, err := run(cfg, client, "list")
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if out != "1\n2\n" {
+		t.Errorf("Expected the first two droplets, got %q", out)
+	}
+}
+
+func TestAPIError(t *testing.T) {
+	cfg := &config.Config{Output: "name"}
+	client := fake.New()
+	client.Errors["ListDroplets"] = errors.New("boom")
+
+	if _, err := run(cfg, client, "list"); err == nil || err.Error() != "boom" {
+		t.Errorf("Expected the API error, got %v", err)
+	}
+}
+
+// The tests below replay the cassettes in testdata. Re-record them with
+// DO_RECORD=1 go test ./internal/droplet.
+
+func TestListReplay(t *testing.T) {
+	cmd := Cmd(&config.Config{Output: "table"}, apitest.Replay[api.DropletAPI](t, "list"))
+
+	out, err := apitest.Run(cmd, "list")
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	for _, want := range []string{"web-1", "worker-1", "198.51.100.195", "web,production"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected %q in the output:\n%s", want, out)
+		}
+	}
+}
+
+func TestCreateDeleteReplay(t *testing.T) {
+	newClient := apitest.Replay[api.DropletAPI](t, "create_delete")
+	cfg := &config.Config{Output: "json"}
+
+	out, err := apitest.Run(Cmd(cfg, newClient), "create", "--name", "web-3", "--region", "nyc1", "--image", "ubuntu-22-04-x64")
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if !strings.Contains(out, `"name": "web-3"`) || !strings.Contains(out, `"status": "new"`) {
+		t.Errorf("Unexpected create output:\n%s", out)
+	}
+
+	out, err = apitest.Run(Cmd(cfg, newClient), "delete", "3164446")
+	if err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if out != "Droplet with ID 3164446 deleted successfully\n" {
+		t.Errorf("Unexpected delete output %q", out)
+	}
+
+	if _, err := apitest.Run(Cmd(cfg, newClient), "delete", "999"); err == nil {
+		t.Error("Expected deleting an unknown droplet to fail")
 	}
 }
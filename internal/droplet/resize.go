@@ -0,0 +1,217 @@
+package droplet
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/digitalocean/godo"
+	"github.com/felipepimentel/digitalocean-go/internal/api"
+	"github.com/felipepimentel/digitalocean-go/internal/config"
+	"github.com/felipepimentel/digitalocean-go/internal/logging"
+	"github.com/felipepimentel/digitalocean-go/internal/output"
+	"github.com/felipepimentel/digitalocean-go/internal/progress"
+	"github.com/felipepimentel/digitalocean-go/internal/prompt"
+	"github.com/spf13/cobra"
+)
+
+// maxRecommendations is how many sizes a rejected resize suggests.
+const maxRecommendations = 3
+
+func resizeCmd(cfg *config.Config, newClient func(*config.Config) api.DropletAPI) *cobra.Command {
+	var slug string
+	var disk, yes bool
+	var wait progress.WaitFlags
+
+	cmd := &cobra.Command{
+		Use:   "resize <droplet_id_or_name> --size <slug>",
+		Short: "Resize a droplet",
+		Long: `Resize a droplet to another size.
+
+The size must be available in the droplet's region and its disk at least as
+large as the droplet's; otherwise the closest-priced sizes that fit are
+suggested. The change in monthly price is printed before resizing.
+
+Droplets can only be resized while off, so an active droplet is shut down
+first and powered back on afterwards. Without --disk only CPU and memory
+change, and the droplet can later be resized back down. With --disk the
+disk grows too, which is permanent, so it asks for confirmation unless
+--yes is given.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := context.Background()
+			client := newClient(refreshed(cfg))
+			droplets, err := selectDroplets(ctx, client, args, nil)
+			if err != nil {
+				return err
+			}
+			d := droplets[0]
+			if d.Status != "active" && d.Status != "off" {
+				return fmt.Errorf("droplet %s (%d) is %s: wait until it is active or off to resize it", d.Name, d.ID, d.Status)
+			}
+
+			sizes, err := client.ListSizes(ctx, api.PageOptions{})
+			if err != nil {
+				logging.Debug("failed to list sizes", "err", err)
+				return err
+			}
+			current, target, err := resizeSizes(d, sizes, slug)
+			if err != nil {
+				return err
+			}
+
+			stderr := cmd.ErrOrStderr()
+			fmt.Fprintf(stderr, "Resizing %s (%d) from %s to %s\n", d.Name, d.ID, current.Slug, target.Slug)
+			fmt.Fprintf(stderr, "Monthly price: %s -> %s (%s)\n",
+				price(current.PriceMonthly), price(target.PriceMonthly), priceDelta(target.PriceMonthly-current.PriceMonthly))
+			if disk {
+				fmt.Fprintf(stderr, "Warning: the disk grows from %d GB to %d GB. This cannot be undone: the droplet can never be resized to a size with a smaller disk again.\n", d.Disk, target.Disk)
+				if !yes {
+					ok, err := prompt.Confirm(cmd, "Resize the disk?")
+					if err != nil {
+						return err
+					}
+					if !ok {
+						return errors.New("resize cancelled")
+					}
+				}
+			}
+
+			var actions []godo.Action
+			step := func(req api.DropletActionRequest, message string, mustWait bool) error {
+				action, err := client.RunDropletAction(ctx, d.ID, req)
+				if err != nil {
+					logging.Debug("failed to run droplet action", "id", d.ID, "action", req.Type, "err", err)
+					return fmt.Errorf("failed to %s droplet %s (%d): %w", strings.ReplaceAll(req.Type, "_", " "), d.Name, d.ID, err)
+				}
+				actions = append(actions, *action)
+				if !mustWait && !wait.Wait {
+					return nil
+				}
+				err = wait.WaitFor(cmd, message, api.ActionDone(client, action.ID))
+				if refreshErr := refreshActions(ctx, client, actions[len(actions)-1:]); err == nil {
+					err = refreshErr
+				}
+				return err
+			}
+
+			// The actions taken are printed even if a later step fails,
+			// with their last known status.
+			err = func() error {
+				poweredOff := false
+				if d.Status == "active" {
+					if err := step(api.DropletActionRequest{Type: api.ActionShutdown}, "Shutting down "+d.Name, true); err != nil {
+						return err
+					}
+					poweredOff = true
+				}
+				resize := api.DropletActionRequest{Type: api.ActionResize, Size: target.Slug, Disk: disk}
+				if err := step(resize, "Resizing "+d.Name, true); err != nil {
+					if poweredOff {
+						if err := step(api.DropletActionRequest{Type: api.ActionPowerOn}, "Powering on "+d.Name, false); err != nil {
+							fmt.Fprintf(stderr, "Warning: %v\n", err)
+						}
+					}
+					return err
+				}
+				if poweredOff {
+					return step(api.DropletActionRequest{Type: api.ActionPowerOn}, "Powering on "+d.Name, false)
+				}
+				return nil
+			}()
+
+			if len(actions) > 0 {
+				if printErr := output.Fprint(cmd.OutOrStdout(), actions, output.FromConfig(cfg)); printErr != nil && err == nil {
+					return printErr
+				}
+			}
+			return err
+		},
+	}
+
+	cmd.Flags().StringVarP(&slug, "size", "s", "", "Size slug to resize to")
+	cmd.Flags().BoolVar(&disk, "disk", false, "Grow the disk as well, permanently")
+	cmd.Flags().BoolVarP(&yes, "yes", "y", false, "Resize the disk without asking for confirmation")
+	wait.Register(cmd.Flags(), "the droplet is powered back on")
+	cmd.MarkFlagRequired("size")
+
+	return cmd
+}
+
+// resizeSizes looks up the droplet's current size and the target size in
+// the catalogue and checks that the droplet can be resized to it.
+func resizeSizes(d godo.Droplet, sizes []godo.Size, slug string) (current, target godo.Size, err error) {
+	current = godo.Size{Slug: d.SizeSlug}
+	if d.Size != nil {
+		current = *d.Size
+	}
+	region := ""
+	if d.Region != nil {
+		region = d.Region.Slug
+	}
+
+	var found *godo.Size
+	for i := range sizes {
+		if sizes[i].Slug == d.SizeSlug {
+			current = sizes[i]
+		}
+		if sizes[i].Slug == slug {
+			found = &sizes[i]
+		}
+	}
+
+	switch {
+	case found == nil:
+		err = fmt.Errorf("unknown size %s", slug)
+	case found.Slug == current.Slug:
+		return current, *found, fmt.Errorf("droplet %s (%d) already has size %s", d.Name, d.ID, slug)
+	case !found.Available || !contains(found.Regions, region):
+		err = fmt.Errorf("size %s is not available in %s", slug, region)
+	case found.Disk < d.Disk:
+		err = fmt.Errorf("size %s has a %d GB disk, smaller than the droplet's %d GB disk, which cannot shrink", slug, found.Disk, d.Disk)
+	default:
+		return current, *found, nil
+	}
+
+	reference := current.PriceMonthly
+	if found != nil {
+		reference = found.PriceMonthly
+	}
+	if recommended := recommendSizes(sizes, d, region, reference); len(recommended) > 0 {
+		names := make([]string, len(recommended))
+		for i, s := range recommended {
+			names[i] = fmt.Sprintf("%s (%s/mo)", s.Slug, price(s.PriceMonthly))
+		}
+		err = fmt.Errorf("%w; sizes available to this droplet include %s", err, strings.Join(names, ", "))
+	}
+	return current, godo.Size{}, err
+}
+
+// recommendSizes returns the sizes the droplet can be resized to in its
+// region, closest to the reference monthly price first.
+func recommendSizes(sizes []godo.Size, d godo.Droplet, region string, reference float64) []godo.Size {
+	var fit []godo.Size
+	for _, s := range sizes {
+		if s.Available && s.Slug != d.SizeSlug && s.Disk >= d.Disk && contains(s.Regions, region) {
+			fit = append(fit, s)
+		}
+	}
+	sort.SliceStable(fit, func(i, j int) bool {
+		return math.Abs(fit[i].PriceMonthly-reference) < math.Abs(fit[j].PriceMonthly-reference)
+	})
+	return fit[:min(len(fit), maxRecommendations)]
+}
+
+func price(dollars float64) string {
+	return fmt.Sprintf("$%.2f", dollars)
+}
+
+func priceDelta(dollars float64) string {
+	if dollars < 0 {
+		return "-" + price(-dollars)
+	}
+	return "+" + price(dollars)
+}
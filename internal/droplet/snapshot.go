@@ -0,0 +1,356 @@
+package droplet
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/digitalocean/godo"
+	"github.com/felipepimentel/digitalocean-go/internal/api"
+	"github.com/felipepimentel/digitalocean-go/internal/config"
+	"github.com/felipepimentel/digitalocean-go/internal/logging"
+	"github.com/felipepimentel/digitalocean-go/internal/output"
+	"github.com/felipepimentel/digitalocean-go/internal/prompt"
+	"github.com/spf13/cobra"
+)
+
+func snapshotCmd(cfg *config.Config, newClient func(*config.Config) api.DropletAPI) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "snapshot",
+		Short: "Manage droplet snapshots",
+	}
+
+	cmd.AddCommand(
+		snapshotCreateCmd(cfg, newClient),
+		snapshotListCmd(cfg, newClient),
+		snapshotDeleteCmd(cfg, newClient),
+		snapshotPruneCmd(cfg, newClient),
+	)
+
+	return cmd
+}
+
+// snapshotData is what the snapshot create --name template is executed
+// with.
+type snapshotData struct {
+	renameData
+	// Time is when the command started, in UTC, as 20060102-150405.
+	Time string
+}
+
+func snapshotCreateCmd(cfg *config.Config, newClient func(*config.Config) api.DropletAPI) *cobra.Command {
+	var name string
+
+	cmd := actionCmd(cfg, newClient, "create", "Take snapshots of droplets", func(droplets []godo.Droplet) ([]api.DropletActionRequest, error) {
+		tmpl, err := template.New("name").Parse(name)
+		if err != nil {
+			return nil, fmt.Errorf("invalid name template %q: %w", name, err)
+		}
+
+		now := time.Now().UTC().Format("20060102-150405")
+		reqs := make([]api.DropletActionRequest, len(droplets))
+		for i, d := range droplets {
+			var b strings.Builder
+			data := snapshotData{renameData{ID: d.ID, Name: d.Name, Index: i + 1, Count: len(droplets)}, now}
+			if err := tmpl.Execute(&b, data); err != nil {
+				return nil, fmt.Errorf("invalid name template %q: %w", name, err)
+			}
+			if strings.TrimSpace(b.String()) == "" {
+				return nil, fmt.Errorf("name template %q gives droplet %s (%d) an empty snapshot name", name, d.Name, d.ID)
+			}
+			reqs[i] = api.DropletActionRequest{Type: api.ActionSnapshot, Name: b.String()}
+		}
+		return reqs, nil
+	})
+	cmd.Long += `
+
+Snapshots of running droplets are taken live; power them off first for a
+consistent copy of the disk. --name is a template executed for each droplet
+with its .ID, .Name, .Index and .Count, as for rename, and the .Time the
+command started, e.g. "{{.Name}}-{{.Time}}".`
+	cmd.Flags().StringVar(&name, "name", "{{.Name}}-{{.Time}}", "Snapshot name, or a name template")
+
+	return cmd
+}
+
+func snapshotListCmd(cfg *config.Config, newClient func(*config.Config) api.DropletAPI) *cobra.Command {
+	var tags []string
+
+	cmd := &cobra.Command{
+		Use:   "list [droplet_id_or_name ...]",
+		Short: "List droplet snapshots",
+		Long: `List the snapshots of the droplets selected by ID, name or --tag, or of
+every droplet, including deleted ones, without a selection.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			snapshots, err := selectSnapshots(context.Background(), newClient(cfg), args, tags, len(args) == 0 && len(tags) == 0)
+			if err != nil {
+				return err
+			}
+			return output.Fprint(cmd.OutOrStdout(), snapshots, output.FromConfig(cfg))
+		},
+	}
+
+	cmd.Flags().StringSliceVar(&tags, "tag", nil, "Select the droplets with this tag")
+
+	return cmd
+}
+
+func snapshotDeleteCmd(cfg *config.Config, newClient func(*config.Config) api.DropletAPI) *cobra.Command {
+	return &cobra.Command{
+		Use:   "delete <snapshot_id> ...",
+		Short: "Delete snapshots",
+		Args:  cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client := newClient(cfg)
+			var failures []string
+			for _, id := range args {
+				err := client.DeleteSnapshot(context.Background(), id)
+				if api.IsNotFound(err) {
+					err = errors.New("not found")
+				}
+				if err != nil {
+					logging.Debug("failed to delete snapshot", "id", id, "err", err)
+					failures = append(failures, fmt.Sprintf("%s: %v", id, err))
+					continue
+				}
+				fmt.Fprintf(cmd.OutOrStdout(), "Snapshot with ID %s deleted successfully\n", id)
+			}
+			if len(failures) > 0 {
+				return fmt.Errorf("failed to delete %d of %d snapshots: %s", len(failures), len(args), strings.Join(failures, "; "))
+			}
+			return nil
+		},
+	}
+}
+
+// retention is the policy of snapshot prune. A snapshot is deleted only
+// when it is neither among the keepLast newest of its droplet nor younger
+// than olderThan; a zero value leaves its condition out.
+type retention struct {
+	keepLast  int
+	olderThan time.Duration
+}
+
+func (p retention) String() string {
+	var parts []string
+	if p.keepLast > 0 {
+		parts = append(parts, fmt.Sprintf("the last %d of each droplet", p.keepLast))
+	}
+	if p.olderThan > 0 {
+		parts = append(parts, "those taken in the last "+formatAge(p.olderThan))
+	}
+	return strings.Join(parts, " and ")
+}
+
+// prune returns the snapshots the policy deletes, oldest first within each
+// droplet. Snapshots without a readable creation time are always kept.
+func (p retention) prune(snapshots []godo.Snapshot, now time.Time) []godo.Snapshot {
+	type dated struct {
+		godo.Snapshot
+		created time.Time
+	}
+	var droplets []string
+	byDroplet := map[string][]dated{}
+	for _, s := range snapshots {
+		created, err := time.Parse(time.RFC3339, s.Created)
+		if err != nil {
+			logging.Debug("keeping snapshot with an unreadable creation time", "id", s.ID, "created", s.Created)
+			continue
+		}
+		if _, ok := byDroplet[s.ResourceID]; !ok {
+			droplets = append(droplets, s.ResourceID)
+		}
+		byDroplet[s.ResourceID] = append(byDroplet[s.ResourceID], dated{s, created})
+	}
+
+	var pruned []godo.Snapshot
+	for _, droplet := range droplets {
+		list := byDroplet[droplet]
+		sort.SliceStable(list, func(i, j int) bool { return list[i].created.After(list[j].created) })
+		for i := len(list) - 1; i >= 0; i-- {
+			if (p.keepLast == 0 || i >= p.keepLast) && (p.olderThan == 0 || now.Sub(list[i].created) >= p.olderThan) {
+				pruned = append(pruned, list[i].Snapshot)
+			}
+		}
+	}
+	return pruned
+}
+
+func snapshotPruneCmd(cfg *config.Config, newClient func(*config.Config) api.DropletAPI) *cobra.Command {
+	var tags []string
+	var all, dryRun, yes bool
+	var policy retention
+	var olderThan string
+
+	cmd := &cobra.Command{
+		Use:   "prune [droplet_id_or_name ...]",
+		Short: "Delete old droplet snapshots by a retention policy",
+		Long: `Delete the snapshots of the droplets selected by ID, name or --tag, or of
+every droplet with --all, that fall outside a retention policy.
+
+--keep-last keeps the newest snapshots of each droplet and --older-than only
+deletes snapshots older than an age such as 30d, 2w or 36h. Given both, a
+snapshot is deleted only if it is outside the newest and older than the age.
+--all also prunes the snapshots of droplets that no longer exist.
+
+The snapshots to delete are listed and deleted after confirmation, or with
+--yes, e.g. when run from cron:
+
+  digitalocean-cli droplet snapshot prune --tag web --keep-last 7 --older-than 30d --yes`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 0 && len(tags) == 0 && !all {
+				return errors.New("select droplets by ID, name, --tag or --all")
+			}
+			if all && (len(args) > 0 || len(tags) > 0) {
+				return errors.New("--all cannot be combined with droplets or --tag")
+			}
+			if policy.keepLast < 0 {
+				return fmt.Errorf("invalid --keep-last %d: must not be negative", policy.keepLast)
+			}
+			if olderThan != "" {
+				age, err := parseAge(olderThan)
+				if err != nil {
+					return err
+				}
+				policy.olderThan = age
+			}
+			if policy.keepLast == 0 && policy.olderThan == 0 {
+				return errors.New("set a retention policy with --keep-last, --older-than or both")
+			}
+
+			ctx := context.Background()
+			client := newClient(refreshed(cfg))
+			snapshots, err := selectSnapshots(ctx, client, args, tags, all)
+			if err != nil {
+				return err
+			}
+			pruned := policy.prune(snapshots, time.Now())
+
+			stderr := cmd.ErrOrStderr()
+			if len(pruned) == 0 {
+				fmt.Fprintf(stderr, "Nothing to prune: all %d snapshots are kept\n", len(snapshots))
+				return nil
+			}
+			fmt.Fprintf(stderr, "Keeping %s, %d of %d snapshots are deleted:\n", policy, len(pruned), len(snapshots))
+			for _, s := range pruned {
+				fmt.Fprintf(stderr, "  - %s (%s) of droplet %s, taken %s\n", s.Name, s.ID, s.ResourceID, s.Created)
+			}
+
+			if dryRun {
+				return output.Fprint(cmd.OutOrStdout(), pruned, output.FromConfig(cfg))
+			}
+			if !yes {
+				ok, err := prompt.Confirm(cmd, "Delete these snapshots?")
+				if err != nil {
+					return err
+				}
+				if !ok {
+					return errors.New("prune cancelled")
+				}
+			}
+
+			var deleted []godo.Snapshot
+			var failures []string
+			for _, s := range pruned {
+				if err := client.DeleteSnapshot(ctx, s.ID); err != nil && !api.IsNotFound(err) {
+					logging.Debug("failed to delete snapshot", "id", s.ID, "err", err)
+					failures = append(failures, fmt.Sprintf("%s (%s): %v", s.Name, s.ID, err))
+					continue
+				}
+				deleted = append(deleted, s)
+			}
+			if len(deleted) > 0 {
+				if err := output.Fprint(cmd.OutOrStdout(), deleted, output.FromConfig(cfg)); err != nil {
+					return err
+				}
+			}
+			if len(failures) > 0 {
+				return fmt.Errorf("failed to delete %d of %d snapshots: %s", len(failures), len(pruned), strings.Join(failures, "; "))
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringSliceVar(&tags, "tag", nil, "Select the droplets with this tag")
+	cmd.Flags().BoolVar(&all, "all", false, "Prune the snapshots of every droplet, including deleted ones")
+	cmd.Flags().IntVar(&policy.keepLast, "keep-last", 0, "Keep this many of the newest snapshots of each droplet")
+	cmd.Flags().StringVar(&olderThan, "older-than", "", "Only delete snapshots older than this, e.g. 30d")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Only list the snapshots that would be deleted")
+	cmd.Flags().BoolVarP(&yes, "yes", "y", false, "Delete without asking for confirmation")
+
+	return cmd
+}
+
+// selectSnapshots returns the snapshots of the droplets args and tags
+// select, or of every droplet when all is set.
+func selectSnapshots(ctx context.Context, client api.DropletAPI, args, tags []string, all bool) ([]godo.Snapshot, error) {
+	ids := map[string]bool{}
+	if !all {
+		droplets, err := selectDroplets(ctx, client, args, tags)
+		if err != nil {
+			return nil, err
+		}
+		for _, d := range droplets {
+			ids[strconv.Itoa(d.ID)] = true
+		}
+	}
+
+	snapshots, err := client.ListDropletSnapshots(ctx, api.PageOptions{})
+	if err != nil {
+		logging.Debug("failed to list snapshots", "err", err)
+		return nil, err
+	}
+	selected := []godo.Snapshot{}
+	for _, s := range snapshots {
+		if all || ids[s.ResourceID] {
+			selected = append(selected, s)
+		}
+	}
+	return selected, nil
+}
+
+// parseAge parses a duration that may also be given in days or weeks, such
+// as 30d or 2w.
+func parseAge(s string) (time.Duration, error) {
+	var age time.Duration
+	var err error
+	if n, ok := strings.CutSuffix(s, "d"); ok {
+		age, err = parseUnits(n, 24*time.Hour)
+	} else if n, ok := strings.CutSuffix(s, "w"); ok {
+		age, err = parseUnits(n, 7*24*time.Hour)
+	} else {
+		age, err = time.ParseDuration(s)
+	}
+	if err != nil || age <= 0 {
+		return 0, fmt.Errorf("invalid age %q: use a positive duration such as 30d, 2w or 36h", s)
+	}
+	return age, nil
+}
+
+func parseUnits(n string, unit time.Duration) (time.Duration, error) {
+	count, err := strconv.Atoi(n)
+	if err != nil {
+		return 0, err
+	}
+	return time.Duration(count) * unit, nil
+}
+
+func formatAge(d time.Duration) string {
+	day := 24 * time.Hour
+	if d%day == 0 {
+		return fmt.Sprintf("%dd", d/day)
+	}
+	s := d.String()
+	if strings.HasSuffix(s, "m0s") {
+		s = strings.TrimSuffix(s, "0s")
+	}
+	if strings.HasSuffix(s, "h0m") {
+		s = strings.TrimSuffix(s, "0m")
+	}
+	return s
+}
@@ -0,0 +1,241 @@
+package fakeapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/digitalocean/godo"
+)
+
+// Fixtures is the initial content of a Server, in the API's JSON form so
+// that it can be written by hand or captured from a real account.
+// Resources are seeded as already provisioned.
+type Fixtures struct {
+	Droplets           []godo.Droplet                 `json:"droplets"`
+	Sizes              []godo.Size                    `json:"sizes"`
+	Snapshots          []godo.Snapshot                `json:"snapshots"`
+	Backups            map[int][]godo.Image           `json:"backups"`
+	VPCs               []godo.VPC                     `json:"vpcs"`
+	KubernetesClusters []godo.KubernetesCluster       `json:"kubernetes_clusters"`
+	Databases          []godo.Database                `json:"databases"`
+	Domains            []godo.Domain                  `json:"domains"`
+	DomainRecords      map[string][]godo.DomainRecord `json:"domain_records"`
+	Balance            *godo.Balance                  `json:"balance"`
+}
+
+func LoadFixtures(path string) (*Fixtures, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var fixtures Fixtures
+	if err := json.Unmarshal(data, &fixtures); err != nil {
+		return nil, fmt.Errorf("failed to parse fixtures %s: %w", path, err)
+	}
+	return &fixtures, nil
+}
+
+// DefaultFixtures returns a small account with a few resources of every
+// kind.
+func DefaultFixtures() *Fixtures {
+	created := time.Date(2024, 1, 15, 9, 30, 0, 0, time.UTC)
+	vpc := godo.VPC{
+		ID:         "5a4981aa-9653-4bd1-bef5-d6bff52042e4",
+		URN:        "do:vpc:5a4981aa-9653-4bd1-bef5-d6bff52042e4",
+		Name:       "default-nyc1",
+		RegionSlug: "nyc1",
+		IPRange:    "10.116.0.0/20",
+		Default:    true,
+		CreatedAt:  created,
+	}
+
+	newDroplet := func(id int, name, size string, tags ...string) godo.Droplet {
+		return godo.Droplet{
+			ID:       id,
+			Name:     name,
+			Status:   "active",
+			Memory:   1024,
+			Vcpus:    1,
+			Disk:     25,
+			Region:   &godo.Region{Slug: "nyc1", Name: "New York 1", Available: true},
+			Size:     &godo.Size{Slug: size, Memory: 1024, Vcpus: 1, Disk: 25, PriceMonthly: 6},
+			SizeSlug: size,
+			Image:    &godo.Image{ID: 1000, Slug: "ubuntu-22-04-x64", Name: "22.04 (LTS) x64", Distribution: "Ubuntu"},
+			Networks: &godo.Networks{
+				V4: []godo.NetworkV4{
+					{IPAddress: fmt.Sprintf("198.51.100.%d", id%250+1), Netmask: "255.255.255.0", Gateway: "198.51.100.254", Type: "public"},
+					{IPAddress: fmt.Sprintf("10.116.0.%d", id%250+1), Netmask: "255.255.240.0", Type: "private"},
+				},
+			},
+			Tags:    tags,
+			VPCUUID: vpc.ID,
+			Created: created.Format(time.RFC3339),
+		}
+	}
+
+	newSize := func(slug string, vcpus, memory, disk int, price float64, regions ...string) godo.Size {
+		return godo.Size{
+			Slug:         slug,
+			Vcpus:        vcpus,
+			Memory:       memory,
+			Disk:         disk,
+			Transfer:     float64(memory) / 1024,
+			PriceMonthly: price,
+			PriceHourly:  price / 672,
+			Regions:      regions,
+			Available:    true,
+		}
+	}
+
+	newSnapshot := func(id int, name string, dropletID int, age time.Duration) godo.Snapshot {
+		return godo.Snapshot{
+			ID:            strconv.Itoa(id),
+			Name:          name,
+			ResourceID:    strconv.Itoa(dropletID),
+			ResourceType:  "droplet",
+			Regions:       []string{"nyc1"},
+			MinDiskSize:   25,
+			SizeGigaBytes: 2.36,
+			Created:       created.Add(age).Format(time.RFC3339),
+		}
+	}
+
+	return &Fixtures{
+		Snapshots: []godo.Snapshot{
+			newSnapshot(3164401, "web-1-20240116", 3164444, 24*time.Hour),
+			newSnapshot(3164402, "web-1-20240123", 3164444, 8*24*time.Hour),
+			newSnapshot(3164403, "web-1-20240130", 3164444, 15*24*time.Hour),
+		},
+		Backups: map[int][]godo.Image{
+			3164444: {
+				{ID: 3164411, Name: "web-1 2024-01-20", Type: "backup", Distribution: "Ubuntu", Regions: []string{"nyc1"}, MinDiskSize: 25, SizeGigaBytes: 2.41, Created: created.Add(5 * 24 * time.Hour).Format(time.RFC3339), Status: "available"},
+			},
+		},
+		Sizes: []godo.Size{
+			newSize("s-1vcpu-512mb-10gb", 1, 512, 10, 4, "nyc1", "sfo3"),
+			newSize("s-1vcpu-1gb", 1, 1024, 25, 6, "nyc1", "sfo3", "ams3"),
+			newSize("s-1vcpu-2gb", 1, 2048, 50, 12, "nyc1", "sfo3", "ams3"),
+			newSize("s-2vcpu-2gb", 2, 2048, 60, 18, "nyc1", "sfo3", "ams3"),
+			newSize("s-2vcpu-4gb", 2, 4096, 80, 24, "nyc1", "sfo3", "ams3"),
+			newSize("s-4vcpu-8gb", 4, 8192, 160, 48, "sfo3", "ams3"),
+		},
+		Droplets: []godo.Droplet{
+			newDroplet(3164444, "web-1", "s-1vcpu-1gb", "web", "production"),
+			newDroplet(3164445, "web-2", "s-1vcpu-1gb", "web", "production"),
+			newDroplet(3164446, "worker-1", "s-1vcpu-1gb", "worker"),
+		},
+		VPCs: []godo.VPC{vpc},
+		KubernetesClusters: []godo.KubernetesCluster{
+			{
+				ID:          "bd5f5959-5e1e-4205-a714-a914373942af",
+				Name:        "prod-cluster",
+				RegionSlug:  "nyc1",
+				VersionSlug: "1.29.1-do.0",
+				VPCUUID:     vpc.ID,
+				Endpoint:    "https://bd5f5959-5e1e-4205-a714-a914373942af.k8s.ondigitalocean.com",
+				NodePools: []*godo.KubernetesNodePool{
+					{ID: "cdda885e-7663-40c8-bc74-3a036c66545d", Name: "worker-pool", Size: "s-2vcpu-2gb", Count: 3},
+				},
+				Status:    &godo.KubernetesClusterStatus{State: godo.KubernetesClusterStatusRunning},
+				CreatedAt: created,
+				UpdatedAt: created,
+			},
+		},
+		Databases: []godo.Database{
+			{
+				ID:          "9cc10173-e9ea-4176-9dbc-a4cee4c4ff30",
+				Name:        "prod-db",
+				EngineSlug:  "pg",
+				VersionSlug: "16",
+				SizeSlug:    "db-s-1vcpu-1gb",
+				RegionSlug:  "nyc1",
+				NumNodes:    1,
+				Status:      "online",
+				Connection: &godo.DatabaseConnection{
+					Host:     "prod-db-do-user-0.db.ondigitalocean.com",
+					Port:     25060,
+					User:     "doadmin",
+					Database: "defaultdb",
+					SSL:      true,
+				},
+				PrivateNetworkUUID: vpc.ID,
+				CreatedAt:          created,
+			},
+		},
+		Domains: []godo.Domain{{Name: "example.com", TTL: 1800}},
+		DomainRecords: map[string][]godo.DomainRecord{
+			"example.com": {
+				{Type: "A", Name: "@", Data: "198.51.100.2", TTL: 3600},
+				{Type: "CNAME", Name: "www", Data: "@", TTL: 3600},
+				{Type: "MX", Name: "@", Data: "mail.example.com.", Priority: 10, TTL: 3600},
+				{Type: "TXT", Name: "@", Data: "v=spf1 include:_spf.example.com ~all", TTL: 3600},
+			},
+		},
+		Balance: &godo.Balance{
+			MonthToDateBalance: "23.44",
+			AccountBalance:     "12.23",
+			MonthToDateUsage:   "11.21",
+			GeneratedAt:        created,
+		},
+	}
+}
+
+// seed loads f into s. Every domain also gets the SOA and NS records the
+// API creates, and records are numbered in order.
+func (s *Server) seed(f *Fixtures) {
+	for _, d := range f.Droplets {
+		if d.ID > s.lastID {
+			s.lastID = d.ID
+		}
+	}
+	for _, d := range f.Droplets {
+		s.droplets = append(s.droplets, &droplet{Droplet: d})
+	}
+	s.sizes = append(s.sizes, f.Sizes...)
+	for i := range f.Snapshots {
+		snapshot := f.Snapshots[i]
+		if id, err := strconv.Atoi(snapshot.ID); err == nil && id > s.lastID {
+			s.lastID = id
+		}
+		s.snapshots = append(s.snapshots, &snapshot)
+	}
+	for id, backups := range f.Backups {
+		s.backups[id] = append(s.backups[id], backups...)
+	}
+	for i := range f.VPCs {
+		v := f.VPCs[i]
+		s.vpcs = append(s.vpcs, &v)
+	}
+	for _, c := range f.KubernetesClusters {
+		s.clusters = append(s.clusters, &cluster{KubernetesCluster: c})
+	}
+	for _, d := range f.Databases {
+		s.databases = append(s.databases, &database{Database: d})
+	}
+	for _, d := range f.Domains {
+		domain := s.addDomain(d.Name)
+		if d.TTL != 0 {
+			domain.TTL = d.TTL
+		}
+	}
+	names := make([]string, 0, len(f.DomainRecords))
+	for name := range f.DomainRecords {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if _, d := s.findDomain(name); d == nil {
+			s.addDomain(name)
+		}
+		for _, r := range f.DomainRecords[name] {
+			s.addRecord(name, r)
+		}
+	}
+	if f.Balance != nil {
+		s.balance = *f.Balance
+	}
+}
@@ -0,0 +1,911 @@
+package fakeapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/digitalocean/godo"
+)
+
+func (s *Server) dropletView(d *droplet) godo.Droplet {
+	view := d.Droplet
+	if view.Status == "new" && s.ready(d.readyAt) {
+		view.Status = "active"
+	}
+	return view
+}
+
+func (s *Server) findDroplet(id string) (int, *droplet) {
+	for i, d := range s.droplets {
+		if strconv.Itoa(d.ID) == id {
+			return i, d
+		}
+	}
+	return -1, nil
+}
+
+func (s *Server) listDroplets(w http.ResponseWriter, r *http.Request, _ ...string) {
+	tag := r.URL.Query().Get("tag_name")
+	list := []godo.Droplet{}
+	for _, d := range s.droplets {
+		if tag == "" || hasTag(d.Tags, tag) {
+			list = append(list, s.dropletView(d))
+		}
+	}
+	writeList(w, r, "droplets", list)
+}
+
+// dropletCreate is the body of a droplet create request. godo sends the
+// image as either a slug or a numeric ID.
+// maxMultiCreate is the most droplets one create request may name.
+const maxMultiCreate = 10
+
+type dropletCreate struct {
+	Name    string          `json:"name"`
+	Names   []string        `json:"names"`
+	Region  string          `json:"region"`
+	Size    string          `json:"size"`
+	Image   json.RawMessage `json:"image"`
+	Tags    []string        `json:"tags"`
+	VPCUUID string          `json:"vpc_uuid"`
+	IPv6    bool            `json:"ipv6"`
+	Backups bool            `json:"backups"`
+
+	Monitoring bool `json:"monitoring"`
+	Volumes    []struct {
+		ID string `json:"id"`
+	} `json:"volumes"`
+}
+
+func (s *Server) createDroplet(w http.ResponseWriter, r *http.Request, _ ...string) {
+	var req dropletCreate
+	if !decode(w, r, &req) {
+		return
+	}
+	names := req.Names
+	if req.Name != "" {
+		names = append(names, req.Name)
+	}
+	if len(names) == 0 || req.Region == "" || req.Size == "" || len(req.Image) == 0 {
+		writeInvalid(w, "name, region, size and image are required.")
+		return
+	}
+	if req.Name != "" && len(req.Names) > 0 {
+		writeInvalid(w, "name and names cannot be used together.")
+		return
+	}
+	if len(names) > maxMultiCreate {
+		writeInvalid(w, fmt.Sprintf("at most %d droplets can be created at once.", maxMultiCreate))
+		return
+	}
+
+	image := &godo.Image{}
+	if err := json.Unmarshal(req.Image, &image.Slug); err != nil {
+		if err := json.Unmarshal(req.Image, &image.ID); err != nil {
+			writeInvalid(w, "image must be a slug or an ID.")
+			return
+		}
+	}
+
+	var views []godo.Droplet
+	var links []godo.LinkAction
+	for _, name := range names {
+		d := s.newDroplet(name, &req, image)
+		a := s.startAction("create", d.ID, req.Region, d.readyAt)
+		views = append(views, s.dropletView(d))
+		links = append(links, godo.LinkAction{ID: a.ID, Rel: "create", HREF: fmt.Sprintf("http://%s/v2/actions/%d", r.Host, a.ID)})
+	}
+
+	body := map[string]interface{}{"links": map[string]interface{}{"actions": links}}
+	if len(req.Names) > 0 {
+		body["droplets"] = views
+	} else {
+		body["droplet"] = views[0]
+	}
+	writeJSON(w, http.StatusAccepted, body)
+}
+
+func (s *Server) newDroplet(name string, req *dropletCreate, image *godo.Image) *droplet {
+	d := &droplet{readyAt: s.readyAt()}
+	d.ID = s.nextID()
+	d.Name = name
+	d.Status = "new"
+	d.Region = &godo.Region{Slug: req.Region, Name: req.Region, Available: true}
+	d.Size = &godo.Size{Slug: req.Size}
+	d.SizeSlug = req.Size
+	d.Image = image
+	d.Tags = append([]string{}, req.Tags...)
+	d.VPCUUID = req.VPCUUID
+	if d.VPCUUID == "" {
+		d.VPCUUID = s.defaultVPC(req.Region)
+	}
+	d.Networks = &godo.Networks{
+		V4: []godo.NetworkV4{
+			{IPAddress: fmt.Sprintf("203.0.113.%d", d.ID%250+1), Netmask: "255.255.255.0", Gateway: "203.0.113.254", Type: "public"},
+			{IPAddress: fmt.Sprintf("10.10.0.%d", d.ID%250+1), Netmask: "255.255.0.0", Type: "private"},
+		},
+	}
+	if req.IPv6 {
+		d.Features = append(d.Features, "ipv6")
+		d.Networks.V6 = []godo.NetworkV6{{IPAddress: fmt.Sprintf("2001:db8::%x", d.ID), Netmask: 64, Type: "public"}}
+	}
+	if req.Backups {
+		d.Features = append(d.Features, "backups")
+	}
+	if req.Monitoring {
+		d.Features = append(d.Features, "monitoring")
+	}
+	for _, v := range req.Volumes {
+		d.VolumeIDs = append(d.VolumeIDs, v.ID)
+	}
+	d.Created = s.now().Format(time.RFC3339)
+	s.droplets = append(s.droplets, d)
+	return d
+}
+
+func (s *Server) getDroplet(w http.ResponseWriter, r *http.Request, params ...string) {
+	_, d := s.findDroplet(params[0])
+	if d == nil {
+		writeNotFound(w)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"droplet": s.dropletView(d)})
+}
+
+func (s *Server) deleteDroplet(w http.ResponseWriter, r *http.Request, params ...string) {
+	i, d := s.findDroplet(params[0])
+	if d == nil {
+		writeNotFound(w)
+		return
+	}
+	s.droplets = append(s.droplets[:i], s.droplets[i+1:]...)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// startAction records an action on a droplet that completes at readyAt.
+func (s *Server) startAction(actionType string, dropletID int, region string, readyAt time.Time) *action {
+	a := &action{readyAt: readyAt}
+	a.ID = s.nextID()
+	a.Type = actionType
+	a.ResourceID = dropletID
+	a.ResourceType = "droplet"
+	a.RegionSlug = region
+	a.StartedAt = &godo.Timestamp{Time: s.now()}
+	s.actions[a.ID] = a
+	return a
+}
+
+func (s *Server) actionView(a *action) godo.Action {
+	view := a.Action
+	view.Status = godo.ActionInProgress
+	if s.ready(a.readyAt) {
+		view.Status = godo.ActionCompleted
+		view.CompletedAt = &godo.Timestamp{Time: a.readyAt}
+	}
+	return view
+}
+
+func (s *Server) getAction(w http.ResponseWriter, r *http.Request, params ...string) {
+	id, _ := strconv.Atoi(params[0])
+	a, ok := s.actions[id]
+	if !ok {
+		writeNotFound(w)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"action": s.actionView(a)})
+}
+
+func (s *Server) listDropletActions(w http.ResponseWriter, r *http.Request, params ...string) {
+	_, d := s.findDroplet(params[0])
+	if d == nil {
+		writeNotFound(w)
+		return
+	}
+	list := []godo.Action{}
+	for id := 1; id <= s.lastID; id++ {
+		if a, ok := s.actions[id]; ok && a.ResourceID == d.ID {
+			list = append(list, s.actionView(a))
+		}
+	}
+	writeList(w, r, "actions", list)
+}
+
+type dropletAction struct {
+	Type  string          `json:"type"`
+	Image json.RawMessage `json:"image"`
+	Name  string          `json:"name"`
+	Size  string          `json:"size"`
+	Disk  bool            `json:"disk"`
+}
+
+// postDropletAction applies an action to a droplet at once; the action
+// itself completes after the provisioning delay.
+func (s *Server) postDropletAction(w http.ResponseWriter, r *http.Request, params ...string) {
+	_, d := s.findDroplet(params[0])
+	if d == nil {
+		writeNotFound(w)
+		return
+	}
+	var req dropletAction
+	if !decode(w, r, &req) {
+		return
+	}
+
+	switch req.Type {
+	case "power_on", "reboot", "power_cycle":
+		d.Status = "active"
+	case "power_off", "shutdown":
+		d.Status = "off"
+	case "rebuild":
+		image := &godo.Image{}
+		if err := json.Unmarshal(req.Image, &image.Slug); err != nil {
+			if err := json.Unmarshal(req.Image, &image.ID); err != nil {
+				writeInvalid(w, "image must be a slug or an ID.")
+				return
+			}
+		}
+		d.Image = image
+	case "rename":
+		if req.Name == "" {
+			writeInvalid(w, "name is required.")
+			return
+		}
+		d.Name = req.Name
+	case "enable_ipv6", "enable_backups":
+		d.Features = append(d.Features, strings.TrimPrefix(req.Type, "enable_"))
+	case "password_reset":
+	case "resize":
+		if d.Status != "off" {
+			writeInvalid(w, "Droplet is currently on. Please power it off to run this event.")
+			return
+		}
+		size := s.findSize(req.Size)
+		if size == nil {
+			writeInvalid(w, fmt.Sprintf("%q is not a valid size.", req.Size))
+			return
+		}
+		if size.Disk < d.Disk {
+			writeInvalid(w, "This size has a smaller disk than the droplet.")
+			return
+		}
+		d.Size = size
+		d.SizeSlug = size.Slug
+		d.Memory, d.Vcpus = size.Memory, size.Vcpus
+		if req.Disk {
+			d.Disk = size.Disk
+		}
+	case "snapshot":
+		if req.Name == "" {
+			req.Name = fmt.Sprintf("%s-%s", d.Name, s.now().Format("20060102150405"))
+		}
+		s.snapshots = append(s.snapshots, &godo.Snapshot{
+			ID:            strconv.Itoa(s.nextID()),
+			Name:          req.Name,
+			ResourceID:    strconv.Itoa(d.ID),
+			ResourceType:  "droplet",
+			Regions:       []string{},
+			MinDiskSize:   d.Disk,
+			SizeGigaBytes: float64(d.Disk) / 10,
+			Created:       s.now().Format(time.RFC3339),
+		})
+	case "restore":
+		var id int
+		if err := json.Unmarshal(req.Image, &id); err != nil || !s.isBackup(d.ID, id) {
+			writeInvalid(w, "image must be a backup of the droplet.")
+			return
+		}
+	default:
+		writeInvalid(w, fmt.Sprintf("%q is not a valid action type.", req.Type))
+		return
+	}
+
+	region := ""
+	if d.Region != nil {
+		region = d.Region.Slug
+	}
+	a := s.startAction(req.Type, d.ID, region, s.readyAt())
+	writeJSON(w, http.StatusCreated, map[string]interface{}{"action": s.actionView(a)})
+}
+
+func (s *Server) listSizes(w http.ResponseWriter, r *http.Request, _ ...string) {
+	writeList(w, r, "sizes", s.sizes)
+}
+
+// findSize returns a copy of the size with the given slug. Without a
+// catalogue every slug is accepted.
+func (s *Server) findSize(slug string) *godo.Size {
+	if len(s.sizes) == 0 {
+		return &godo.Size{Slug: slug}
+	}
+	for _, size := range s.sizes {
+		if size.Slug == slug {
+			return &size
+		}
+	}
+	return nil
+}
+
+func (s *Server) listBackups(w http.ResponseWriter, r *http.Request, params ...string) {
+	_, d := s.findDroplet(params[0])
+	if d == nil {
+		writeNotFound(w)
+		return
+	}
+	writeList(w, r, "backups", append([]godo.Image{}, s.backups[d.ID]...))
+}
+
+func (s *Server) isBackup(dropletID, imageID int) bool {
+	for _, b := range s.backups[dropletID] {
+		if b.ID == imageID {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Server) findSnapshot(id string) (int, *godo.Snapshot) {
+	for i, snapshot := range s.snapshots {
+		if snapshot.ID == id {
+			return i, snapshot
+		}
+	}
+	return -1, nil
+}
+
+// listSnapshots only holds droplet snapshots, so resource_type=volume
+// lists none.
+func (s *Server) listSnapshots(w http.ResponseWriter, r *http.Request, _ ...string) {
+	list := []*godo.Snapshot{}
+	if t := r.URL.Query().Get("resource_type"); t == "" || t == "droplet" {
+		list = s.snapshots
+	}
+	writeList(w, r, "snapshots", list)
+}
+
+func (s *Server) getSnapshot(w http.ResponseWriter, r *http.Request, params ...string) {
+	_, snapshot := s.findSnapshot(params[0])
+	if snapshot == nil {
+		writeNotFound(w)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"snapshot": snapshot})
+}
+
+func (s *Server) deleteSnapshot(w http.ResponseWriter, r *http.Request, params ...string) {
+	i, snapshot := s.findSnapshot(params[0])
+	if snapshot == nil {
+		writeNotFound(w)
+		return
+	}
+	s.snapshots = append(s.snapshots[:i], s.snapshots[i+1:]...)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func hasTag(tags []string, tag string) bool {
+	for _, t := range tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Server) findVPC(id string) (int, *godo.VPC) {
+	for i, v := range s.vpcs {
+		if v.ID == id {
+			return i, v
+		}
+	}
+	return -1, nil
+}
+
+// defaultVPC returns the ID of the region's default VPC, creating it the
+// way the API does when the first resource lands in a region.
+func (s *Server) defaultVPC(region string) string {
+	for _, v := range s.vpcs {
+		if v.RegionSlug == region && v.Default {
+			return v.ID
+		}
+	}
+	v := &godo.VPC{
+		ID:         s.nextUUID(),
+		Name:       "default-" + region,
+		RegionSlug: region,
+		IPRange:    "10.10.0.0/20",
+		Default:    true,
+		CreatedAt:  s.now(),
+	}
+	v.URN = "do:vpc:" + v.ID
+	s.vpcs = append(s.vpcs, v)
+	return v.ID
+}
+
+func (s *Server) listVPCs(w http.ResponseWriter, r *http.Request, _ ...string) {
+	writeList(w, r, "vpcs", s.vpcs)
+}
+
+func (s *Server) createVPC(w http.ResponseWriter, r *http.Request, _ ...string) {
+	var req godo.VPCCreateRequest
+	if !decode(w, r, &req) {
+		return
+	}
+	if req.Name == "" || req.RegionSlug == "" {
+		writeInvalid(w, "name and region are required.")
+		return
+	}
+	for _, v := range s.vpcs {
+		if v.Name == req.Name {
+			writeInvalid(w, fmt.Sprintf("A VPC named %s already exists.", req.Name))
+			return
+		}
+	}
+
+	v := &godo.VPC{
+		ID:          s.nextUUID(),
+		Name:        req.Name,
+		Description: req.Description,
+		RegionSlug:  req.RegionSlug,
+		IPRange:     req.IPRange,
+		CreatedAt:   s.now(),
+	}
+	if v.IPRange == "" {
+		v.IPRange = fmt.Sprintf("10.%d.0.0/20", 100+s.lastID%100)
+	}
+	v.URN = "do:vpc:" + v.ID
+	s.vpcs = append(s.vpcs, v)
+	writeJSON(w, http.StatusCreated, map[string]interface{}{"vpc": v})
+}
+
+func (s *Server) getVPC(w http.ResponseWriter, r *http.Request, params ...string) {
+	_, v := s.findVPC(params[0])
+	if v == nil {
+		writeNotFound(w)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"vpc": v})
+}
+
+func (s *Server) deleteVPC(w http.ResponseWriter, r *http.Request, params ...string) {
+	i, v := s.findVPC(params[0])
+	if v == nil {
+		writeNotFound(w)
+		return
+	}
+	if v.Default {
+		writeError(w, http.StatusForbidden, "forbidden", "Default VPCs cannot be deleted.")
+		return
+	}
+	for _, d := range s.droplets {
+		if d.VPCUUID == v.ID {
+			writeError(w, http.StatusConflict, "conflict", "VPCs with members cannot be deleted.")
+			return
+		}
+	}
+	s.vpcs = append(s.vpcs[:i], s.vpcs[i+1:]...)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) clusterView(c *cluster) *godo.KubernetesCluster {
+	view := c.KubernetesCluster
+	if c.readyAt.IsZero() {
+		return &view
+	}
+	view.Status = &godo.KubernetesClusterStatus{State: godo.KubernetesClusterStatusProvisioning, Message: "provisioning"}
+	if s.ready(c.readyAt) {
+		view.Status = &godo.KubernetesClusterStatus{State: godo.KubernetesClusterStatusRunning}
+	}
+	return &view
+}
+
+func (s *Server) findCluster(id string) (int, *cluster) {
+	for i, c := range s.clusters {
+		if c.ID == id {
+			return i, c
+		}
+	}
+	return -1, nil
+}
+
+func (s *Server) listClusters(w http.ResponseWriter, r *http.Request, _ ...string) {
+	list := []*godo.KubernetesCluster{}
+	for _, c := range s.clusters {
+		list = append(list, s.clusterView(c))
+	}
+	writeList(w, r, "kubernetes_clusters", list)
+}
+
+func (s *Server) createCluster(w http.ResponseWriter, r *http.Request, _ ...string) {
+	var req godo.KubernetesClusterCreateRequest
+	if !decode(w, r, &req) {
+		return
+	}
+	if req.Name == "" || req.RegionSlug == "" || req.VersionSlug == "" || len(req.NodePools) == 0 {
+		writeInvalid(w, "name, region, version and at least one node pool are required.")
+		return
+	}
+
+	c := &cluster{readyAt: s.readyAt()}
+	c.ID = s.nextUUID()
+	c.Name = req.Name
+	c.RegionSlug = req.RegionSlug
+	c.VersionSlug = req.VersionSlug
+	c.Tags = append([]string{}, req.Tags...)
+	c.VPCUUID = req.VPCUUID
+	if c.VPCUUID == "" {
+		c.VPCUUID = s.defaultVPC(req.RegionSlug)
+	}
+	c.Endpoint = fmt.Sprintf("https://%s.k8s.ondigitalocean.com", c.ID)
+	for _, p := range req.NodePools {
+		c.NodePools = append(c.NodePools, &godo.KubernetesNodePool{
+			ID:    s.nextUUID(),
+			Name:  p.Name,
+			Size:  p.Size,
+			Count: p.Count,
+			Tags:  p.Tags,
+		})
+	}
+	c.CreatedAt = s.now()
+	c.UpdatedAt = c.CreatedAt
+	s.clusters = append(s.clusters, c)
+	writeJSON(w, http.StatusCreated, map[string]interface{}{"kubernetes_cluster": s.clusterView(c)})
+}
+
+func (s *Server) getCluster(w http.ResponseWriter, r *http.Request, params ...string) {
+	_, c := s.findCluster(params[0])
+	if c == nil {
+		writeNotFound(w)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"kubernetes_cluster": s.clusterView(c)})
+}
+
+func (s *Server) deleteCluster(w http.ResponseWriter, r *http.Request, params ...string) {
+	i, c := s.findCluster(params[0])
+	if c == nil {
+		writeNotFound(w)
+		return
+	}
+	s.clusters = append(s.clusters[:i], s.clusters[i+1:]...)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) databaseView(d *database) godo.Database {
+	view := d.Database
+	if d.readyAt.IsZero() {
+		return view
+	}
+	view.Status = "creating"
+	if s.ready(d.readyAt) {
+		view.Status = "online"
+	}
+	return view
+}
+
+func (s *Server) findDatabase(id string) (int, *database) {
+	for i, d := range s.databases {
+		if d.ID == id {
+			return i, d
+		}
+	}
+	return -1, nil
+}
+
+func (s *Server) listDatabases(w http.ResponseWriter, r *http.Request, _ ...string) {
+	list := []godo.Database{}
+	for _, d := range s.databases {
+		list = append(list, s.databaseView(d))
+	}
+	// The databases endpoint is not paginated.
+	writeJSON(w, http.StatusOK, map[string]interface{}{"databases": list})
+}
+
+func (s *Server) createDatabase(w http.ResponseWriter, r *http.Request, _ ...string) {
+	var req godo.DatabaseCreateRequest
+	if !decode(w, r, &req) {
+		return
+	}
+	if req.Name == "" || req.EngineSlug == "" || req.SizeSlug == "" || req.Region == "" {
+		writeInvalid(w, "name, engine, size and region are required.")
+		return
+	}
+
+	d := &database{readyAt: s.readyAt()}
+	d.ID = s.nextUUID()
+	d.Name = req.Name
+	d.EngineSlug = req.EngineSlug
+	d.VersionSlug = req.Version
+	d.SizeSlug = req.SizeSlug
+	d.RegionSlug = req.Region
+	d.NumNodes = req.NumNodes
+	if d.NumNodes == 0 {
+		d.NumNodes = 1
+	}
+	d.Tags = append([]string{}, req.Tags...)
+	d.PrivateNetworkUUID = req.PrivateNetworkUUID
+	if d.PrivateNetworkUUID == "" {
+		d.PrivateNetworkUUID = s.defaultVPC(req.Region)
+	}
+	d.Connection = &godo.DatabaseConnection{
+		Host:     fmt.Sprintf("%s-do-user-0.db.ondigitalocean.com", req.Name),
+		Port:     25060,
+		User:     "doadmin",
+		Database: "defaultdb",
+		SSL:      true,
+	}
+	d.CreatedAt = s.now()
+	s.databases = append(s.databases, d)
+	writeJSON(w, http.StatusCreated, map[string]interface{}{"database": s.databaseView(d)})
+}
+
+func (s *Server) getDatabase(w http.ResponseWriter, r *http.Request, params ...string) {
+	_, d := s.findDatabase(params[0])
+	if d == nil {
+		writeNotFound(w)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"database": s.databaseView(d)})
+}
+
+func (s *Server) deleteDatabase(w http.ResponseWriter, r *http.Request, params ...string) {
+	i, d := s.findDatabase(params[0])
+	if d == nil {
+		writeNotFound(w)
+		return
+	}
+	s.databases = append(s.databases[:i], s.databases[i+1:]...)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) findDomain(name string) (int, *godo.Domain) {
+	for i, d := range s.domains {
+		if strings.EqualFold(d.Name, name) {
+			return i, d
+		}
+	}
+	return -1, nil
+}
+
+func (s *Server) listDomains(w http.ResponseWriter, r *http.Request, _ ...string) {
+	writeList(w, r, "domains", s.domains)
+}
+
+func (s *Server) createDomain(w http.ResponseWriter, r *http.Request, _ ...string) {
+	var req godo.DomainCreateRequest
+	if !decode(w, r, &req) {
+		return
+	}
+	if req.Name == "" {
+		writeInvalid(w, "name is required.")
+		return
+	}
+	if _, d := s.findDomain(req.Name); d != nil {
+		writeInvalid(w, fmt.Sprintf("Domain %s already exists.", req.Name))
+		return
+	}
+
+	d := s.addDomain(req.Name)
+	if req.IPAddress != "" {
+		s.addRecord(d.Name, godo.DomainRecord{Type: "A", Name: "@", Data: req.IPAddress, TTL: d.TTL})
+	}
+	view := *d
+	view.ZoneFile = s.zoneFile(d)
+	writeJSON(w, http.StatusCreated, map[string]interface{}{"domain": view})
+}
+
+// addDomain creates a domain with the SOA and NS records the API adds to
+// every new zone.
+func (s *Server) addDomain(name string) *godo.Domain {
+	d := &godo.Domain{Name: strings.ToLower(name), TTL: 1800}
+	s.domains = append(s.domains, d)
+	s.addRecord(d.Name, godo.DomainRecord{Type: "SOA", Name: "@", Data: "1800", TTL: 1800})
+	for _, ns := range []string{"ns1.digitalocean.com", "ns2.digitalocean.com", "ns3.digitalocean.com"} {
+		s.addRecord(d.Name, godo.DomainRecord{Type: "NS", Name: "@", Data: ns, TTL: 1800})
+	}
+	return d
+}
+
+func (s *Server) getDomain(w http.ResponseWriter, r *http.Request, params ...string) {
+	_, d := s.findDomain(params[0])
+	if d == nil {
+		writeNotFound(w)
+		return
+	}
+	view := *d
+	view.ZoneFile = s.zoneFile(d)
+	writeJSON(w, http.StatusOK, map[string]interface{}{"domain": view})
+}
+
+func (s *Server) deleteDomain(w http.ResponseWriter, r *http.Request, params ...string) {
+	i, d := s.findDomain(params[0])
+	if d == nil {
+		writeNotFound(w)
+		return
+	}
+	s.domains = append(s.domains[:i], s.domains[i+1:]...)
+	delete(s.records, d.Name)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// zoneFile renders the records of d roughly the way the API does.
+func (s *Server) zoneFile(d *godo.Domain) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "$ORIGIN %s.\n$TTL %d\n", d.Name, d.TTL)
+	for _, r := range s.records[d.Name] {
+		data := r.Data
+		switch r.Type {
+		case "SOA":
+			data = fmt.Sprintf("ns1.digitalocean.com. hostmaster.%s. 1 10800 3600 604800 %s", d.Name, r.Data)
+		case "MX":
+			data = fmt.Sprintf("%d %s", r.Priority, r.Data)
+		case "TXT":
+			data = strconv.Quote(r.Data)
+		}
+		fmt.Fprintf(&b, "%s %d IN %s %s\n", r.Name, r.TTL, r.Type, data)
+	}
+	return b.String()
+}
+
+func (s *Server) addRecord(domain string, record godo.DomainRecord) *godo.DomainRecord {
+	record.ID = s.nextID()
+	if record.TTL == 0 {
+		record.TTL = 1800
+	}
+	s.records[domain] = append(s.records[domain], &record)
+	return &record
+}
+
+func (s *Server) findRecord(domain, id string) (int, *godo.DomainRecord) {
+	for i, r := range s.records[domain] {
+		if strconv.Itoa(r.ID) == id {
+			return i, r
+		}
+	}
+	return -1, nil
+}
+
+func (s *Server) listRecords(w http.ResponseWriter, r *http.Request, params ...string) {
+	_, d := s.findDomain(params[0])
+	if d == nil {
+		writeNotFound(w)
+		return
+	}
+	query := r.URL.Query()
+	list := []*godo.DomainRecord{}
+	for _, record := range s.records[d.Name] {
+		if t := query.Get("type"); t != "" && !strings.EqualFold(record.Type, t) {
+			continue
+		}
+		if n := query.Get("name"); n != "" && !strings.EqualFold(recordFQDN(record.Name, d.Name), n) {
+			continue
+		}
+		list = append(list, record)
+	}
+	writeList(w, r, "domain_records", list)
+}
+
+func recordFQDN(name, domain string) string {
+	if name == "@" {
+		return domain
+	}
+	return name + "." + domain
+}
+
+func (s *Server) createRecord(w http.ResponseWriter, r *http.Request, params ...string) {
+	_, d := s.findDomain(params[0])
+	if d == nil {
+		writeNotFound(w)
+		return
+	}
+	var req godo.DomainRecordEditRequest
+	if !decode(w, r, &req) {
+		return
+	}
+	if err := validateRecord(req); err != nil {
+		writeInvalid(w, err.Error())
+		return
+	}
+
+	record := s.addRecord(d.Name, godo.DomainRecord{
+		Type:     strings.ToUpper(req.Type),
+		Name:     req.Name,
+		Data:     req.Data,
+		Priority: req.Priority,
+		Port:     req.Port,
+		TTL:      req.TTL,
+		Weight:   req.Weight,
+		Flags:    req.Flags,
+		Tag:      req.Tag,
+	})
+	writeJSON(w, http.StatusCreated, map[string]interface{}{"domain_record": record})
+}
+
+func (s *Server) getRecord(w http.ResponseWriter, r *http.Request, params ...string) {
+	_, record := s.findRecord(strings.ToLower(params[0]), params[1])
+	if record == nil {
+		writeNotFound(w)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"domain_record": record})
+}
+
+// updateRecord applies the non-empty fields of the request, which covers
+// both PUT and PATCH as godo sends them.
+func (s *Server) updateRecord(w http.ResponseWriter, r *http.Request, params ...string) {
+	_, record := s.findRecord(strings.ToLower(params[0]), params[1])
+	if record == nil {
+		writeNotFound(w)
+		return
+	}
+	var req godo.DomainRecordEditRequest
+	if !decode(w, r, &req) {
+		return
+	}
+
+	updated := *record
+	if req.Type != "" {
+		updated.Type = strings.ToUpper(req.Type)
+	}
+	if req.Name != "" {
+		updated.Name = req.Name
+	}
+	if req.Data != "" {
+		updated.Data = req.Data
+	}
+	if req.TTL != 0 {
+		updated.TTL = req.TTL
+	}
+	updated.Priority = req.Priority
+	updated.Port = req.Port
+	updated.Weight = req.Weight
+	updated.Flags = req.Flags
+	if req.Tag != "" {
+		updated.Tag = req.Tag
+	}
+	if err := validateRecord(godo.DomainRecordEditRequest{Type: updated.Type, Data: updated.Data, Tag: updated.Tag}); err != nil {
+		writeInvalid(w, err.Error())
+		return
+	}
+
+	*record = updated
+	writeJSON(w, http.StatusOK, map[string]interface{}{"domain_record": record})
+}
+
+func (s *Server) deleteRecord(w http.ResponseWriter, r *http.Request, params ...string) {
+	domain := strings.ToLower(params[0])
+	i, record := s.findRecord(domain, params[1])
+	if record == nil {
+		writeNotFound(w)
+		return
+	}
+	s.records[domain] = append(s.records[domain][:i], s.records[domain][i+1:]...)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func validateRecord(req godo.DomainRecordEditRequest) error {
+	switch strings.ToUpper(req.Type) {
+	case "A", "AAAA", "CNAME", "MX", "TXT", "NS", "SRV", "CAA":
+	case "":
+		return fmt.Errorf("type is required.")
+	default:
+		return fmt.Errorf("type %s is not supported.", req.Type)
+	}
+	if req.Data == "" {
+		return fmt.Errorf("data is required.")
+	}
+	if strings.EqualFold(req.Type, "CAA") && req.Tag == "" {
+		return fmt.Errorf("tag is required for CAA records.")
+	}
+	return nil
+}
+
+func (s *Server) getBalance(w http.ResponseWriter, r *http.Request, _ ...string) {
+	balance := s.balance
+	if balance.GeneratedAt.IsZero() {
+		balance.GeneratedAt = s.now()
+	}
+	writeJSON(w, http.StatusOK, balance)
+}
@@ -0,0 +1,332 @@
+// Package fakeapi serves an in-memory imitation of the parts of the
+// DigitalOcean API that api.Client calls, for end-to-end tests and offline
+// development. Point the client's base URL at a Server:
+//
+//	srv := httptest.NewServer(fakeapi.New(fakeapi.Options{Fixtures: fakeapi.DefaultFixtures()}))
+//	cfg.APIURL = srv.URL + "/"
+//
+// List endpoints page like the real API, created resources go through the
+// same provisioning states and droplet creation is tracked by an action.
+package fakeapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/digitalocean/godo"
+)
+
+const (
+	defaultPerPage = 20
+	maxPerPage     = 200
+
+	// rateLimit is reported in the rate limit headers of every response.
+	// The fake never enforces it.
+	rateLimit = 5000
+)
+
+type Options struct {
+	// Fixtures seeds the server. Nil starts it empty.
+	Fixtures *Fixtures
+
+	// ProvisionDelay is how long created droplets, clusters and databases
+	// stay provisioning, and their actions in progress.
+	ProvisionDelay time.Duration
+
+	// Token, when set, is the only bearer token accepted. Otherwise any
+	// non-empty token is.
+	Token string
+
+	// Now replaces the clock, mainly to step through provisioning in tests.
+	Now func() time.Time
+}
+
+// Server is an http.Handler holding every resource in memory. It is safe
+// for concurrent use.
+type Server struct {
+	opts Options
+
+	mu        sync.Mutex
+	lastID    int
+	requests  int
+	droplets  []*droplet
+	actions   map[int]*action
+	sizes     []godo.Size
+	snapshots []*godo.Snapshot
+	backups   map[int][]godo.Image
+	vpcs      []*godo.VPC
+	clusters  []*cluster
+	databases []*database
+	domains   []*godo.Domain
+	records   map[string][]*godo.DomainRecord
+	balance   godo.Balance
+}
+
+// The provisioning state of a resource is derived from readyAt whenever it
+// is rendered.
+
+type droplet struct {
+	godo.Droplet
+	readyAt time.Time
+}
+
+type action struct {
+	godo.Action
+	readyAt time.Time
+}
+
+type cluster struct {
+	godo.KubernetesCluster
+	readyAt time.Time
+}
+
+type database struct {
+	godo.Database
+	readyAt time.Time
+}
+
+func New(opts Options) *Server {
+	if opts.Now == nil {
+		opts.Now = time.Now
+	}
+	s := &Server{
+		opts:    opts,
+		actions: map[int]*action{},
+		backups: map[int][]godo.Image{},
+		records: map[string][]*godo.DomainRecord{},
+	}
+	if opts.Fixtures != nil {
+		s.seed(opts.Fixtures)
+	}
+	return s
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.requests++
+	w.Header().Set("X-Request-Id", fmt.Sprintf("fake-%08d", s.requests))
+	w.Header().Set("RateLimit-Limit", strconv.Itoa(rateLimit))
+	w.Header().Set("RateLimit-Remaining", strconv.Itoa(rateLimit-1))
+	w.Header().Set("RateLimit-Reset", strconv.FormatInt(s.opts.Now().Add(time.Minute).Unix(), 10))
+
+	token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if token == "" || token == r.Header.Get("Authorization") || (s.opts.Token != "" && token != s.opts.Token) {
+		writeError(w, http.StatusUnauthorized, "unauthorized", "Unable to authenticate you.")
+		return
+	}
+
+	path := strings.Trim(strings.TrimPrefix(r.URL.Path, "/v2"), "/")
+	s.route(w, r, strings.Split(path, "/"))
+}
+
+func (s *Server) route(w http.ResponseWriter, r *http.Request, path []string) {
+	switch {
+	case match(path, "droplets"):
+		s.handle(w, r, methods{http.MethodGet: s.listDroplets, http.MethodPost: s.createDroplet})
+	case match(path, "droplets", "*"):
+		s.handle(w, r, methods{http.MethodGet: s.getDroplet, http.MethodDelete: s.deleteDroplet}, path[1])
+	case match(path, "droplets", "*", "actions"):
+		s.handle(w, r, methods{http.MethodGet: s.listDropletActions, http.MethodPost: s.postDropletAction}, path[1])
+	case match(path, "droplets", "*", "backups"):
+		s.handle(w, r, methods{http.MethodGet: s.listBackups}, path[1])
+	case match(path, "actions", "*"):
+		s.handle(w, r, methods{http.MethodGet: s.getAction}, path[1])
+
+	case match(path, "sizes"):
+		s.handle(w, r, methods{http.MethodGet: s.listSizes})
+	case match(path, "snapshots"):
+		s.handle(w, r, methods{http.MethodGet: s.listSnapshots})
+	case match(path, "snapshots", "*"):
+		s.handle(w, r, methods{http.MethodGet: s.getSnapshot, http.MethodDelete: s.deleteSnapshot}, path[1])
+
+	case match(path, "vpcs"):
+		s.handle(w, r, methods{http.MethodGet: s.listVPCs, http.MethodPost: s.createVPC})
+	case match(path, "vpcs", "*"):
+		s.handle(w, r, methods{http.MethodGet: s.getVPC, http.MethodDelete: s.deleteVPC}, path[1])
+
+	case match(path, "kubernetes", "clusters"):
+		s.handle(w, r, methods{http.MethodGet: s.listClusters, http.MethodPost: s.createCluster})
+	case match(path, "kubernetes", "clusters", "*"):
+		s.handle(w, r, methods{http.MethodGet: s.getCluster, http.MethodDelete: s.deleteCluster}, path[2])
+
+	case match(path, "databases"):
+		s.handle(w, r, methods{http.MethodGet: s.listDatabases, http.MethodPost: s.createDatabase})
+	case match(path, "databases", "*"):
+		s.handle(w, r, methods{http.MethodGet: s.getDatabase, http.MethodDelete: s.deleteDatabase}, path[1])
+
+	case match(path, "domains"):
+		s.handle(w, r, methods{http.MethodGet: s.listDomains, http.MethodPost: s.createDomain})
+	case match(path, "domains", "*"):
+		s.handle(w, r, methods{http.MethodGet: s.getDomain, http.MethodDelete: s.deleteDomain}, path[1])
+	case match(path, "domains", "*", "records"):
+		s.handle(w, r, methods{http.MethodGet: s.listRecords, http.MethodPost: s.createRecord}, path[1])
+	case match(path, "domains", "*", "records", "*"):
+		s.handle(w, r, methods{
+			http.MethodGet:    s.getRecord,
+			http.MethodPut:    s.updateRecord,
+			http.MethodPatch:  s.updateRecord,
+			http.MethodDelete: s.deleteRecord,
+		}, path[1], path[3])
+
+	case match(path, "customers", "my", "balance"):
+		s.handle(w, r, methods{http.MethodGet: s.getBalance})
+
+	default:
+		writeNotFound(w)
+	}
+}
+
+// handler serves a request given the wildcard segments of its path.
+type handler func(w http.ResponseWriter, r *http.Request, params ...string)
+
+type methods map[string]handler
+
+func (s *Server) handle(w http.ResponseWriter, r *http.Request, m methods, params ...string) {
+	h, ok := m[r.Method]
+	if !ok {
+		writeError(w, http.StatusMethodNotAllowed, "method_not_allowed", fmt.Sprintf("%s is not allowed on %s.", r.Method, r.URL.Path))
+		return
+	}
+	h(w, r, params...)
+}
+
+// match reports whether path has the given segments, "*" matching any one.
+func match(path []string, segments ...string) bool {
+	if len(path) != len(segments) {
+		return false
+	}
+	for i, segment := range segments {
+		if segment != "*" && segment != path[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func (s *Server) nextID() int {
+	s.lastID++
+	return s.lastID
+}
+
+func (s *Server) nextUUID() string {
+	return fmt.Sprintf("%08x-0000-4000-8000-%012x", s.nextID(), s.lastID)
+}
+
+func (s *Server) now() time.Time {
+	return s.opts.Now().UTC()
+}
+
+func (s *Server) readyAt() time.Time {
+	return s.now().Add(s.opts.ProvisionDelay)
+}
+
+func (s *Server) ready(at time.Time) bool {
+	return !s.now().Before(at)
+}
+
+// paginate returns the page of items selected by the page and per_page
+// query parameters, with links to the other pages.
+func paginate[T any](r *http.Request, items []T) ([]T, *godo.Links, *godo.Meta, error) {
+	query := r.URL.Query()
+	page, perPage := 1, defaultPerPage
+	if v := query.Get("page"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			return nil, nil, nil, fmt.Errorf("invalid page %q", v)
+		}
+		page = n
+	}
+	if v := query.Get("per_page"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			return nil, nil, nil, fmt.Errorf("invalid per_page %q", v)
+		}
+		if n > maxPerPage {
+			n = maxPerPage
+		}
+		perPage = n
+	}
+
+	lastPage := (len(items) + perPage - 1) / perPage
+	if lastPage == 0 {
+		lastPage = 1
+	}
+
+	start := (page - 1) * perPage
+	if start > len(items) {
+		start = len(items)
+	}
+	end := start + perPage
+	if end > len(items) {
+		end = len(items)
+	}
+
+	pages := &godo.Pages{}
+	if page > 1 {
+		pages.First = pageURL(r, 1, perPage)
+		pages.Prev = pageURL(r, page-1, perPage)
+	}
+	if page < lastPage {
+		pages.Next = pageURL(r, page+1, perPage)
+		pages.Last = pageURL(r, lastPage, perPage)
+	}
+
+	return items[start:end], &godo.Links{Pages: pages}, &godo.Meta{Total: len(items)}, nil
+}
+
+func pageURL(r *http.Request, page, perPage int) string {
+	u := url.URL{Scheme: "http", Host: r.Host, Path: r.URL.Path}
+	if r.TLS != nil {
+		u.Scheme = "https"
+	}
+	query := r.URL.Query()
+	query.Set("page", strconv.Itoa(page))
+	query.Set("per_page", strconv.Itoa(perPage))
+	u.RawQuery = query.Encode()
+	return u.String()
+}
+
+func decode(w http.ResponseWriter, r *http.Request, v interface{}) bool {
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		writeError(w, http.StatusBadRequest, "bad_request", fmt.Sprintf("Invalid request body: %v", err))
+		return false
+	}
+	return true
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, id, message string) {
+	writeJSON(w, status, map[string]string{"id": id, "message": message})
+}
+
+func writeNotFound(w http.ResponseWriter) {
+	writeError(w, http.StatusNotFound, "not_found", "The resource you were accessing could not be found.")
+}
+
+func writeInvalid(w http.ResponseWriter, message string) {
+	writeError(w, http.StatusUnprocessableEntity, "unprocessable_entity", message)
+}
+
+// writeList writes the requested page of items under key, as list
+// endpoints do.
+func writeList[T any](w http.ResponseWriter, r *http.Request, key string, items []T) {
+	page, links, meta, err := paginate(r, items)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "bad_request", err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{key: page, "links": links, "meta": meta})
+}
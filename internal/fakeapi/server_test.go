@@ -0,0 +1,342 @@
+package fakeapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/digitalocean/godo"
+	"github.com/felipepimentel/digitalocean-go/internal/api"
+	"github.com/felipepimentel/digitalocean-go/internal/config"
+)
+
+type clock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *clock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *clock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+func newTestServer(t *testing.T, opts Options) *api.Client {
+	t.Helper()
+	server := httptest.NewServer(New(opts))
+	t.Cleanup(server.Close)
+
+	return api.NewClient(&config.Config{DOToken: "test-token", APIURL: server.URL + "/"})
+}
+
+func TestPagination(t *testing.T) {
+	fixtures := &Fixtures{}
+	for i := 1; i <= 7; i++ {
+		fixtures.Droplets = append(fixtures.Droplets, godo.Droplet{ID: i, Name: "d", Status: "active"})
+	}
+	client := newTestServer(t, Options{Fixtures: fixtures})
+
+	droplets, err := client.ListDroplets(context.Background(), api.PageOptions{PerPage: 3})
+	if err != nil {
+		t.Fatalf("ListDroplets: %v", err)
+	}
+	if len(droplets) != 7 {
+		t.Fatalf("Expected 7 droplets across pages, got %d", len(droplets))
+	}
+	for i, d := range droplets {
+		if d.ID != i+1 {
+			t.Errorf("Expected droplet %d at position %d, got %d", i+1, i, d.ID)
+		}
+	}
+
+	_, resp, err := client.Droplets.List(context.Background(), &godo.ListOptions{Page: 3, PerPage: 3})
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if !resp.Links.IsLastPage() {
+		t.Errorf("Expected page 3 to be the last page, got links %+v", resp.Links.Pages)
+	}
+	if resp.Meta.Total != 7 {
+		t.Errorf("Expected a total of 7, got %d", resp.Meta.Total)
+	}
+}
+
+func TestDropletProvisioning(t *testing.T) {
+	c := &clock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	client := newTestServer(t, Options{ProvisionDelay: time.Minute, Now: c.Now})
+	ctx := context.Background()
+
+	droplet, resp, err := client.Droplets.Create(ctx, &godo.DropletCreateRequest{
+		Name:   "web-1",
+		Region: "nyc1",
+		Size:   "s-1vcpu-1gb",
+		Image:  godo.DropletCreateImage{Slug: "ubuntu-22-04-x64"},
+	})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if droplet.Status != "new" {
+		t.Errorf("Expected a new droplet, got status %s", droplet.Status)
+	}
+	if len(resp.Links.Actions) != 1 {
+		t.Fatalf("Expected a create action link, got %+v", resp.Links.Actions)
+	}
+	actionID := resp.Links.Actions[0].ID
+
+	action, _, err := client.Actions.Get(ctx, actionID)
+	if err != nil {
+		t.Fatalf("Get action: %v", err)
+	}
+	if action.Status != godo.ActionInProgress {
+		t.Errorf("Expected the action to be in progress, got %s", action.Status)
+	}
+
+	c.Advance(time.Minute)
+
+	action, _, err = client.Actions.Get(ctx, actionID)
+	if err != nil {
+		t.Fatalf("Get action: %v", err)
+	}
+	if action.Status != godo.ActionCompleted {
+		t.Errorf("Expected the action to be completed, got %s", action.Status)
+	}
+	droplet, _, err = client.Droplets.Get(ctx, droplet.ID)
+	if err != nil {
+		t.Fatalf("Get droplet: %v", err)
+	}
+	if droplet.Status != "active" {
+		t.Errorf("Expected the droplet to be active, got %s", droplet.Status)
+	}
+
+	if err := client.DeleteDroplet(ctx, droplet.ID); err != nil {
+		t.Fatalf("DeleteDroplet: %v", err)
+	}
+	_, _, err = client.Droplets.Get(ctx, droplet.ID)
+	var apiErr *godo.ErrorResponse
+	if !errors.As(err, &apiErr) || apiErr.Response.StatusCode != http.StatusNotFound {
+		t.Errorf("Expected 404 for a deleted droplet, got %v", err)
+	}
+}
+
+func TestMultiCreate(t *testing.T) {
+	client := newTestServer(t, Options{})
+	ctx := context.Background()
+
+	req := &godo.DropletMultiCreateRequest{
+		Names:  []string{"web-1", "web-2"},
+		Region: "nyc1",
+		Size:   "s-1vcpu-1gb",
+		Image:  godo.DropletCreateImage{Slug: "ubuntu-22-04-x64"},
+		Tags:   []string{"web"},
+	}
+	droplets, links, err := client.CreateDroplets(ctx, req)
+	if err != nil {
+		t.Fatalf("CreateDroplets: %v", err)
+	}
+	if len(droplets) != 2 || droplets[1].Name != "web-2" || droplets[1].Tags[0] != "web" {
+		t.Errorf("Unexpected droplets %+v", droplets)
+	}
+	if len(links) != 2 || links[0].Rel != "create" || links[0].ID == links[1].ID {
+		t.Errorf("Expected a create action link per droplet, got %+v", links)
+	}
+
+	req.Names = make([]string, maxMultiCreate+1)
+	for i := range req.Names {
+		req.Names[i] = fmt.Sprintf("worker-%d", i)
+	}
+	if _, _, err := client.CreateDroplets(ctx, req); err == nil {
+		t.Errorf("Expected creating %d droplets at once to fail", len(req.Names))
+	}
+}
+
+func TestWaitForProvisioning(t *testing.T) {
+	c := &clock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	client := newTestServer(t, Options{ProvisionDelay: time.Minute, Now: c.Now})
+	ctx := context.Background()
+
+	droplet, links, err := client.CreateDroplet(ctx, &godo.DropletCreateRequest{
+		Name:   "web-1",
+		Region: "nyc1",
+		Size:   "s-1vcpu-1gb",
+		Image:  godo.DropletCreateImage{Slug: "ubuntu-22-04-x64"},
+	})
+	if err != nil {
+		t.Fatalf("CreateDroplet: %v", err)
+	}
+
+	var statuses []string
+	opts := api.WaitOptions{Timeout: 5 * time.Second, Interval: time.Millisecond, Progress: func(status string) {
+		statuses = append(statuses, status)
+		c.Advance(20 * time.Second)
+	}}
+	if err := api.Wait(ctx, opts, api.DropletReady(client, droplet.ID, links[0].ID)); err != nil {
+		t.Fatalf("Wait: %v", err)
+	}
+	if len(statuses) != 3 || statuses[0] != "create in-progress" {
+		t.Errorf("Expected three polls while the create action ran, got %v", statuses)
+	}
+
+	if err := client.DeleteDroplet(ctx, droplet.ID); err != nil {
+		t.Fatalf("DeleteDroplet: %v", err)
+	}
+	if err := api.Wait(ctx, opts, api.DropletDeleted(client, droplet.ID)); err != nil {
+		t.Errorf("Expected the droplet to be gone, got %v", err)
+	}
+}
+
+func TestDropletActions(t *testing.T) {
+	client := newTestServer(t, Options{Fixtures: DefaultFixtures()})
+	ctx := context.Background()
+
+	action, err := client.RunDropletAction(ctx, 3164444, api.DropletActionRequest{Type: api.ActionPowerOff})
+	if err != nil {
+		t.Fatalf("RunDropletAction: %v", err)
+	}
+	if action.Type != "power_off" || action.ResourceID != 3164444 || action.Status != godo.ActionCompleted {
+		t.Errorf("Unexpected action %+v", action)
+	}
+	droplet, err := client.GetDroplet(ctx, 3164444)
+	if err != nil || droplet.Status != "off" {
+		t.Errorf("Expected the droplet to be off, got %+v, %v", droplet, err)
+	}
+
+	if _, err := client.RunDropletAction(ctx, 3164444, api.DropletActionRequest{Type: api.ActionRename, Name: "web-1-old"}); err != nil {
+		t.Fatalf("RunDropletAction: %v", err)
+	}
+	if droplet, _ := client.GetDroplet(ctx, 3164444); droplet.Name != "web-1-old" {
+		t.Errorf("Expected the droplet to be renamed, got %s", droplet.Name)
+	}
+	if _, err := client.RunDropletAction(ctx, 1, api.DropletActionRequest{Type: api.ActionReboot}); !api.IsNotFound(err) {
+		t.Errorf("Expected a 404 for an unknown droplet, got %v", err)
+	}
+}
+
+func TestDropletResize(t *testing.T) {
+	client := newTestServer(t, Options{Fixtures: DefaultFixtures()})
+	ctx := context.Background()
+
+	sizes, err := client.ListSizes(ctx, api.PageOptions{})
+	if err != nil {
+		t.Fatalf("ListSizes: %v", err)
+	}
+	if len(sizes) == 0 || !sizes[0].Available {
+		t.Errorf("Expected the fixture sizes, got %+v", sizes)
+	}
+
+	resize := api.DropletActionRequest{Type: api.ActionResize, Size: "s-2vcpu-4gb", Disk: true}
+	if _, err := client.RunDropletAction(ctx, 3164444, resize); err == nil {
+		t.Errorf("Expected resizing an active droplet to fail")
+	}
+	if _, err := client.RunDropletAction(ctx, 3164444, api.DropletActionRequest{Type: api.ActionPowerOff}); err != nil {
+		t.Fatalf("RunDropletAction: %v", err)
+	}
+	if _, err := client.RunDropletAction(ctx, 3164444, resize); err != nil {
+		t.Fatalf("RunDropletAction: %v", err)
+	}
+	droplet, err := client.GetDroplet(ctx, 3164444)
+	if err != nil {
+		t.Fatalf("GetDroplet: %v", err)
+	}
+	if droplet.SizeSlug != "s-2vcpu-4gb" || droplet.Disk != 80 || droplet.Vcpus != 2 {
+		t.Errorf("Expected the droplet to be resized with its disk, got %s, %d GB, %d vCPUs", droplet.SizeSlug, droplet.Disk, droplet.Vcpus)
+	}
+
+	resize = api.DropletActionRequest{Type: api.ActionResize, Size: "s-1vcpu-1gb"}
+	if _, err := client.RunDropletAction(ctx, 3164444, resize); err == nil {
+		t.Errorf("Expected resizing to a smaller disk to fail")
+	}
+}
+
+func TestSnapshotsAndBackups(t *testing.T) {
+	client := newTestServer(t, Options{Fixtures: DefaultFixtures()})
+	ctx := context.Background()
+
+	if _, err := client.RunDropletAction(ctx, 3164445, api.DropletActionRequest{Type: api.ActionSnapshot, Name: "web-2-manual"}); err != nil {
+		t.Fatalf("RunDropletAction: %v", err)
+	}
+	snapshots, err := client.ListDropletSnapshots(ctx, api.PageOptions{})
+	if err != nil {
+		t.Fatalf("ListDropletSnapshots: %v", err)
+	}
+	if len(snapshots) != 4 || snapshots[3].Name != "web-2-manual" || snapshots[3].ResourceID != "3164445" {
+		t.Fatalf("Expected the new snapshot after the fixture ones, got %+v", snapshots)
+	}
+	if err := client.DeleteSnapshot(ctx, snapshots[0].ID); err != nil {
+		t.Fatalf("DeleteSnapshot: %v", err)
+	}
+	if err := client.DeleteSnapshot(ctx, snapshots[0].ID); !api.IsNotFound(err) {
+		t.Errorf("Expected a 404 for a deleted snapshot, got %v", err)
+	}
+
+	backups, err := client.ListDropletBackups(ctx, 3164444)
+	if err != nil {
+		t.Fatalf("ListDropletBackups: %v", err)
+	}
+	if len(backups) != 1 {
+		t.Fatalf("Expected the fixture backup, got %+v", backups)
+	}
+	restore := api.DropletActionRequest{Type: api.ActionRestore, Image: godo.DropletCreateImage{ID: backups[0].ID}}
+	if _, err := client.RunDropletAction(ctx, 3164444, restore); err != nil {
+		t.Errorf("RunDropletAction: %v", err)
+	}
+	if _, err := client.RunDropletAction(ctx, 3164445, restore); err == nil {
+		t.Errorf("Expected restoring another droplet's backup to fail")
+	}
+}
+
+func TestDefaultFixtures(t *testing.T) {
+	client := newTestServer(t, Options{Fixtures: DefaultFixtures()})
+	ctx := context.Background()
+
+	records, err := client.ListDomainRecords(ctx, "example.com", api.PageOptions{})
+	if err != nil {
+		t.Fatalf("ListDomainRecords: %v", err)
+	}
+	counts := map[string]int{}
+	for _, r := range records {
+		counts[r.Type]++
+	}
+	if counts["SOA"] != 1 || counts["NS"] != 3 || counts["A"] != 1 {
+		t.Errorf("Unexpected records %v", counts)
+	}
+
+	record, err := client.CreateDomainRecord(ctx, "example.com", &godo.DomainRecordEditRequest{Type: "A", Name: "api", Data: "198.51.100.9"})
+	if err != nil {
+		t.Fatalf("CreateDomainRecord: %v", err)
+	}
+	if err := client.DeleteDomainRecord(ctx, "example.com", record.ID); err != nil {
+		t.Errorf("DeleteDomainRecord: %v", err)
+	}
+
+	balance, err := client.GetBillingInfo(ctx)
+	if err != nil {
+		t.Fatalf("GetBillingInfo: %v", err)
+	}
+	if balance.AccountBalance != "12.23" {
+		t.Errorf("Expected the fixture balance, got %s", balance.AccountBalance)
+	}
+}
+
+func TestUnauthorized(t *testing.T) {
+	server := httptest.NewServer(New(Options{Token: "secret"}))
+	defer server.Close()
+
+	client := api.NewClient(&config.Config{DOToken: "wrong", APIURL: server.URL + "/"})
+	_, err := client.ListVPCs(context.Background(), api.PageOptions{})
+
+	var apiErr *godo.ErrorResponse
+	if !errors.As(err, &apiErr) || apiErr.Response.StatusCode != http.StatusUnauthorized {
+		t.Errorf("Expected 401 for the wrong token, got %v", err)
+	}
+}
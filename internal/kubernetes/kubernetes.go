@@ -6,88 +6,124 @@ import (
 
 	"github.com/felipepimentel/digitalocean-go/internal/api"
 	"github.com/felipepimentel/digitalocean-go/internal/config"
+	"github.com/felipepimentel/digitalocean-go/internal/output"
+	"github.com/felipepimentel/digitalocean-go/internal/progress"
 	"github.com/spf13/cobra"
 )
 
-func Cmd(cfg *config.Config) *cobra.Command {
+func Cmd(cfg *config.Config, newClient func(*config.Config) api.KubernetesAPI) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "kubernetes",
 		Short: "Manage DigitalOcean Kubernetes clusters",
 	}
 
 	cmd.AddCommand(
-		listCmd(cfg),
-		createCmd(cfg),
-		deleteCmd(cfg),
+		listCmd(cfg, newClient),
+		createCmd(cfg, newClient),
+		deleteCmd(cfg, newClient),
 	)
 
 	return cmd
 }
 
-func listCmd(cfg *config.Config) *cobra.Command {
+func listCmd(cfg *config.Config, newClient func(*config.Config) api.KubernetesAPI) *cobra.Command {
 	return &cobra.Command{
 		Use:   "list",
 		Short: "List all Kubernetes clusters",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			client := api.NewClient(cfg)
-			clusters, err := client.ListKubernetesClusters(context.Background())
+			client := newClient(cfg)
+			clusters, err := client.ListKubernetesClusters(context.Background(), api.PageOptionsFromConfig(cfg))
 			if err != nil {
 				return fmt.Errorf("failed to list Kubernetes clusters: %w", err)
 			}
 
-			for _, c := range clusters {
-				fmt.Printf("ID: %s, Name: %s, Region: %s, Version: %s\n", c.ID, c.Name, c.RegionSlug, c.VersionSlug)
-			}
-			return nil
+			return output.Fprint(cmd.OutOrStdout(), clusters, output.FromConfig(cfg))
 		},
 	}
 }
 
-func createCmd(cfg *config.Config) *cobra.Command {
+func createCmd(cfg *config.Config, newClient func(*config.Config) api.KubernetesAPI) *cobra.Command {
 	var name, region, version string
 	var numNodes int
+	var wait progress.WaitFlags
 
 	cmd := &cobra.Command{
 		Use:   "create",
 		Short: "Create a new Kubernetes cluster",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			client := api.NewClient(cfg)
+			if region == "" {
+				region = cfg.Region
+			}
+			if region == "" {
+				return fmt.Errorf("required flag \"region\" not set and the context has no default region")
+			}
+
+			client := newClient(cfg)
 			cluster, err := client.CreateKubernetesCluster(context.Background(), name, region, version, numNodes)
 			if err != nil {
 				return fmt.Errorf("failed to create Kubernetes cluster: %w", err)
 			}
 
-			fmt.Printf("Kubernetes cluster created: ID: %s, Name: %s\n", cluster.ID, cluster.Name)
-			return nil
+			// A failed wait still prints the cluster, as it was created, with
+			// its last known state.
+			var waitErr error
+			if wait.Wait {
+				if err := wait.Run(cmd, fmt.Sprintf("Waiting for Kubernetes cluster %s to be running", name), api.ClusterRunning(client, cluster.ID)); err != nil {
+					waitErr = fmt.Errorf("cluster %s was created but is not running: %w", cluster.ID, err)
+				}
+				current, err := client.GetKubernetesCluster(context.Background(), cluster.ID)
+				if err != nil {
+					if waitErr == nil {
+						waitErr = fmt.Errorf("failed to get Kubernetes cluster: %w", err)
+					}
+				} else {
+					cluster = current
+				}
+			}
+
+			if err := output.Fprint(cmd.OutOrStdout(), cluster, output.FromConfig(cfg)); err != nil {
+				return err
+			}
+			return waitErr
 		},
 	}
 
 	cmd.Flags().StringVar(&name, "name", "", "Name of the Kubernetes cluster")
-	cmd.Flags().StringVar(&region, "region", "", "Region for the Kubernetes cluster")
+	cmd.Flags().StringVar(&region, "region", "", "Region for the Kubernetes cluster (defaults to the context region)")
 	cmd.Flags().StringVar(&version, "version", "", "Kubernetes version")
 	cmd.Flags().IntVar(&numNodes, "nodes", 3, "Number of nodes in the cluster")
 
+	wait.Register(cmd.Flags(), "the cluster is running")
+
 	cmd.MarkFlagRequired("name")
-	cmd.MarkFlagRequired("region")
 	cmd.MarkFlagRequired("version")
 
 	return cmd
 }
 
-func deleteCmd(cfg *config.Config) *cobra.Command {
-	return &cobra.Command{
+func deleteCmd(cfg *config.Config, newClient func(*config.Config) api.KubernetesAPI) *cobra.Command {
+	var wait progress.WaitFlags
+
+	cmd := &cobra.Command{
 		Use:   "delete [cluster_id]",
 		Short: "Delete a Kubernetes cluster",
 		Args:  cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			client := api.NewClient(cfg)
+			client := newClient(cfg)
 			err := client.DeleteKubernetesCluster(context.Background(), args[0])
 			if err != nil {
 				return fmt.Errorf("failed to delete Kubernetes cluster: %w", err)
 			}
+			if err := wait.Run(cmd, fmt.Sprintf("Waiting for Kubernetes cluster %s to be deleted", args[0]), api.ClusterDeleted(client, args[0])); err != nil {
+				return err
+			}
 
-			fmt.Printf("Kubernetes cluster %s deleted\n", args[0])
+			fmt.Fprintf(cmd.OutOrStdout(), "Kubernetes cluster %s deleted\n", args[0])
 			return nil
 		},
 	}
+
+	wait.Register(cmd.Flags(), "the cluster is gone")
+
+	return cmd
 }
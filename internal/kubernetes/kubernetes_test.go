@@ -0,0 +1,62 @@
+package kubernetes
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/felipepimentel/digitalocean-go/internal/api"
+	"github.com/felipepimentel/digitalocean-go/internal/api/apitest"
+	"github.com/felipepimentel/digitalocean-go/internal/api/fake"
+	"github.com/felipepimentel/digitalocean-go/internal/config"
+)
+
+// These tests replay the cassettes in testdata. Re-record them with
+// DO_RECORD=1 go test ./internal/kubernetes.
+
+func TestListReplay(t *testing.T) {
+	out, err := apitest.Run(Cmd(&config.Config{Output: "table"}, apitest.Replay[api.KubernetesAPI](t, "list")), "list")
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	for _, want := range []string{"prod-cluster", "1.29.1-do.0", "running", "worker-pool"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected %q in the output:\n%s", want, out)
+		}
+	}
+}
+
+func TestCreateDeleteReplay(t *testing.T) {
+	newClient := apitest.Replay[api.KubernetesAPI](t, "create_delete")
+	cfg := &config.Config{Output: "json", Region: "nyc1"}
+
+	out, err := apitest.Run(Cmd(cfg, newClient), "create", "--name", "staging", "--version", "1.29.1-do.0", "--nodes", "2")
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if !strings.Contains(out, `"name": "staging"`) || !strings.Contains(out, `"state": "provisioning"`) {
+		t.Errorf("Unexpected create output:\n%s", out)
+	}
+
+	out, err = apitest.Run(Cmd(cfg, newClient), "delete", "bd5f5959-5e1e-4205-a714-a914373942af")
+	if err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if !strings.Contains(out, "bd5f5959-5e1e-4205-a714-a914373942af") {
+		t.Errorf("Unexpected delete output %q", out)
+	}
+}
+
+func TestCreateWaitFailure(t *testing.T) {
+	client := fake.New()
+	client.Errors["GetKubernetesCluster"] = errors.New("boom")
+
+	out, err := apitest.Run(Cmd(&config.Config{Output: "json", Region: "nyc1"}, fake.Factory[api.KubernetesAPI](client)),
+		"create", "--name", "staging", "--version", "1.29.1-do.0", "--wait")
+	if err == nil || !strings.Contains(err.Error(), "was created but is not running: boom") {
+		t.Errorf("Expected the wait error, got %v", err)
+	}
+	if !strings.Contains(out, `"name": "staging"`) {
+		t.Errorf("Expected the created cluster to be printed:\n%s", out)
+	}
+}
@@ -1,16 +1,143 @@
+// Package logging writes diagnostics as levelled, structured records to
+// stderr or a log file. Command results are written to the command's output
+// and never go through it.
 package logging
 
 import (
-	"log"
+	"fmt"
+	"io"
+	"log/slog"
 	"os"
+	"strings"
+	"sync/atomic"
 )
 
+// Formats accepted by Setup.
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+// Options configure the logger, as set by --log-level, --log-format and
+// --log-file. An empty File logs to stderr.
+type Options struct {
+	Level  string
+	Format string
+	File   string
+}
+
 var (
-	InfoLogger  *log.Logger
-	ErrorLogger *log.Logger
+	logger atomic.Pointer[slog.Logger]
+	// file is the log file opened by Setup.
+	file *os.File
 )
 
 func init() {
-	InfoLogger = log.New(os.Stdout, "INFO: ", log.Ldate|log.Ltime|log.Lshortfile)
-	ErrorLogger = log.New(os.Stderr, "ERROR: ", log.Ldate|log.Ltime|log.Lshortfile)
-}
\ No newline at end of file
+	logger.Store(defaultLogger())
+}
+
+func defaultLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelInfo}))
+}
+
+// Setup replaces the logger, closing the log file opened by a previous
+// Setup.
+func Setup(opts Options) error {
+	level, err := ParseLevel(opts.Level)
+	if err != nil {
+		return err
+	}
+
+	var w io.Writer = os.Stderr
+	var f *os.File
+	if opts.File != "" {
+		f, err = os.OpenFile(opts.File, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+		if err != nil {
+			return fmt.Errorf("failed to open log file: %w", err)
+		}
+		w = f
+	}
+
+	handler, err := NewHandler(w, level, opts.Format)
+	if err != nil {
+		if f != nil {
+			f.Close()
+		}
+		return err
+	}
+	logger.Store(slog.New(handler))
+
+	previous := file
+	file = f
+	if previous != nil {
+		previous.Close()
+	}
+	return nil
+}
+
+// Close closes the log file, if any, and logs to stderr from then on.
+func Close() error {
+	if file == nil {
+		return nil
+	}
+	logger.Store(defaultLogger())
+	err := file.Close()
+	file = nil
+	return err
+}
+
+// NewHandler returns a handler writing records at level and above to w in
+// the text or JSON format.
+func NewHandler(w io.Writer, level slog.Level, format string) (slog.Handler, error) {
+	opts := &slog.HandlerOptions{Level: level}
+	switch strings.ToLower(format) {
+	case "", FormatText:
+		return slog.NewTextHandler(w, opts), nil
+	case FormatJSON:
+		return slog.NewJSONHandler(w, opts), nil
+	default:
+		return nil, fmt.Errorf("unknown log format %q: expected text or json", format)
+	}
+}
+
+// ParseLevel parses debug, info, warn or error. An empty level is info.
+func ParseLevel(s string) (slog.Level, error) {
+	switch strings.ToLower(s) {
+	case "debug":
+		return slog.LevelDebug, nil
+	case "", "info":
+		return slog.LevelInfo, nil
+	case "warn", "warning":
+		return slog.LevelWarn, nil
+	case "error":
+		return slog.LevelError, nil
+	default:
+		return 0, fmt.Errorf("unknown log level %q: expected debug, info, warn or error", s)
+	}
+}
+
+// Logger returns the current logger.
+func Logger() *slog.Logger {
+	return logger.Load()
+}
+
+// SetLogger replaces the logger, e.g. to capture records in tests.
+func SetLogger(l *slog.Logger) {
+	logger.Store(l)
+}
+
+func Debug(msg string, args ...any) {
+	Logger().Debug(msg, args...)
+}
+
+func Info(msg string, args ...any) {
+	Logger().Info(msg, args...)
+}
+
+func Warn(msg string, args ...any) {
+	Logger().Warn(msg, args...)
+}
+
+func Error(msg string, args ...any) {
+	Logger().Error(msg, args...)
+}
@@ -0,0 +1,47 @@
+package logging
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSetupLogFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cli.log")
+	if err := Setup(Options{Level: "warn", Format: FormatJSON, File: path}); err != nil {
+		t.Fatalf("Setup: %v", err)
+	}
+
+	Info("not logged")
+	Warn("cache write failed", "key", "work/droplets")
+	if err := Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 1 {
+		t.Fatalf("Expected one record at warn and above, got:\n%s", data)
+	}
+	var record map[string]interface{}
+	if err := json.Unmarshal([]byte(lines[0]), &record); err != nil {
+		t.Fatalf("Expected a JSON record, got %s", lines[0])
+	}
+	if record["level"] != "WARN" || record["msg"] != "cache write failed" || record["key"] != "work/droplets" {
+		t.Errorf("Unexpected record %v", record)
+	}
+}
+
+func TestSetupInvalidOptions(t *testing.T) {
+	if err := Setup(Options{Level: "loud"}); err == nil {
+		t.Error("Expected an error for an unknown level")
+	}
+	if err := Setup(Options{Format: "xml"}); err == nil {
+		t.Error("Expected an error for an unknown format")
+	}
+}
@@ -0,0 +1,449 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/digitalocean/godo"
+)
+
+// Column describes a single table column. Path is a dotted list of field or
+// zero-argument method names resolved against each item, e.g. "Region.Slug"
+// or "PublicIPv4". Slices met along the path are flattened element by element.
+// Wide columns are only shown with the wide output format.
+type Column struct {
+	Header string
+	Path   string
+	Wide   bool
+}
+
+var defaultColumns = map[reflect.Type][]Column{
+	reflect.TypeOf(godo.Droplet{}): {
+		{Header: "ID", Path: "ID"},
+		{Header: "Name", Path: "Name"},
+		{Header: "Status", Path: "Status"},
+		{Header: "Region", Path: "Region.Slug"},
+		{Header: "Size", Path: "SizeSlug"},
+		{Header: "Public IPv4", Path: "PublicIPv4"},
+		{Header: "Tags", Path: "Tags"},
+		{Header: "Private IPv4", Path: "PrivateIPv4", Wide: true},
+		{Header: "Public IPv6", Path: "PublicIPv6", Wide: true},
+		{Header: "Memory", Path: "Memory", Wide: true},
+		{Header: "VCPUs", Path: "Vcpus", Wide: true},
+		{Header: "Disk", Path: "Disk", Wide: true},
+		{Header: "Image", Path: "Image.Slug", Wide: true},
+		{Header: "VPC UUID", Path: "VPCUUID", Wide: true},
+		{Header: "Created", Path: "Created", Wide: true},
+	},
+	reflect.TypeOf(godo.VPC{}): {
+		{Header: "ID", Path: "ID"},
+		{Header: "Name", Path: "Name"},
+		{Header: "Region", Path: "RegionSlug"},
+		{Header: "IP Range", Path: "IPRange"},
+		{Header: "Default", Path: "Default"},
+		{Header: "Description", Path: "Description", Wide: true},
+		{Header: "URN", Path: "URN", Wide: true},
+		{Header: "Created", Path: "CreatedAt", Wide: true},
+	},
+	reflect.TypeOf(godo.KubernetesCluster{}): {
+		{Header: "ID", Path: "ID"},
+		{Header: "Name", Path: "Name"},
+		{Header: "Region", Path: "RegionSlug"},
+		{Header: "Version", Path: "VersionSlug"},
+		{Header: "Status", Path: "Status.State"},
+		{Header: "Node Pools", Path: "NodePools.Name"},
+		{Header: "Nodes", Path: "NodePools.Count", Wide: true},
+		{Header: "Endpoint", Path: "Endpoint", Wide: true},
+		{Header: "VPC UUID", Path: "VPCUUID", Wide: true},
+		{Header: "Tags", Path: "Tags", Wide: true},
+		{Header: "Created", Path: "CreatedAt", Wide: true},
+	},
+	reflect.TypeOf(godo.Database{}): {
+		{Header: "ID", Path: "ID"},
+		{Header: "Name", Path: "Name"},
+		{Header: "Engine", Path: "EngineSlug"},
+		{Header: "Version", Path: "VersionSlug"},
+		{Header: "Region", Path: "RegionSlug"},
+		{Header: "Size", Path: "SizeSlug"},
+		{Header: "Status", Path: "Status"},
+		{Header: "Nodes", Path: "NumNodes", Wide: true},
+		{Header: "Host", Path: "Connection.Host", Wide: true},
+		{Header: "Port", Path: "Connection.Port", Wide: true},
+		{Header: "Tags", Path: "Tags", Wide: true},
+		{Header: "Created", Path: "CreatedAt", Wide: true},
+	},
+	reflect.TypeOf(godo.Domain{}): {
+		{Header: "Name", Path: "Name"},
+		{Header: "TTL", Path: "TTL"},
+	},
+	reflect.TypeOf(godo.DomainRecord{}): {
+		{Header: "ID", Path: "ID"},
+		{Header: "Type", Path: "Type"},
+		{Header: "Name", Path: "Name"},
+		{Header: "Data", Path: "Data"},
+		{Header: "Priority", Path: "Priority"},
+		{Header: "TTL", Path: "TTL"},
+		{Header: "Port", Path: "Port", Wide: true},
+		{Header: "Weight", Path: "Weight", Wide: true},
+		{Header: "Flags", Path: "Flags", Wide: true},
+		{Header: "Tag", Path: "Tag", Wide: true},
+	},
+	reflect.TypeOf(godo.Action{}): {
+		{Header: "ID", Path: "ID"},
+		{Header: "Type", Path: "Type"},
+		{Header: "Status", Path: "Status"},
+		{Header: "Resource ID", Path: "ResourceID"},
+		{Header: "Region", Path: "RegionSlug"},
+		{Header: "Started", Path: "StartedAt.Time", Wide: true},
+		{Header: "Completed", Path: "CompletedAt.Time", Wide: true},
+	},
+	reflect.TypeOf(godo.Snapshot{}): {
+		{Header: "ID", Path: "ID"},
+		{Header: "Name", Path: "Name"},
+		{Header: "Resource ID", Path: "ResourceID"},
+		{Header: "Created", Path: "Created"},
+		{Header: "Size (GB)", Path: "SizeGigaBytes"},
+		{Header: "Regions", Path: "Regions"},
+		{Header: "Min Disk", Path: "MinDiskSize", Wide: true},
+		{Header: "Tags", Path: "Tags", Wide: true},
+	},
+	reflect.TypeOf(godo.Image{}): {
+		{Header: "ID", Path: "ID"},
+		{Header: "Name", Path: "Name"},
+		{Header: "Type", Path: "Type"},
+		{Header: "Created", Path: "Created"},
+		{Header: "Size (GB)", Path: "SizeGigaBytes"},
+		{Header: "Regions", Path: "Regions"},
+		{Header: "Distribution", Path: "Distribution", Wide: true},
+		{Header: "Min Disk", Path: "MinDiskSize", Wide: true},
+		{Header: "Status", Path: "Status", Wide: true},
+	},
+	reflect.TypeOf(godo.Balance{}): {
+		{Header: "Month-to-date Balance", Path: "MonthToDateBalance"},
+		{Header: "Account Balance", Path: "AccountBalance"},
+		{Header: "Month-to-date Usage", Path: "MonthToDateUsage"},
+		{Header: "Generated At", Path: "GeneratedAt"},
+	},
+}
+
+// nameColumns holds the identifier printed by the name output format: the
+// value the get and delete commands accept for each resource type.
+var nameColumns = map[reflect.Type]Column{
+	reflect.TypeOf(godo.Domain{}): {Header: "Name", Path: "Name"},
+}
+
+func (c Column) value(item reflect.Value) string {
+	return resolve(item, strings.Split(c.Path, "."))
+}
+
+// resolveColumns returns the columns to render for data. Selected names are
+// matched against the headers and paths of the type's default columns and
+// fall back to being treated as field paths themselves. Without a selection,
+// wide columns are included only when wide is set.
+func resolveColumns(data interface{}, selected []string, wide bool) ([]Column, error) {
+	typ := elementType(reflect.TypeOf(data))
+	if typ == nil {
+		return nil, fmt.Errorf("cannot render %T as a table", data)
+	}
+
+	defaults, ok := defaultColumns[typ]
+	if !ok {
+		defaults = deriveColumns(typ)
+	}
+	if len(selected) == 0 {
+		if wide {
+			return defaults, nil
+		}
+		columns := make([]Column, 0, len(defaults))
+		for _, column := range defaults {
+			if !column.Wide {
+				columns = append(columns, column)
+			}
+		}
+		return columns, nil
+	}
+
+	columns := make([]Column, 0, len(selected))
+	for _, name := range selected {
+		column, ok := findColumn(defaults, name)
+		if !ok {
+			if !hasPath(typ, strings.Split(name, ".")) {
+				return nil, fmt.Errorf("unknown column %q for %s", name, typ.Name())
+			}
+			column = Column{Header: name, Path: name}
+		}
+		columns = append(columns, column)
+	}
+	return columns, nil
+}
+
+// nameColumn returns the column identifying each item of data.
+func nameColumn(data interface{}) (Column, error) {
+	typ := elementType(reflect.TypeOf(data))
+	if typ == nil {
+		return Column{}, fmt.Errorf("cannot print names of %T", data)
+	}
+	if column, ok := nameColumns[typ]; ok {
+		return column, nil
+	}
+	for _, name := range []string{"ID", "Name"} {
+		if hasPath(typ, []string{name}) {
+			return Column{Header: name, Path: name}, nil
+		}
+	}
+	return Column{}, fmt.Errorf("cannot print names of %s", typ.Name())
+}
+
+func findColumn(columns []Column, name string) (Column, bool) {
+	key := normalize(name)
+	for _, column := range columns {
+		if normalize(column.Header) == key || normalize(column.Path) == key {
+			return column, true
+		}
+	}
+	return Column{}, false
+}
+
+func normalize(name string) string {
+	return strings.NewReplacer(" ", "", "-", "", "_", "").Replace(strings.ToLower(name))
+}
+
+// deriveColumns builds a column set from the scalar exported fields of typ,
+// used for types that have no registered defaults.
+func deriveColumns(typ reflect.Type) []Column {
+	if typ.Kind() != reflect.Struct {
+		return []Column{{Header: "Value", Path: ""}}
+	}
+
+	var columns []Column
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		if !field.IsExported() || !isScalar(field.Type) {
+			continue
+		}
+		columns = append(columns, Column{Header: field.Name, Path: field.Name})
+	}
+	return columns
+}
+
+func isScalar(typ reflect.Type) bool {
+	if typ == timeType {
+		return true
+	}
+	switch typ.Kind() {
+	case reflect.String, reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+var (
+	timeType  = reflect.TypeOf(time.Time{})
+	errorType = reflect.TypeOf((*error)(nil)).Elem()
+)
+
+// elementType returns the struct type a table row is built from: the
+// element type of a slice, with pointers removed.
+func elementType(typ reflect.Type) reflect.Type {
+	if typ == nil {
+		return nil
+	}
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if typ.Kind() == reflect.Slice || typ.Kind() == reflect.Array {
+		typ = typ.Elem()
+		for typ.Kind() == reflect.Ptr {
+			typ = typ.Elem()
+		}
+	}
+	return typ
+}
+
+// elements returns the rows contained in data: every element of a slice, or
+// data itself for a single value.
+func elements(data interface{}) []reflect.Value {
+	v := indirect(reflect.ValueOf(data))
+	if !v.IsValid() {
+		return nil
+	}
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return []reflect.Value{v}
+	}
+
+	items := make([]reflect.Value, 0, v.Len())
+	for i := 0; i < v.Len(); i++ {
+		items = append(items, v.Index(i))
+	}
+	return items
+}
+
+func indirect(v reflect.Value) reflect.Value {
+	for v.IsValid() && (v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface) {
+		if v.IsNil() {
+			return reflect.Value{}
+		}
+		v = v.Elem()
+	}
+	return v
+}
+
+func resolve(v reflect.Value, path []string) string {
+	v = indirect(v)
+	if !v.IsValid() {
+		return ""
+	}
+	if len(path) == 0 || path[0] == "" {
+		return format(v)
+	}
+
+	if v.Kind() == reflect.Slice || v.Kind() == reflect.Array {
+		values := make([]string, 0, v.Len())
+		for i := 0; i < v.Len(); i++ {
+			if s := resolve(v.Index(i), path); s != "" {
+				values = append(values, s)
+			}
+		}
+		return strings.Join(values, ",")
+	}
+
+	next, ok := member(v, path[0])
+	if !ok {
+		return ""
+	}
+	return resolve(next, path[1:])
+}
+
+// member looks up name on v, first as a struct field and then as a method
+// taking no arguments and returning a value and an optional error. Matching
+// is case-insensitive.
+func member(v reflect.Value, name string) (reflect.Value, bool) {
+	if v.Kind() == reflect.Struct {
+		field, ok := v.Type().FieldByNameFunc(func(field string) bool {
+			return strings.EqualFold(field, name)
+		})
+		if ok && field.IsExported() {
+			return v.FieldByIndex(field.Index), true
+		}
+	}
+
+	var ptr reflect.Value
+	if v.CanAddr() {
+		ptr = v.Addr()
+	} else {
+		ptr = reflect.New(v.Type())
+		ptr.Elem().Set(v)
+	}
+
+	method, ok := findMethod(ptr.Type(), name)
+	if !ok {
+		return reflect.Value{}, false
+	}
+	out := ptr.Method(method.Index).Call(nil)
+	if len(out) == 2 && !out[1].IsNil() {
+		return reflect.Value{}, false
+	}
+	return out[0], true
+}
+
+func findMethod(typ reflect.Type, name string) (reflect.Method, bool) {
+	for i := 0; i < typ.NumMethod(); i++ {
+		method := typ.Method(i)
+		if !strings.EqualFold(method.Name, name) {
+			continue
+		}
+		// The receiver counts as the first input.
+		if method.Type.NumIn() != 1 {
+			return reflect.Method{}, false
+		}
+		switch method.Type.NumOut() {
+		case 1:
+			return method, true
+		case 2:
+			return method, method.Type.Out(1) == errorType
+		}
+		return reflect.Method{}, false
+	}
+	return reflect.Method{}, false
+}
+
+// hasPath reports whether path can be resolved against values of typ.
+func hasPath(typ reflect.Type, path []string) bool {
+	for _, name := range path {
+		for typ.Kind() == reflect.Ptr || typ.Kind() == reflect.Slice || typ.Kind() == reflect.Array {
+			typ = typ.Elem()
+		}
+		if typ.Kind() == reflect.Struct {
+			if field, ok := typ.FieldByNameFunc(func(field string) bool {
+				return strings.EqualFold(field, name)
+			}); ok && field.IsExported() {
+				typ = field.Type
+				continue
+			}
+		}
+		method, ok := findMethod(reflect.PointerTo(typ), name)
+		if !ok {
+			return false
+		}
+		typ = method.Type.Out(0)
+	}
+	return true
+}
+
+func format(v reflect.Value) string {
+	v = indirect(v)
+	if !v.IsValid() {
+		return ""
+	}
+
+	if v.Type() == timeType {
+		t := v.Interface().(time.Time)
+		if t.IsZero() {
+			return ""
+		}
+		return t.Format(time.RFC3339)
+	}
+
+	switch v.Kind() {
+	case reflect.Slice, reflect.Array:
+		values := make([]string, 0, v.Len())
+		for i := 0; i < v.Len(); i++ {
+			values = append(values, format(v.Index(i)))
+		}
+		return strings.Join(values, ",")
+	case reflect.Map:
+		values := make([]string, 0, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			values = append(values, format(iter.Key())+"="+format(iter.Value()))
+		}
+		sort.Strings(values)
+		return strings.Join(values, ",")
+	case reflect.Struct:
+		// Nested resources are best identified by their slug or name.
+		for _, name := range []string{"Slug", "Name", "ID"} {
+			if field := v.FieldByName(name); field.IsValid() {
+				return format(field)
+			}
+		}
+		// Anything else, such as a droplet's networks, is spelled out as JSON
+		// so that no detail is lost.
+		if v.CanInterface() {
+			if raw, err := json.Marshal(v.Interface()); err == nil {
+				return string(raw)
+			}
+		}
+	}
+
+	if !v.CanInterface() {
+		return ""
+	}
+	return fmt.Sprint(v.Interface())
+}
@@ -0,0 +1,129 @@
+package output
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/digitalocean/godo"
+)
+
+func testDroplets() []godo.Droplet {
+	return []godo.Droplet{
+		{
+			ID:       1,
+			Name:     "web-1",
+			Status:   "active",
+			Region:   &godo.Region{Slug: "nyc3"},
+			SizeSlug: "s-1vcpu-1gb",
+			Tags:     []string{"web", "prod"},
+			Networks: &godo.Networks{
+				V4: []godo.NetworkV4{
+					{IPAddress: "10.0.0.2", Type: "private"},
+					{IPAddress: "203.0.113.10", Type: "public"},
+				},
+			},
+		},
+	}
+}
+
+func TestGetRowsDefaultColumns(t *testing.T) {
+	columns, err := resolveColumns(testDroplets(), nil, false)
+	if err != nil {
+		t.Fatalf("resolveColumns returned error: %v", err)
+	}
+
+	rows := getRows(testDroplets(), columns)
+	if len(rows) != 1 {
+		t.Fatalf("Expected 1 row, got %d", len(rows))
+	}
+
+	expected := []string{"1", "web-1", "active", "nyc3", "s-1vcpu-1gb", "203.0.113.10", "web,prod"}
+	if strings.Join(rows[0], "|") != strings.Join(expected, "|") {
+		t.Errorf("Expected row %v, got %v", expected, rows[0])
+	}
+}
+
+func TestResolveColumnsSelection(t *testing.T) {
+	columns, err := resolveColumns(testDroplets(), []string{"public-ipv4", "name", "Networks.V4.Type"}, false)
+	if err != nil {
+		t.Fatalf("resolveColumns returned error: %v", err)
+	}
+
+	headers := getHeaders(columns)
+	if strings.Join(headers, "|") != "Public IPv4|Name|Networks.V4.Type" {
+		t.Errorf("Unexpected headers: %v", headers)
+	}
+
+	rows := getRows(testDroplets(), columns)
+	if strings.Join(rows[0], "|") != "203.0.113.10|web-1|private,public" {
+		t.Errorf("Unexpected row: %v", rows[0])
+	}
+
+	if _, err := resolveColumns(testDroplets(), []string{"bogus"}, false); err == nil {
+		t.Error("Expected an error for an unknown column")
+	}
+}
+
+func TestPrintTablePointerElements(t *testing.T) {
+	clusters := []*godo.KubernetesCluster{
+		{
+			ID:     "abc",
+			Name:   "prod",
+			Status: &godo.KubernetesClusterStatus{State: godo.KubernetesClusterStatusRunning},
+			NodePools: []*godo.KubernetesNodePool{
+				{Name: "workers"},
+				{Name: "infra"},
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := Fprint(&buf, clusters, Options{Format: OutputFormatTable}); err != nil {
+		t.Fatalf("Fprint returned error: %v", err)
+	}
+
+	out := buf.String()
+	for _, want := range []string{"abc", "prod", "running", "workers,infra"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected table output to contain %q, got:\n%s", want, out)
+		}
+	}
+}
+
+func TestPrintTableSingleValue(t *testing.T) {
+	var buf bytes.Buffer
+	balance := &godo.Balance{AccountBalance: "12.34"}
+	if err := Fprint(&buf, balance, Options{Format: OutputFormatTable}); err != nil {
+		t.Fatalf("Fprint returned error: %v", err)
+	}
+
+	if !strings.Contains(buf.String(), "12.34") {
+		t.Errorf("Expected table output to contain the balance, got:\n%s", buf.String())
+	}
+}
+
+func TestPrintNamesAndWide(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Fprint(&buf, testDroplets(), Options{Format: OutputFormatName}); err != nil {
+		t.Fatalf("Fprint returned error: %v", err)
+	}
+	if buf.String() != "1\n" {
+		t.Errorf("Expected droplet ID, got %q", buf.String())
+	}
+
+	buf.Reset()
+	domains := []godo.Domain{{Name: "example.com", TTL: 1800}}
+	if err := Fprint(&buf, domains, Options{Format: OutputFormatName}); err != nil {
+		t.Fatalf("Fprint returned error: %v", err)
+	}
+	if buf.String() != "example.com\n" {
+		t.Errorf("Expected domain name, got %q", buf.String())
+	}
+
+	narrow, _ := resolveColumns(testDroplets(), nil, false)
+	wide, _ := resolveColumns(testDroplets(), nil, true)
+	if len(wide) <= len(narrow) {
+		t.Errorf("Expected wide output to add columns, got %d and %d", len(narrow), len(wide))
+	}
+}
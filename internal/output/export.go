@@ -0,0 +1,101 @@
+package output
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strings"
+)
+
+// tsvEscaper escapes the characters that would otherwise end a TSV field or
+// record.
+var tsvEscaper = strings.NewReplacer(`\`, `\\`, "\t", `\t`, "\n", `\n`, "\r", `\r`)
+
+// printCSV writes data as CSV using the table's column model.
+// Spreadsheets have no width constraints, so the wide column set is used
+// unless columns are selected explicitly. List values are joined with
+// commas and the writer quotes any cell that needs it.
+func printCSV(w io.Writer, data interface{}, selected []string) error {
+	columns, err := resolveColumns(data, selected, true)
+	if err != nil {
+		return err
+	}
+
+	writer := csv.NewWriter(w)
+	if err := writer.Write(getHeaders(columns)); err != nil {
+		return err
+	}
+	if err := writer.WriteAll(getRows(data, columns)); err != nil {
+		return err
+	}
+	return writer.Error()
+}
+
+// printTSV writes data as tab-separated values with the same columns as
+// CSV. Fields are never quoted; tabs, newlines and backslashes in them are
+// escaped as \t, \n and \\ instead.
+func printTSV(w io.Writer, data interface{}, selected []string) error {
+	columns, err := resolveColumns(data, selected, true)
+	if err != nil {
+		return err
+	}
+
+	for _, row := range append([][]string{getHeaders(columns)}, getRows(data, columns)...) {
+		for i, field := range row {
+			row[i] = tsvEscaper.Replace(field)
+		}
+		if _, err := io.WriteString(w, strings.Join(row, "\t")+"\n"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// printNDJSON writes one compact JSON document per item. With selected
+// columns each document maps the column headers to their rendered values,
+// in the order the columns were given; otherwise the full item is written.
+func printNDJSON(w io.Writer, data interface{}, selected []string) error {
+	var columns []Column
+	if len(selected) > 0 {
+		var err error
+		if columns, err = resolveColumns(data, selected, true); err != nil {
+			return err
+		}
+	}
+
+	encoder := json.NewEncoder(w)
+	for _, item := range elements(data) {
+		if columns == nil {
+			if err := encoder.Encode(item.Interface()); err != nil {
+				return err
+			}
+			continue
+		}
+
+		// A map would sort the keys, so the object is assembled by hand.
+		var record bytes.Buffer
+		record.WriteByte('{')
+		for i, column := range columns {
+			if i > 0 {
+				record.WriteByte(',')
+			}
+			key, err := json.Marshal(column.Header)
+			if err != nil {
+				return err
+			}
+			value, err := json.Marshal(column.value(item))
+			if err != nil {
+				return err
+			}
+			record.Write(key)
+			record.WriteByte(':')
+			record.Write(value)
+		}
+		record.WriteString("}\n")
+		if _, err := w.Write(record.Bytes()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
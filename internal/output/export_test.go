@@ -0,0 +1,77 @@
+package output
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/digitalocean/godo"
+)
+
+func TestPrintCSVQuotesListFields(t *testing.T) {
+	var buf bytes.Buffer
+	opts := Options{Format: OutputFormatCSV, Columns: []string{"id", "name", "tags"}}
+	if err := Fprint(&buf, testDroplets(), opts); err != nil {
+		t.Fatalf("Fprint returned error: %v", err)
+	}
+
+	expected := "ID,Name,Tags\n1,web-1,\"web,prod\"\n"
+	if buf.String() != expected {
+		t.Errorf("Expected %q, got %q", expected, buf.String())
+	}
+}
+
+func TestPrintTSVNestedFields(t *testing.T) {
+	var buf bytes.Buffer
+	opts := Options{Format: OutputFormatTSV, Columns: []string{"id", "Networks"}}
+	if err := Fprint(&buf, testDroplets(), opts); err != nil {
+		t.Fatalf("Fprint returned error: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 || lines[0] != "ID\tNetworks" {
+		t.Fatalf("Unexpected TSV output: %q", buf.String())
+	}
+	if !strings.HasPrefix(lines[1], "1\t{") || !strings.Contains(lines[1], `"ip_address":"203.0.113.10"`) {
+		t.Errorf("Expected networks as plain JSON, got %q", lines[1])
+	}
+}
+
+func TestPrintTSVEscapes(t *testing.T) {
+	var buf bytes.Buffer
+	records := []godo.DomainRecord{{ID: 1, Type: "TXT", Name: "@", Data: "a\tb\nc\\d \"e\""}}
+	opts := Options{Format: OutputFormatTSV, Columns: []string{"id", "data"}}
+	if err := Fprint(&buf, records, opts); err != nil {
+		t.Fatalf("Fprint returned error: %v", err)
+	}
+
+	expected := "ID\tData\n1\ta\\tb\\nc\\\\d \"e\"\n"
+	if buf.String() != expected {
+		t.Errorf("Expected %q, got %q", expected, buf.String())
+	}
+}
+
+func TestPrintNDJSON(t *testing.T) {
+	var buf bytes.Buffer
+	records := []godo.DomainRecord{
+		{ID: 1, Type: "A", Name: "@", Data: "203.0.113.10"},
+		{ID: 2, Type: "TXT", Name: "@", Data: "v=spf1 -all"},
+	}
+	if err := Fprint(&buf, records, Options{Format: OutputFormatNDJSON}); err != nil {
+		t.Fatalf("Fprint returned error: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 || !strings.Contains(lines[1], `"data":"v=spf1 -all"`) {
+		t.Errorf("Unexpected NDJSON output: %q", buf.String())
+	}
+
+	buf.Reset()
+	opts := Options{Format: OutputFormatNDJSON, Columns: []string{"type", "data"}}
+	if err := Fprint(&buf, records, opts); err != nil {
+		t.Fatalf("Fprint returned error: %v", err)
+	}
+	if !strings.HasPrefix(buf.String(), `{"Type":"A","Data":"203.0.113.10"}`+"\n") {
+		t.Errorf("Unexpected NDJSON column output: %q", buf.String())
+	}
+}
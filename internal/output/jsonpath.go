@@ -0,0 +1,556 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// jsonPath is a parsed kubectl-style JSONPath template such as
+// `{range [*]}{.name}{"\t"}{.networks.v4[?(@.type=="public")].ip_address}{"\n"}{end}`.
+// It is evaluated against the JSON form of the data, so paths use the API
+// field names rather than the Go ones.
+type jsonPath struct {
+	nodes []jsonPathNode
+}
+
+type jsonPathNode interface{}
+
+type textNode string
+
+type exprNode []pathStep
+
+type rangeNode struct {
+	path  []pathStep
+	nodes []jsonPathNode
+}
+
+type pathStep interface{}
+
+type (
+	rootStep      struct{}
+	fieldStep     string
+	recursiveStep string
+	wildcardStep  struct{}
+	indexStep     int
+	sliceStep     struct{ start, end *int }
+	filterStep    struct {
+		path  []pathStep
+		op    string
+		value interface{}
+	}
+)
+
+func parseJSONPath(template string) (*jsonPath, error) {
+	root := &rangeNode{}
+	stack := []*rangeNode{root}
+
+	for len(template) > 0 {
+		open := strings.IndexByte(template, '{')
+		if open < 0 {
+			open = len(template)
+		}
+		if open > 0 {
+			top := stack[len(stack)-1]
+			top.nodes = append(top.nodes, textNode(template[:open]))
+			template = template[open:]
+			continue
+		}
+
+		end, err := matchingBrace(template)
+		if err != nil {
+			return nil, err
+		}
+		expr := strings.TrimSpace(template[1:end])
+		template = template[end+1:]
+
+		top := stack[len(stack)-1]
+		switch {
+		case expr == "end":
+			if len(stack) == 1 {
+				return nil, fmt.Errorf("jsonpath: {end} without {range}")
+			}
+			stack = stack[:len(stack)-1]
+		case strings.HasPrefix(expr, "range "):
+			path, err := parsePath(strings.TrimSpace(strings.TrimPrefix(expr, "range ")))
+			if err != nil {
+				return nil, err
+			}
+			node := &rangeNode{path: path}
+			top.nodes = append(top.nodes, node)
+			stack = append(stack, node)
+		case strings.HasPrefix(expr, `"`):
+			text, err := strconv.Unquote(expr)
+			if err != nil {
+				return nil, fmt.Errorf("jsonpath: invalid string literal %s", expr)
+			}
+			top.nodes = append(top.nodes, textNode(text))
+		default:
+			path, err := parsePath(expr)
+			if err != nil {
+				return nil, err
+			}
+			top.nodes = append(top.nodes, exprNode(path))
+		}
+	}
+
+	if len(stack) != 1 {
+		return nil, fmt.Errorf("jsonpath: {range} without {end}")
+	}
+	return &jsonPath{nodes: root.nodes}, nil
+}
+
+// matchingBrace returns the index of the brace closing the one at s[0],
+// skipping over quoted strings.
+func matchingBrace(s string) (int, error) {
+	depth := 0
+	var quote byte
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case quote != 0:
+			if c == '\\' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '{':
+			depth++
+		case c == '}':
+			depth--
+			if depth == 0 {
+				return i, nil
+			}
+		}
+	}
+	return 0, fmt.Errorf("jsonpath: unclosed expression %q", s)
+}
+
+func parsePath(expr string) ([]pathStep, error) {
+	var steps []pathStep
+	s := expr
+
+	if strings.HasPrefix(s, "$") {
+		steps = append(steps, rootStep{})
+		s = s[1:]
+	}
+
+	for len(s) > 0 {
+		switch {
+		case strings.HasPrefix(s, ".."):
+			name, rest := splitName(s[2:])
+			if name == "" {
+				return nil, fmt.Errorf("jsonpath: missing field name in %q", expr)
+			}
+			steps = append(steps, recursiveStep(name))
+			s = rest
+		case s[0] == '.':
+			name, rest := splitName(s[1:])
+			switch name {
+			case "":
+				// A lone "." refers to the current value.
+			case "*":
+				steps = append(steps, wildcardStep{})
+			default:
+				steps = append(steps, fieldStep(name))
+			}
+			s = rest
+		case s[0] == '[':
+			end, err := matchingBracket(s)
+			if err != nil {
+				return nil, fmt.Errorf("jsonpath: %v in %q", err, expr)
+			}
+			step, err := parseBracket(strings.TrimSpace(s[1:end]))
+			if err != nil {
+				return nil, fmt.Errorf("jsonpath: %v in %q", err, expr)
+			}
+			steps = append(steps, step)
+			s = s[end+1:]
+		default:
+			name, rest := splitName(s)
+			if name == "" {
+				return nil, fmt.Errorf("jsonpath: unexpected %q in %q", s, expr)
+			}
+			steps = append(steps, fieldStep(name))
+			s = rest
+		}
+	}
+
+	return steps, nil
+}
+
+func splitName(s string) (string, string) {
+	i := strings.IndexAny(s, ".[")
+	if i < 0 {
+		return s, ""
+	}
+	return s[:i], s[i:]
+}
+
+func matchingBracket(s string) (int, error) {
+	depth := 0
+	var quote byte
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case quote != 0:
+			if c == '\\' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '[':
+			depth++
+		case c == ']':
+			depth--
+			if depth == 0 {
+				return i, nil
+			}
+		}
+	}
+	return 0, fmt.Errorf("unclosed bracket")
+}
+
+func parseBracket(s string) (pathStep, error) {
+	switch {
+	case s == "*":
+		return wildcardStep{}, nil
+	case strings.HasPrefix(s, "?(") && strings.HasSuffix(s, ")"):
+		return parseFilter(strings.TrimSpace(s[2 : len(s)-1]))
+	case strings.HasPrefix(s, "'") || strings.HasPrefix(s, `"`):
+		name, err := unquote(s)
+		if err != nil {
+			return nil, err
+		}
+		return fieldStep(name), nil
+	case strings.Contains(s, ":"):
+		parts := strings.SplitN(s, ":", 2)
+		var step sliceStep
+		for i, part := range parts {
+			part = strings.TrimSpace(part)
+			if part == "" {
+				continue
+			}
+			n, err := strconv.Atoi(part)
+			if err != nil {
+				return nil, fmt.Errorf("invalid slice bound %q", part)
+			}
+			if i == 0 {
+				step.start = &n
+			} else {
+				step.end = &n
+			}
+		}
+		return step, nil
+	default:
+		n, err := strconv.Atoi(s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid index %q", s)
+		}
+		return indexStep(n), nil
+	}
+}
+
+var filterOps = []string{"==", "!=", "<=", ">=", "<", ">"}
+
+func parseFilter(s string) (pathStep, error) {
+	if !strings.HasPrefix(s, "@") {
+		return nil, fmt.Errorf("filter %q must start with @", s)
+	}
+
+	for _, op := range filterOps {
+		i := strings.Index(s, op)
+		if i < 0 {
+			continue
+		}
+		path, err := parsePath(strings.TrimSpace(s[1:i]))
+		if err != nil {
+			return nil, err
+		}
+		value, err := parseLiteral(strings.TrimSpace(s[i+len(op):]))
+		if err != nil {
+			return nil, err
+		}
+		return filterStep{path: path, op: op, value: value}, nil
+	}
+
+	path, err := parsePath(s[1:])
+	if err != nil {
+		return nil, err
+	}
+	return filterStep{path: path}, nil
+}
+
+func parseLiteral(s string) (interface{}, error) {
+	if strings.HasPrefix(s, "'") || strings.HasPrefix(s, `"`) {
+		return unquote(s)
+	}
+
+	var value interface{}
+	if err := json.Unmarshal([]byte(s), &value); err != nil {
+		return nil, fmt.Errorf("invalid filter value %q", s)
+	}
+	return value, nil
+}
+
+func unquote(s string) (string, error) {
+	if strings.HasPrefix(s, "'") && strings.HasSuffix(s, "'") && len(s) >= 2 {
+		return s[1 : len(s)-1], nil
+	}
+	return strconv.Unquote(s)
+}
+
+func (p *jsonPath) execute(w io.Writer, data interface{}) error {
+	root, err := toJSONValue(data)
+	if err != nil {
+		return err
+	}
+	return executeNodes(w, p.nodes, root, root)
+}
+
+// toJSONValue converts data to the generic form produced by decoding its
+// JSON encoding, keeping numbers exact.
+func toJSONValue(data interface{}) (interface{}, error) {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+func executeNodes(w io.Writer, nodes []jsonPathNode, root, current interface{}) error {
+	for _, node := range nodes {
+		switch node := node.(type) {
+		case textNode:
+			if _, err := io.WriteString(w, string(node)); err != nil {
+				return err
+			}
+		case exprNode:
+			values := evalPath(node, root, current)
+			texts := make([]string, len(values))
+			for i, value := range values {
+				texts[i] = jsonText(value)
+			}
+			if _, err := io.WriteString(w, strings.Join(texts, " ")); err != nil {
+				return err
+			}
+		case *rangeNode:
+			for _, value := range evalPath(node.path, root, current) {
+				// Ranging over a single list iterates its elements.
+				items, ok := value.([]interface{})
+				if !ok {
+					items = []interface{}{value}
+				}
+				for _, item := range items {
+					if err := executeNodes(w, node.nodes, root, item); err != nil {
+						return err
+					}
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func evalPath(steps []pathStep, root, current interface{}) []interface{} {
+	values := []interface{}{current}
+	for _, step := range steps {
+		var next []interface{}
+		for _, value := range values {
+			next = append(next, evalStep(step, root, value)...)
+		}
+		values = next
+	}
+	return values
+}
+
+func evalStep(step pathStep, root, value interface{}) []interface{} {
+	switch step := step.(type) {
+	case rootStep:
+		return []interface{}{root}
+	case fieldStep:
+		if m, ok := value.(map[string]interface{}); ok {
+			if v, ok := m[string(step)]; ok {
+				return []interface{}{v}
+			}
+		}
+	case wildcardStep:
+		return children(value)
+	case recursiveStep:
+		return descendants(value, string(step))
+	case indexStep:
+		if list, ok := value.([]interface{}); ok {
+			i := int(step)
+			if i < 0 {
+				i += len(list)
+			}
+			if i >= 0 && i < len(list) {
+				return []interface{}{list[i]}
+			}
+		}
+	case sliceStep:
+		if list, ok := value.([]interface{}); ok {
+			start, end := 0, len(list)
+			if step.start != nil {
+				start = clampIndex(*step.start, len(list))
+			}
+			if step.end != nil {
+				end = clampIndex(*step.end, len(list))
+			}
+			if start < end {
+				return list[start:end]
+			}
+		}
+	case filterStep:
+		var matched []interface{}
+		for _, child := range children(value) {
+			if step.matches(root, child) {
+				matched = append(matched, child)
+			}
+		}
+		return matched
+	}
+	return nil
+}
+
+func clampIndex(i, n int) int {
+	if i < 0 {
+		i += n
+	}
+	if i < 0 {
+		return 0
+	}
+	if i > n {
+		return n
+	}
+	return i
+}
+
+func children(value interface{}) []interface{} {
+	switch value := value.(type) {
+	case []interface{}:
+		return value
+	case map[string]interface{}:
+		keys := make([]string, 0, len(value))
+		for key := range value {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		values := make([]interface{}, len(keys))
+		for i, key := range keys {
+			values[i] = value[key]
+		}
+		return values
+	}
+	return nil
+}
+
+func descendants(value interface{}, name string) []interface{} {
+	var found []interface{}
+	if m, ok := value.(map[string]interface{}); ok {
+		if v, ok := m[name]; ok {
+			found = append(found, v)
+		}
+	}
+	for _, child := range children(value) {
+		found = append(found, descendants(child, name)...)
+	}
+	return found
+}
+
+func (f filterStep) matches(root, value interface{}) bool {
+	results := evalPath(f.path, root, value)
+	if f.op == "" {
+		return len(results) > 0
+	}
+
+	for _, result := range results {
+		if compare(result, f.op, f.value) {
+			return true
+		}
+	}
+	return false
+}
+
+func compare(left interface{}, op string, right interface{}) bool {
+	if l, ok := toFloat(left); ok {
+		if r, ok := toFloat(right); ok {
+			switch op {
+			case "==":
+				return l == r
+			case "!=":
+				return l != r
+			case "<":
+				return l < r
+			case "<=":
+				return l <= r
+			case ">":
+				return l > r
+			case ">=":
+				return l >= r
+			}
+		}
+	}
+
+	l, r := jsonText(left), jsonText(right)
+	switch op {
+	case "==":
+		return l == r
+	case "!=":
+		return l != r
+	case "<":
+		return l < r
+	case "<=":
+		return l <= r
+	case ">":
+		return l > r
+	case ">=":
+		return l >= r
+	}
+	return false
+}
+
+func toFloat(value interface{}) (float64, bool) {
+	switch value := value.(type) {
+	case json.Number:
+		f, err := value.Float64()
+		return f, err == nil
+	case float64:
+		return value, true
+	}
+	return 0, false
+}
+
+// jsonText renders a value the way kubectl does: strings and numbers
+// verbatim, everything else as compact JSON.
+func jsonText(value interface{}) string {
+	switch value := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return value
+	case json.Number:
+		return value.String()
+	}
+
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(raw)
+}
@@ -3,8 +3,11 @@ package output
 import (
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
+	"strings"
 
+	"github.com/felipepimentel/digitalocean-go/internal/config"
 	"github.com/olekukonko/tablewriter"
 	"gopkg.in/yaml.v2"
 )
@@ -12,51 +15,194 @@ import (
 type OutputFormat string
 
 const (
-	OutputFormatJSON  OutputFormat = "json"
-	OutputFormatYAML  OutputFormat = "yaml"
-	OutputFormatTable OutputFormat = "table"
+	OutputFormatJSON   OutputFormat = "json"
+	OutputFormatYAML   OutputFormat = "yaml"
+	OutputFormatTable  OutputFormat = "table"
+	OutputFormatWide   OutputFormat = "wide"
+	OutputFormatName   OutputFormat = "name"
+	OutputFormatCSV    OutputFormat = "csv"
+	OutputFormatTSV    OutputFormat = "tsv"
+	OutputFormatNDJSON OutputFormat = "ndjson"
+
+	// The following formats take an argument after "=", e.g.
+	// jsonpath={.name}.
+	OutputFormatGoTemplate     OutputFormat = "go-template"
+	OutputFormatGoTemplateFile OutputFormat = "go-template-file"
+	OutputFormatJSONPath       OutputFormat = "jsonpath"
 )
 
+// Options controls how data is rendered. Columns selects and orders the
+// table columns; an empty list means the default set for the data type.
+type Options struct {
+	Format  OutputFormat
+	Columns []string
+}
+
+var formats = []OutputFormat{
+	OutputFormatJSON,
+	OutputFormatYAML,
+	OutputFormatTable,
+	OutputFormatWide,
+	OutputFormatName,
+	OutputFormatCSV,
+	OutputFormatTSV,
+	OutputFormatNDJSON,
+}
+
+var templateFormats = []OutputFormat{
+	OutputFormatGoTemplate,
+	OutputFormatGoTemplateFile,
+	OutputFormatJSONPath,
+}
+
+// ParseFormat validates a format given on the command line. Template formats
+// are parsed up front so that syntax errors are reported before any API call.
+func ParseFormat(s string) (OutputFormat, error) {
+	format := OutputFormat(s)
+	name, arg := format.split()
+
+	for _, f := range formats {
+		if f == format {
+			return format, nil
+		}
+	}
+
+	for _, f := range templateFormats {
+		if f != name {
+			continue
+		}
+		if arg == "" {
+			return "", fmt.Errorf("output format %s requires an argument, e.g. %s=...", name, name)
+		}
+		var err error
+		switch name {
+		case OutputFormatGoTemplate:
+			_, err = parseTemplate(arg)
+		case OutputFormatGoTemplateFile:
+			var text string
+			if text, err = readTemplateFile(arg); err == nil {
+				_, err = parseTemplate(text)
+			}
+		case OutputFormatJSONPath:
+			_, err = parseJSONPath(arg)
+		}
+		if err != nil {
+			return "", err
+		}
+		return format, nil
+	}
+
+	return "", fmt.Errorf("unsupported output format: %s", s)
+}
+
+// split separates a format from its argument, if any.
+func (f OutputFormat) split() (OutputFormat, string) {
+	name, arg, _ := strings.Cut(string(f), "=")
+	return OutputFormat(name), arg
+}
+
+// FromConfig returns the options selected by the global --output and
+// --columns flags.
+func FromConfig(cfg *config.Config) Options {
+	return Options{
+		Format:  OutputFormat(cfg.Output),
+		Columns: cfg.Columns,
+	}
+}
+
 func Print(data interface{}, format OutputFormat) error {
-	switch format {
+	return Fprint(os.Stdout, data, Options{Format: format})
+}
+
+func Fprint(w io.Writer, data interface{}, opts Options) error {
+	name, arg := opts.Format.split()
+	switch name {
 	case OutputFormatJSON:
-		return printJSON(data)
+		return printJSON(w, data)
 	case OutputFormatYAML:
-		return printYAML(data)
+		return printYAML(w, data)
 	case OutputFormatTable:
-		return printTable(data)
+		return printTable(w, data, opts.Columns, false)
+	case OutputFormatWide:
+		return printTable(w, data, opts.Columns, true)
+	case OutputFormatName:
+		return printNames(w, data)
+	case OutputFormatCSV:
+		return printCSV(w, data, opts.Columns)
+	case OutputFormatTSV:
+		return printTSV(w, data, opts.Columns)
+	case OutputFormatNDJSON:
+		return printNDJSON(w, data, opts.Columns)
+	case OutputFormatGoTemplate:
+		return printTemplate(w, data, arg)
+	case OutputFormatGoTemplateFile:
+		text, err := readTemplateFile(arg)
+		if err != nil {
+			return err
+		}
+		return printTemplate(w, data, text)
+	case OutputFormatJSONPath:
+		return printJSONPath(w, data, arg)
 	default:
-		return fmt.Errorf("unsupported output format: %s", format)
+		return fmt.Errorf("unsupported output format: %s", opts.Format)
 	}
 }
 
-func printJSON(data interface{}) error {
-	encoder := json.NewEncoder(os.Stdout)
+func printJSON(w io.Writer, data interface{}) error {
+	encoder := json.NewEncoder(w)
 	encoder.SetIndent("", "  ")
 	return encoder.Encode(data)
 }
 
-func printYAML(data interface{}) error {
-	return yaml.NewEncoder(os.Stdout).Encode(data)
+func printYAML(w io.Writer, data interface{}) error {
+	return yaml.NewEncoder(w).Encode(data)
 }
 
-func printTable(data interface{}) error {
-	table := tablewriter.NewWriter(os.Stdout)
-	table.SetHeader(getHeaders(data))
-	table.AppendBulk(getRows(data))
+func printTable(w io.Writer, data interface{}, selected []string, wide bool) error {
+	columns, err := resolveColumns(data, selected, wide)
+	if err != nil {
+		return err
+	}
+
+	table := tablewriter.NewWriter(w)
+	table.SetAutoWrapText(false)
+	table.SetHeader(getHeaders(columns))
+	table.AppendBulk(getRows(data, columns))
 	table.Render()
 	return nil
 }
 
-func getHeaders(data interface{}) []string {
-	// Implement this function to return the appropriate headers based on the data type
-	return []string{"ID", "Name", "Status"}
+func printNames(w io.Writer, data interface{}) error {
+	column, err := nameColumn(data)
+	if err != nil {
+		return err
+	}
+
+	for _, item := range elements(data) {
+		if _, err := fmt.Fprintln(w, column.value(item)); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
-func getRows(data interface{}) [][]string {
-	// Implement this function to return the appropriate rows based on the data type
-	return [][]string{
-		{"1", "Example", "Active"},
-		{"2", "Test", "Inactive"},
+func getHeaders(columns []Column) []string {
+	headers := make([]string, len(columns))
+	for i, column := range columns {
+		headers[i] = column.Header
 	}
-}
\ No newline at end of file
+	return headers
+}
+
+func getRows(data interface{}, columns []Column) [][]string {
+	items := elements(data)
+	rows := make([][]string, 0, len(items))
+	for _, item := range items {
+		row := make([]string, len(columns))
+		for i, column := range columns {
+			row[i] = column.value(item)
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
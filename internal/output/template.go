@@ -0,0 +1,67 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/template"
+)
+
+// templateFuncs are available to go-template and go-template-file formats
+// in addition to the text/template builtins.
+var templateFuncs = template.FuncMap{
+	"json": func(v interface{}) (string, error) {
+		raw, err := json.Marshal(v)
+		return string(raw), err
+	},
+	"join": func(sep string, values []string) string {
+		return strings.Join(values, sep)
+	},
+	"contains": func(values []string, s string) bool {
+		for _, value := range values {
+			if value == s {
+				return true
+			}
+		}
+		return false
+	},
+	"upper": strings.ToUpper,
+	"lower": strings.ToLower,
+}
+
+func parseTemplate(text string) (*template.Template, error) {
+	tmpl, err := template.New("output").Funcs(templateFuncs).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid go-template: %w", err)
+	}
+	return tmpl, nil
+}
+
+func readTemplateFile(path string) (string, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read template file: %w", err)
+	}
+	return string(raw), nil
+}
+
+// printTemplate executes a Go template against data as returned by the API
+// client, so fields and methods use their Go names, e.g.
+// {{range .}}{{if contains .Tags "web"}}{{.PublicIPv4}}{{"\n"}}{{end}}{{end}}.
+func printTemplate(w io.Writer, data interface{}, text string) error {
+	tmpl, err := parseTemplate(text)
+	if err != nil {
+		return err
+	}
+	return tmpl.Execute(w, data)
+}
+
+func printJSONPath(w io.Writer, data interface{}, text string) error {
+	path, err := parseJSONPath(text)
+	if err != nil {
+		return err
+	}
+	return path.execute(w, data)
+}
@@ -0,0 +1,75 @@
+package output
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/digitalocean/godo"
+)
+
+func taggedDroplets() []godo.Droplet {
+	droplets := testDroplets()
+	droplets = append(droplets, godo.Droplet{
+		ID:   2,
+		Name: "db-1",
+		Tags: []string{"db"},
+		Networks: &godo.Networks{
+			V4: []godo.NetworkV4{{IPAddress: "203.0.113.20", Type: "public"}},
+		},
+	})
+	return droplets
+}
+
+func TestGoTemplate(t *testing.T) {
+	var buf bytes.Buffer
+	format := OutputFormat(`go-template={{range .}}{{if contains .Tags "web"}}{{.PublicIPv4}}{{"\n"}}{{end}}{{end}}`)
+	if err := Fprint(&buf, taggedDroplets(), Options{Format: format}); err != nil {
+		t.Fatalf("Fprint returned error: %v", err)
+	}
+
+	if buf.String() != "203.0.113.10\n" {
+		t.Errorf("Unexpected template output: %q", buf.String())
+	}
+}
+
+func TestJSONPath(t *testing.T) {
+	tests := []struct {
+		template string
+		expected string
+	}{
+		{`{[*].name}`, "web-1 db-1"},
+		{`{[0].networks.v4[?(@.type=="public")].ip_address}`, "203.0.113.10"},
+		{`{range [*]}{.id}{"\t"}{.tags[0]}{"\n"}{end}`, "1\tweb\n2\tdb\n"},
+		{`{[?(@.id>1)].name}`, "db-1"},
+		{`{..ip_address}`, "10.0.0.2 203.0.113.10 203.0.113.20"},
+		{`{[-1:].name}`, "db-1"},
+		{`names: {$[*].name}`, "names: web-1 db-1"},
+	}
+
+	for _, tt := range tests {
+		var buf bytes.Buffer
+		if err := Fprint(&buf, taggedDroplets(), Options{Format: OutputFormat("jsonpath=" + tt.template)}); err != nil {
+			t.Errorf("%s: Fprint returned error: %v", tt.template, err)
+			continue
+		}
+		if buf.String() != tt.expected {
+			t.Errorf("%s: expected %q, got %q", tt.template, tt.expected, buf.String())
+		}
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	valid := []string{"json", "wide", "jsonpath={.name}", "go-template={{.Name}}"}
+	for _, s := range valid {
+		if _, err := ParseFormat(s); err != nil {
+			t.Errorf("Expected %q to be valid, got %v", s, err)
+		}
+	}
+
+	invalid := []string{"xml", "jsonpath=", "jsonpath={range .x}", "go-template={{.Name", "go-template-file=/nonexistent"}
+	for _, s := range invalid {
+		if _, err := ParseFormat(s); err == nil {
+			t.Errorf("Expected %q to be rejected", s)
+		}
+	}
+}
@@ -0,0 +1,93 @@
+// Package progress shows the progress of long-running commands, such as
+// waiting for a droplet to become active.
+package progress
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"golang.org/x/term"
+)
+
+var frames = []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"}
+
+const frameInterval = 100 * time.Millisecond
+
+// Spinner animates a message and the latest status on a single line. It
+// only draws when its writer is a terminal, so that redirected output and
+// logs stay clean.
+type Spinner struct {
+	w       io.Writer
+	message string
+	enabled bool
+
+	mu     sync.Mutex
+	status string
+	stop   chan struct{}
+	done   chan struct{}
+}
+
+func NewSpinner(w io.Writer, message string) *Spinner {
+	return &Spinner{w: w, message: message, enabled: isTerminal(w)}
+}
+
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	return ok && term.IsTerminal(int(f.Fd()))
+}
+
+// Start starts drawing in the background until Stop is called.
+func (s *Spinner) Start() {
+	if !s.enabled || s.stop != nil {
+		return
+	}
+	s.stop = make(chan struct{})
+	s.done = make(chan struct{})
+
+	go func() {
+		defer close(s.done)
+		ticker := time.NewTicker(frameInterval)
+		defer ticker.Stop()
+		for i := 0; ; i++ {
+			s.draw(frames[i%len(frames)])
+			select {
+			case <-s.stop:
+				fmt.Fprint(s.w, "\r\033[K")
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Update sets the status shown after the message.
+func (s *Spinner) Update(status string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.status = status
+}
+
+// Stop stops drawing and clears the line.
+func (s *Spinner) Stop() {
+	if s.stop == nil {
+		return
+	}
+	close(s.stop)
+	<-s.done
+	s.stop = nil
+}
+
+func (s *Spinner) draw(frame string) {
+	s.mu.Lock()
+	status := s.status
+	s.mu.Unlock()
+
+	line := frame + " " + s.message
+	if status != "" {
+		line += " (" + status + ")"
+	}
+	fmt.Fprint(s.w, "\r\033[K"+line)
+}
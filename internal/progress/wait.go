@@ -0,0 +1,45 @@
+package progress
+
+import (
+	"time"
+
+	"github.com/felipepimentel/digitalocean-go/internal/api"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// WaitFlags are the --wait and --wait-timeout flags of commands that start
+// asynchronous operations.
+type WaitFlags struct {
+	Wait    bool
+	Timeout time.Duration
+}
+
+// Register adds the flags; what completes "Wait until ...".
+func (f *WaitFlags) Register(flags *pflag.FlagSet, what string) {
+	flags.BoolVar(&f.Wait, "wait", false, "Wait until "+what)
+	flags.DurationVar(&f.Timeout, "wait-timeout", api.DefaultWaitOptions.Timeout, "How long --wait waits before giving up")
+}
+
+// Run waits for check when --wait is set, showing message and the latest
+// status on a spinner on stderr.
+func (f *WaitFlags) Run(cmd *cobra.Command, message string, check api.Check) error {
+	if !f.Wait {
+		return nil
+	}
+	return f.WaitFor(cmd, message, check)
+}
+
+// WaitFor waits for check like Run, whether or not --wait is set, for steps
+// a command cannot go on without.
+func (f *WaitFlags) WaitFor(cmd *cobra.Command, message string, check api.Check) error {
+	opts := api.DefaultWaitOptions
+	opts.Timeout = f.Timeout
+
+	spinner := NewSpinner(cmd.ErrOrStderr(), message)
+	opts.Progress = spinner.Update
+	spinner.Start()
+	defer spinner.Stop()
+
+	return api.Wait(cmd.Context(), opts, check)
+}
@@ -0,0 +1,45 @@
+package vpc
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/felipepimentel/digitalocean-go/internal/api"
+	"github.com/felipepimentel/digitalocean-go/internal/api/apitest"
+	"github.com/felipepimentel/digitalocean-go/internal/config"
+)
+
+// These tests replay the cassettes in testdata. Re-record them with
+// DO_RECORD=1 go test ./internal/vpc.
+
+func TestListReplay(t *testing.T) {
+	out, err := apitest.Run(Cmd(&config.Config{Output: "table"}, apitest.Replay[api.VPCAPI](t, "list")), "list")
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if !strings.Contains(out, "default-nyc1") || !strings.Contains(out, "10.116.0.0/20") {
+		t.Errorf("Unexpected list output:\n%s", out)
+	}
+}
+
+func TestCreateDeleteReplay(t *testing.T) {
+	newClient := apitest.Replay[api.VPCAPI](t, "create_delete")
+	cfg := &config.Config{Output: "name", Region: "ams3"}
+
+	out, err := apitest.Run(Cmd(cfg, newClient), "create", "--name", "staging", "--ip-range", "10.20.0.0/20")
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	id := strings.TrimSpace(out)
+	if id == "" {
+		t.Fatal("Expected create to print the VPC ID")
+	}
+
+	out, err = apitest.Run(Cmd(cfg, newClient), "delete", id)
+	if err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if !strings.Contains(out, id) {
+		t.Errorf("Unexpected delete output %q", out)
+	}
+}
@@ -0,0 +1,154 @@
+package zonefile
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+type token struct {
+	text string
+	// quoted tokens are character-strings, never names or directives.
+	quoted bool
+}
+
+// line is a logical line of a master file: parentheses join physical
+// lines, and comments are dropped.
+type line struct {
+	number int
+	tokens []token
+	// blankOwner is set when the line starts with whitespace, repeating the
+	// previous owner name.
+	blankOwner bool
+}
+
+// readLines splits a master file into logical lines of tokens.
+func readLines(r io.Reader) ([]line, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	var lines []line
+	var current *line
+	depth := 0
+	number := 0
+	for scanner.Scan() {
+		number++
+		text := scanner.Text()
+		if current == nil {
+			current = &line{number: number, blankOwner: text != "" && (text[0] == ' ' || text[0] == '\t')}
+		}
+
+		tokens, opened, err := tokenize(text)
+		if err != nil {
+			return nil, &ParseError{Line: number, Err: err}
+		}
+		current.tokens = append(current.tokens, tokens...)
+		depth += opened
+		if depth < 0 {
+			return nil, &ParseError{Line: number, Err: fmt.Errorf("unbalanced )")}
+		}
+		if depth > 0 {
+			continue
+		}
+
+		if len(current.tokens) > 0 {
+			lines = append(lines, *current)
+		}
+		current = nil
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if depth > 0 {
+		return nil, &ParseError{Line: current.number, Err: fmt.Errorf("unbalanced (")}
+	}
+	return lines, nil
+}
+
+// tokenize splits a physical line into tokens, returning how many more
+// parentheses it opens than closes.
+func tokenize(text string) ([]token, int, error) {
+	var tokens []token
+	var b strings.Builder
+	depth := 0
+	inWord := false
+
+	flush := func() {
+		if inWord {
+			tokens = append(tokens, token{text: b.String()})
+			b.Reset()
+			inWord = false
+		}
+	}
+
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+		switch {
+		case c == ';':
+			flush()
+			return tokens, depth, nil
+		case c == ' ' || c == '\t' || c == '\r':
+			flush()
+		case c == '(':
+			flush()
+			depth++
+		case c == ')':
+			flush()
+			depth--
+		case c == '"':
+			flush()
+			s, n, err := readQuoted(text[i+1:])
+			if err != nil {
+				return nil, 0, err
+			}
+			tokens = append(tokens, token{text: s, quoted: true})
+			i += n
+		case c == '\\' && i+1 < len(text):
+			b.WriteByte(text[i+1])
+			i++
+			inWord = true
+		default:
+			b.WriteByte(c)
+			inWord = true
+		}
+	}
+	flush()
+	return tokens, depth, nil
+}
+
+// readQuoted reads a character-string up to its closing quote, decoding
+// \X and \DDD escapes. It returns the string and the bytes consumed,
+// including the closing quote.
+func readQuoted(text string) (string, int, error) {
+	var b strings.Builder
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+		switch {
+		case c == '"':
+			return b.String(), i + 1, nil
+		case c == '\\' && i+3 < len(text) && isDigits(text[i+1:i+4]):
+			n := int(text[i+1]-'0')*100 + int(text[i+2]-'0')*10 + int(text[i+3]-'0')
+			if n > 255 {
+				return "", 0, fmt.Errorf("invalid escape \\%s", text[i+1:i+4])
+			}
+			b.WriteByte(byte(n))
+			i += 3
+		case c == '\\' && i+1 < len(text):
+			b.WriteByte(text[i+1])
+			i++
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return "", 0, fmt.Errorf("unterminated quoted string")
+}
+
+func isDigits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,373 @@
+// Package zonefile reads and writes RFC 1035 master files as DigitalOcean
+// domain records, whose names are relative to the domain ("@" for the
+// domain itself).
+package zonefile
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/digitalocean/godo"
+)
+
+// maxStringLen is the longest character-string a TXT record can hold.
+// Longer data is split into several strings, which resolvers concatenate.
+const maxStringLen = 255
+
+// ParseError reports the line of a master file that could not be parsed.
+type ParseError struct {
+	Line int
+	Err  error
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("line %d: %v", e.Line, e.Err)
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// Parse reads the records of the master file r for domain. Names and
+// targets are resolved against $ORIGIN, which starts out as domain, and
+// owners are made relative to domain. Target hostnames are returned fully
+// qualified, except for the domain itself which is "@". Records without a
+// TTL get the $TTL in effect, or else the last TTL given. $INCLUDE and
+// $GENERATE are not supported.
+func Parse(r io.Reader, domain string) ([]godo.DomainRecord, error) {
+	lines, err := readLines(r)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{domain: fqdn(domain), origin: fqdn(domain)}
+	var records []godo.DomainRecord
+	for _, l := range lines {
+		record, ok, err := p.parseLine(l)
+		if err != nil {
+			return nil, &ParseError{Line: l.number, Err: err}
+		}
+		if ok {
+			records = append(records, record)
+		}
+	}
+	return records, nil
+}
+
+type parser struct {
+	domain string
+	origin string
+	// defaultTTL is set by $TTL and lastTTL by the last record with a TTL.
+	defaultTTL int
+	lastTTL    int
+	owner      string
+}
+
+func (p *parser) parseLine(l line) (godo.DomainRecord, bool, error) {
+	tokens := l.tokens
+	if !l.blankOwner && !tokens[0].quoted && strings.HasPrefix(tokens[0].text, "$") {
+		return godo.DomainRecord{}, false, p.directive(tokens)
+	}
+
+	if l.blankOwner {
+		if p.owner == "" {
+			return godo.DomainRecord{}, false, fmt.Errorf("record without an owner name")
+		}
+	} else {
+		p.owner = absolute(tokens[0].text, p.origin)
+		tokens = tokens[1:]
+	}
+	name, err := p.relative(p.owner)
+	if err != nil {
+		return godo.DomainRecord{}, false, err
+	}
+
+	// The TTL and class may come in either order before the type.
+	ttl := -1
+	for i := 0; i < 2 && len(tokens) > 0; i++ {
+		if t, err := parseTTL(tokens[0].text); err == nil && ttl < 0 {
+			ttl = t
+		} else if isClass(tokens[0].text) {
+			if !strings.EqualFold(tokens[0].text, "IN") {
+				return godo.DomainRecord{}, false, fmt.Errorf("unsupported class %s", tokens[0].text)
+			}
+		} else {
+			break
+		}
+		tokens = tokens[1:]
+	}
+	if len(tokens) == 0 {
+		return godo.DomainRecord{}, false, fmt.Errorf("record for %s has no type", p.owner)
+	}
+	switch {
+	case ttl >= 0:
+		p.lastTTL = ttl
+	case p.defaultTTL > 0:
+		ttl = p.defaultTTL
+	default:
+		ttl = p.lastTTL
+	}
+
+	record := godo.DomainRecord{Name: name, Type: strings.ToUpper(tokens[0].text), TTL: ttl}
+	if err := p.rdata(&record, tokens[1:]); err != nil {
+		return godo.DomainRecord{}, false, err
+	}
+	return record, true, nil
+}
+
+func (p *parser) directive(tokens []token) error {
+	switch strings.ToUpper(tokens[0].text) {
+	case "$ORIGIN":
+		if len(tokens) != 2 {
+			return fmt.Errorf("$ORIGIN needs a single name")
+		}
+		p.origin = absolute(tokens[1].text, p.origin)
+	case "$TTL":
+		if len(tokens) != 2 {
+			return fmt.Errorf("$TTL needs a single TTL")
+		}
+		ttl, err := parseTTL(tokens[1].text)
+		if err != nil {
+			return err
+		}
+		p.defaultTTL = ttl
+	default:
+		return fmt.Errorf("unsupported directive %s", tokens[0].text)
+	}
+	return nil
+}
+
+func (p *parser) rdata(r *godo.DomainRecord, fields []token) error {
+	want := map[string]int{"A": 1, "AAAA": 1, "CNAME": 1, "NS": 1, "MX": 2, "SRV": 4, "CAA": 3, "SOA": 7}
+	if n, ok := want[r.Type]; ok && len(fields) != n {
+		return fmt.Errorf("%s record for %s needs %d fields, got %d", r.Type, p.owner, n, len(fields))
+	}
+
+	var err error
+	switch r.Type {
+	case "A", "AAAA":
+		r.Data = fields[0].text
+	case "CNAME", "NS":
+		r.Data = p.target(fields[0].text)
+	case "MX":
+		r.Priority, err = parseUint16(fields[0].text, "MX preference")
+		r.Data = p.target(fields[1].text)
+	case "SRV":
+		if r.Priority, err = parseUint16(fields[0].text, "SRV priority"); err != nil {
+			return err
+		}
+		if r.Weight, err = parseUint16(fields[1].text, "SRV weight"); err != nil {
+			return err
+		}
+		r.Port, err = parseUint16(fields[2].text, "SRV port")
+		r.Data = p.target(fields[3].text)
+	case "CAA":
+		r.Flags, err = strconv.Atoi(fields[0].text)
+		if err != nil || r.Flags < 0 || r.Flags > 255 {
+			return fmt.Errorf("invalid CAA flags %q", fields[0].text)
+		}
+		r.Tag, r.Data = fields[1].text, fields[2].text
+	case "TXT":
+		if len(fields) == 0 {
+			return fmt.Errorf("TXT record for %s has no text", p.owner)
+		}
+		var b strings.Builder
+		for _, f := range fields {
+			b.WriteString(f.text)
+		}
+		r.Data = b.String()
+	case "SOA":
+		// DigitalOcean keeps only the minimum TTL of the SOA record.
+		r.Data = fields[6].text
+	default:
+		return fmt.Errorf("unsupported record type %s", r.Type)
+	}
+	return err
+}
+
+// relative returns name relative to the domain.
+func (p *parser) relative(name string) (string, error) {
+	lower := strings.ToLower(name)
+	if lower == p.domain {
+		return "@", nil
+	}
+	if strings.HasSuffix(lower, "."+p.domain) {
+		return strings.TrimSuffix(lower, "."+p.domain), nil
+	}
+	return "", fmt.Errorf("%s is outside of %s", name, p.domain)
+}
+
+// target qualifies a hostname in record data, keeping "@" for the domain.
+func (p *parser) target(name string) string {
+	name = absolute(name, p.origin)
+	if strings.EqualFold(name, p.domain) {
+		return "@"
+	}
+	return name
+}
+
+// Write writes records as a master file for domain. Target hostnames are
+// written fully qualified, with a trailing dot, as DigitalOcean returns them
+// without one; "@" is kept for the domain itself. TXT data longer than 255
+// bytes is split into several strings. The SOA record, of which
+// DigitalOcean only keeps the minimum TTL, is written with DigitalOcean's
+// primary name server and default timers.
+func Write(w io.Writer, domain string, records []godo.DomainRecord) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "$ORIGIN %s\n", fqdn(domain))
+
+	tw := tabwriter.NewWriter(bw, 0, 8, 1, ' ', 0)
+	for _, r := range sortSOAFirst(records) {
+		data, err := rdata(domain, r, records)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(tw, "%s\t%d\tIN\t%s\t%s\n", r.Name, r.TTL, r.Type, data)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	return bw.Flush()
+}
+
+func rdata(domain string, r godo.DomainRecord, records []godo.DomainRecord) (string, error) {
+	switch r.Type {
+	case "A", "AAAA":
+		return r.Data, nil
+	case "CNAME", "NS":
+		return hostname(r.Data), nil
+	case "MX":
+		return fmt.Sprintf("%d %s", r.Priority, hostname(r.Data)), nil
+	case "SRV":
+		return fmt.Sprintf("%d %d %d %s", r.Priority, r.Weight, r.Port, hostname(r.Data)), nil
+	case "CAA":
+		return fmt.Sprintf("%d %s %s", r.Flags, r.Tag, quote(r.Data)), nil
+	case "TXT":
+		var parts []string
+		data := r.Data
+		for len(data) > maxStringLen {
+			parts = append(parts, quote(data[:maxStringLen]))
+			data = data[maxStringLen:]
+		}
+		parts = append(parts, quote(data))
+		return strings.Join(parts, " "), nil
+	case "SOA":
+		primary := "ns1.digitalocean.com."
+		for _, ns := range records {
+			if ns.Type == "NS" && ns.Name == "@" {
+				primary = hostname(ns.Data)
+				break
+			}
+		}
+		return fmt.Sprintf("%s hostmaster.%s 1 10800 3600 604800 %s", primary, fqdn(domain), r.Data), nil
+	default:
+		return "", fmt.Errorf("cannot write %s record %s", r.Type, r.Name)
+	}
+}
+
+func sortSOAFirst(records []godo.DomainRecord) []godo.DomainRecord {
+	sorted := make([]godo.DomainRecord, 0, len(records))
+	for _, r := range records {
+		if r.Type == "SOA" {
+			sorted = append(sorted, r)
+		}
+	}
+	for _, r := range records {
+		if r.Type != "SOA" {
+			sorted = append(sorted, r)
+		}
+	}
+	return sorted
+}
+
+// quote writes s as a character-string, escaping quotes, backslashes and
+// non-printable bytes.
+func quote(s string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == '"' || c == '\\':
+			b.WriteByte('\\')
+			b.WriteByte(c)
+		case c < ' ' || c > '~':
+			fmt.Fprintf(&b, "\\%03d", c)
+		default:
+			b.WriteByte(c)
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
+
+// hostname writes a target hostname fully qualified. Without the trailing
+// dot a parser would qualify it again with $ORIGIN.
+func hostname(name string) string {
+	if name == "@" || strings.HasSuffix(name, ".") {
+		return name
+	}
+	return name + "."
+}
+
+func fqdn(name string) string {
+	return strings.ToLower(strings.TrimSuffix(name, ".")) + "."
+}
+
+// absolute qualifies name with origin unless it already ends in a dot.
+func absolute(name, origin string) string {
+	switch {
+	case name == "@":
+		return origin
+	case strings.HasSuffix(name, "."):
+		return name
+	default:
+		return name + "." + origin
+	}
+}
+
+func isClass(s string) bool {
+	switch strings.ToUpper(s) {
+	case "IN", "CH", "HS", "CS":
+		return true
+	}
+	return false
+}
+
+// parseTTL parses a TTL in seconds or with BIND's unit suffixes, e.g. 1h30m.
+func parseTTL(s string) (int, error) {
+	if n, err := strconv.Atoi(s); err == nil && n >= 0 {
+		return n, nil
+	}
+	units := map[byte]int{'s': 1, 'm': 60, 'h': 3600, 'd': 86400, 'w': 604800}
+	total, n, digits := 0, 0, false
+	for i := 0; i < len(s); i++ {
+		c := s[i] | 0x20
+		switch {
+		case s[i] >= '0' && s[i] <= '9':
+			n = n*10 + int(s[i]-'0')
+			digits = true
+		case units[c] > 0 && digits:
+			total += n * units[c]
+			n, digits = 0, false
+		default:
+			return 0, fmt.Errorf("invalid TTL %q", s)
+		}
+	}
+	if digits || s == "" {
+		return 0, fmt.Errorf("invalid TTL %q", s)
+	}
+	return total, nil
+}
+
+func parseUint16(s, what string) (int, error) {
+	n, err := strconv.Atoi(s)
+	if err != nil || n < 0 || n > 65535 {
+		return 0, fmt.Errorf("invalid %s %q", what, s)
+	}
+	return n, nil
+}
@@ -0,0 +1,105 @@
+package zonefile
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/digitalocean/godo"
+)
+
+const zone = `$ORIGIN example.com.
+$TTL 1h
+@	IN	SOA	ns1.example.net. hostmaster.example.com. (
+		2024010101 ; serial
+		7200 3600 1209600 300 )
+	IN	NS	ns1.example.net.
+@	300	IN	A	198.51.100.7
+www		CNAME	@
+mail.example.com. IN 600 MX 10 mail
+	TXT	"v=spf1 mx" " -all" ; joined
+_sip._tcp 86400 IN SRV 10 5 5060 sip.example.net.
+@	CAA	0 issue "letsencrypt.org"
+
+$ORIGIN staging.example.com.
+api	AAAA	2001:db8::1
+key	TXT	"quote \" and \\ and \059"
+`
+
+func TestParse(t *testing.T) {
+	records, err := Parse(strings.NewReader(zone), "example.com")
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+
+	expected := []godo.DomainRecord{
+		{Type: "SOA", Name: "@", Data: "300", TTL: 3600},
+		{Type: "NS", Name: "@", Data: "ns1.example.net.", TTL: 3600},
+		{Type: "A", Name: "@", Data: "198.51.100.7", TTL: 300},
+		{Type: "CNAME", Name: "www", Data: "@", TTL: 3600},
+		{Type: "MX", Name: "mail", Data: "mail.example.com.", Priority: 10, TTL: 600},
+		{Type: "TXT", Name: "mail", Data: "v=spf1 mx -all", TTL: 3600},
+		{Type: "SRV", Name: "_sip._tcp", Data: "sip.example.net.", Priority: 10, Weight: 5, Port: 5060, TTL: 86400},
+		{Type: "CAA", Name: "@", Data: "letsencrypt.org", Flags: 0, Tag: "issue", TTL: 3600},
+		{Type: "AAAA", Name: "api.staging", Data: "2001:db8::1", TTL: 3600},
+		{Type: "TXT", Name: "key.staging", Data: `quote " and \ and ;`, TTL: 3600},
+	}
+	if len(records) != len(expected) {
+		t.Fatalf("Expected %d records, got %d: %+v", len(expected), len(records), records)
+	}
+	for i := range expected {
+		if records[i] != expected[i] {
+			t.Errorf("Record %d: expected %+v, got %+v", i, expected[i], records[i])
+		}
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	records := []godo.DomainRecord{
+		{Type: "NS", Name: "@", Data: "ns1.digitalocean.com.", TTL: 1800},
+		{Type: "SOA", Name: "@", Data: "1800", TTL: 1800},
+		{Type: "A", Name: "www", Data: "198.51.100.7", TTL: 300},
+		{Type: "MX", Name: "@", Data: "mail.example.com.", Priority: 10, TTL: 1800},
+		{Type: "TXT", Name: "dkim._domainkey", Data: "v=DKIM1; p=" + strings.Repeat("A", 400), TTL: 1800},
+		{Type: "CAA", Name: "@", Data: "mailto:security@example.com", Flags: 128, Tag: "iodef", TTL: 1800},
+		{Type: "SRV", Name: "_xmpp._tcp", Data: "xmpp.example.com.", Priority: 5, Weight: 0, Port: 5222, TTL: 1800},
+	}
+
+	var buf bytes.Buffer
+	if err := Write(&buf, "example.com", records); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	if !strings.Contains(buf.String(), `AAA" "AAA`) {
+		t.Errorf("Expected long TXT data to be split:\n%s", buf.String())
+	}
+
+	parsed, err := Parse(&buf, "example.com")
+	if err != nil {
+		t.Fatalf("Parse: %v\n%s", err, buf.String())
+	}
+	// Write puts the SOA record first.
+	expected := append([]godo.DomainRecord{records[1], records[0]}, records[2:]...)
+	if !reflect.DeepEqual(parsed, expected) {
+		t.Errorf("Round trip changed the records:\nexpected %+v\ngot      %+v", expected, parsed)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := map[string]string{
+		"www.example.org. A 198.51.100.7":  "outside of example.com",
+		"www IN MX mail":                   "needs 2 fields",
+		"www CH A 198.51.100.7":            "unsupported class",
+		"$INCLUDE other.db":                "unsupported directive",
+		"www A 198.51.100.7\n  TXT \"open": "unterminated",
+		"www A ( 198.51.100.7":             "unbalanced (",
+	}
+	for input, want := range tests {
+		_, err := Parse(strings.NewReader(input), "example.com")
+		var parseErr *ParseError
+		if !errors.As(err, &parseErr) || !strings.Contains(err.Error(), want) {
+			t.Errorf("%q: expected a parse error containing %q, got %v", input, want, err)
+		}
+	}
+}